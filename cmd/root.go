@@ -44,6 +44,8 @@ var (
 func initCmds() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(tokenCmd)
+	rootCmd.AddCommand(roleCmd)
 }
 
 func initFlags() {
@@ -64,6 +66,24 @@ func initConfig() {
 	viper.SetDefault("api.tls_addr", "")
 	viper.SetDefault("api.tls_crt", "")
 	viper.SetDefault("api.tls_key", "")
+	viper.SetDefault("api.idempotency_ttl", "24h0m0s")
+	// api: 分页令牌的签名密钥，为空时启动时随机生成，多副本部署时需要配置相同的密钥
+	viper.SetDefault("api.page_token_secret", "")
+	// api: gRPC 服务与 REST 接口共用鉴权与控制器逻辑，为空时不启动
+	viper.SetDefault("api.grpc_addr", "")
+	// auth: 支持数据库中的 API 令牌与 JWT，jwt_algorithm 为 HS256 时使用 jwt_secret 校验签名，为 RS256 时使用 jwt_public_key 指定的 PEM 公钥文件
+	viper.SetDefault("auth.enable", false)
+	viper.SetDefault("auth.jwt_algorithm", "HS256")
+	viper.SetDefault("auth.jwt_secret", "")
+	viper.SetDefault("auth.jwt_public_key", "")
+	viper.SetDefault("auth.jwt_issuer", "")
+	viper.SetDefault("auth.jwt_audience", "")
+	// audit: 记录修改类接口的调用，file 不为空时同时以 JSON lines 格式追加到文件
+	viper.SetDefault("audit.enable", true)
+	viper.SetDefault("audit.file", "")
+	// terminal: 节点终端无输入时断开的时长，录像以 asciicast v2 格式保存到 record_dir，为空时不录像
+	viper.SetDefault("terminal.idle_timeout", "10m0s")
+	viper.SetDefault("terminal.record_dir", ".gin-template/recordings")
 	// db: default to sqlite for test
 	// go-mysql-server -> :memory: -> gin-template:gin-template@tcp(127.0.0.1:6603)/db?charset=utf8mb4&parseTime=True&loc=Local
 	// mysql -> gin-template:gin-template@tcp(127.0.0.1:3306)/db?charset=utf8mb4&parseTime=True&loc=Local
@@ -73,6 +93,7 @@ func initConfig() {
 	viper.SetDefault("db.max_active_conns", 200)
 	viper.SetDefault("db.conn_lifetime", "1h0m0s")
 	viper.SetDefault("db.conn_idletime", "30m0s")
+	viper.SetDefault("db.credential_key", "")
 	// kv_db: default to redis
 	viper.SetDefault("kv_db.type", "redis")
 	viper.SetDefault("kv_db.dsn", "redis://127.0.0.1:6379")
@@ -80,6 +101,12 @@ func initConfig() {
 	viper.SetDefault("kv_db.max_active_conns", 200)
 	viper.SetDefault("kv_db.conn_lifetime", "1h0m0s")
 	viper.SetDefault("kv_db.conn_idletime", "30m0s")
+	// daemon: ssh 通过 SSH 在主机上部署集群，fake 仅在内存中模拟，用于本地调试与 e2e 测试
+	viper.SetDefault("daemon.provisioner", "ssh")
+	// daemon: 全量同步集群的间隔，新提交的任务通过通知立即处理
+	viper.SetDefault("daemon.resync_interval", "30s")
+	// daemon: 已删除集群与任务的保留时长，超过后彻底删除，0 表示永久保留
+	viper.SetDefault("daemon.retention", "720h0m0s")
 
 	// read in environment variables that match
 	viper.AutomaticEnv()
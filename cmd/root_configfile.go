@@ -26,6 +26,38 @@ tls_addr="{{ .API.TLSAddr }}"
 # tls crt and key
 tls_crt="{{ .API.TLSCrt }}"
 tls_key="{{ .API.TLSKey }}"
+# how long responses of requests with an Idempotency-Key header are kept for replay
+idempotency_ttl="{{ .API.IdempotencyTTL }}"
+# secret used to sign page tokens, generated randomly at startup when empty, must be the same across replicas
+page_token_secret="{{ .API.PageTokenSecret }}"
+# ip:port to bind the grpc server, disabled when empty
+grpc_addr="{{ .API.GRPCAddr }}"
+
+[auth]
+# authenticate api requests with api tokens or jwt bearer tokens
+# /metrics and /debug/pprof also require a token with the admin:read permission
+enable={{ .Auth.Enable }}
+# jwt signing algorithm: HS256, RS256
+jwt_algorithm="{{ .Auth.JWTAlgorithm }}"
+# secret for HS256
+jwt_secret="{{ .Auth.JWTSecret }}"
+# path of the PEM encoded public key for RS256
+jwt_public_key="{{ .Auth.JWTPublicKey }}"
+# expected iss and aud claims, skip checking when empty
+jwt_issuer="{{ .Auth.JWTIssuer }}"
+jwt_audience="{{ .Auth.JWTAudience }}"
+
+[audit]
+# record mutating api calls to the database
+enable={{ .Audit.Enable }}
+# also append audit events to this file as JSON lines, disabled when empty
+file="{{ .Audit.File }}"
+
+[terminal]
+# close node shell sessions without input for this duration
+idle_timeout="{{ .Terminal.IdleTimeout }}"
+# record node shell sessions to this directory in asciicast v2 format, disabled when empty
+record_dir="{{ .Terminal.RecordDir }}"
 
 [db]
 # db connection info
@@ -35,6 +67,9 @@ min_idle_conns={{ .DB.MinIdleConns }}
 max_active_conns={{ .DB.MaxActiveConns }}
 conn_lifetime="{{ .DB.ConnLifetime }}"
 conn_idletime="{{ .DB.ConnIdletime }}"
+# base64 encoded 32-byte key to encrypt host passwords with AES-256-GCM, generate with: openssl rand -base64 32
+# host passwords are stored in plaintext when empty, existing plaintext passwords are encrypted on startup once set
+credential_key="{{ .DB.CredentialKey }}"
 
 [kv_db]
 # kv_db connection info
@@ -44,6 +79,14 @@ min_idle_conns={{ .KVDB.MinIdleConns }}
 max_active_conns={{ .KVDB.MaxActiveConns }}
 conn_lifetime="{{ .KVDB.ConnLifetime }}"
 conn_idletime="{{ .KVDB.ConnIdletime }}"
+
+[daemon]
+# cluster provisioner: ssh, fake
+provisioner="{{ .Daemon.Provisioner }}"
+# interval to resync all clusters, new tasks are notified immediately
+resync_interval="{{ .Daemon.ResyncInterval }}"
+# retention of deleted clusters, tasks and webhook deliveries before purged, 0 to keep forever
+retention="{{ .Daemon.Retention }}"
 `
 
 var configCmd = &cobra.Command{
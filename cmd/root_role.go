@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bindSubject string
+	bindRole    string
+	bindProject string
+)
+
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "Manage the gin-template role bindings",
+}
+
+var roleBindCmd = &cobra.Command{
+	Use:   "bind",
+	Short: "Grant a role to a caller, used to bootstrap the first admin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupCmdStorage(); err != nil {
+			return err
+		}
+		item, err := storage.CreateRoleBinding(bindSubject, bindRole, bindProject)
+		if err != nil {
+			return errors.Wrap(err, "create role binding")
+		}
+		fmt.Printf("id: %d\nsubject: %s\nrole: %s\nproject: %s\n", item.ID, item.Subject, item.RoleName, item.ProjectID)
+		return nil
+	},
+}
+
+func init() {
+	roleBindCmd.Flags().StringVar(&bindSubject, "subject", "", "caller identity")
+	roleBindCmd.Flags().StringVar(&bindRole, "role", storage.RoleAdmin, "role name")
+	roleBindCmd.Flags().StringVar(&bindProject, "project", "", "project resource id, empty for all projects")
+	roleBindCmd.MarkFlagRequired("subject")
+	roleCmd.AddCommand(roleBindCmd)
+}
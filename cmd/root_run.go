@@ -14,6 +14,7 @@ import (
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/rpc"
 	"gitbub.com/wbuntu/gin-template/internal/storage"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -66,6 +67,7 @@ func run(cmd *cobra.Command, args []string) error {
 type task struct {
 	ctx           context.Context
 	cfg           *config.Config
+	api           *api.Server
 	serveFuncs    []func()
 	shutdownFuncs []func()
 	logger        log.Logger
@@ -80,6 +82,7 @@ func (t *task) setup() error {
 		setupDependency,
 		setupDaemon,
 		setupAPI,
+		setupGRPC,
 	}
 	for _, fn := range taskFuncs {
 		if err := fn(t); err != nil {
@@ -173,6 +176,7 @@ func setupAPI(t *task) error {
 	); err != nil {
 		return errors.Wrap(err, "setup api")
 	}
+	t.api = srv
 	t.serveFuncs = append(t.serveFuncs, func() {
 		srv.Serve()
 	})
@@ -182,3 +186,26 @@ func setupAPI(t *task) error {
 	t.logger.Info("setup api server success")
 	return nil
 }
+
+// setupGRPC 初始化 gRPC 模块，未配置监听地址时不启动
+func setupGRPC(t *task) error {
+	if len(t.cfg.API.GRPCAddr) == 0 {
+		return nil
+	}
+	srv := &rpc.Server{}
+	if err := srv.Setup(
+		t.ctx,
+		t.cfg,
+		t.api.Handler(),
+	); err != nil {
+		return errors.Wrap(err, "setup grpc")
+	}
+	t.serveFuncs = append(t.serveFuncs, func() {
+		srv.Serve()
+	})
+	t.shutdownFuncs = append(t.shutdownFuncs, func() {
+		srv.Shutdown()
+	})
+	t.logger.Info("setup grpc server success")
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenName    string
+	tokenSubject string
+	tokenTTL     time.Duration
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the gin-template api tokens",
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an api token, the token is printed only once",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupCmdStorage(); err != nil {
+			return err
+		}
+		item, token, err := storage.CreateAPIToken(tokenName, tokenSubject, tokenTTL)
+		if err != nil {
+			return errors.Wrap(err, "create api token")
+		}
+		fmt.Printf("id: %s\nsubject: %s\ntoken: %s\n", item.ResourceID, item.Subject, token)
+		return nil
+	},
+}
+
+func init() {
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "token name")
+	tokenCreateCmd.Flags().StringVar(&tokenSubject, "subject", "", "caller identity of the token")
+	tokenCreateCmd.Flags().DurationVar(&tokenTTL, "ttl", 0, "token lifetime, never expires when 0")
+	tokenCreateCmd.MarkFlagRequired("subject")
+	tokenCmd.AddCommand(tokenCreateCmd)
+}
+
+// setupCmdStorage 初始化命令行工具使用的日志与数据存储
+func setupCmdStorage() error {
+	if err := log.Setup(&config.C); err != nil {
+		return errors.Wrap(err, "setup log")
+	}
+	ctx := context.Background()
+	if err := storage.Setup(ctx, &config.C); err != nil {
+		return errors.Wrap(err, "setup storage")
+	}
+	if err := storage.Migrate(ctx, &config.C); err != nil {
+		return errors.Wrap(err, "migrate storage")
+	}
+	return nil
+}
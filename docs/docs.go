@@ -22,14 +22,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/clusters": {
+        "/admin/deleted-clusters": {
             "get": {
-                "description": "分页获取集群列表",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取已删除的集群，最近删除的集群在前，超过保留时长的集群会被彻底删除",
                 "tags": [
-                    "Cluster"
+                    "Admin"
                 ],
-                "summary": "集群列表",
+                "summary": "已删除的集群列表",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID，为空时列出全部项目",
+                        "name": "projectId",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "x-example": "1",
@@ -45,17 +57,156 @@ const docTemplate = `{
                         "name": "pageSize",
                         "in": "query",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListDeletedClusterResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/deleted-clusters/{clusterId}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "恢复已删除的集群与集群的任务，集群的主机在删除时已重置，恢复后集群进入异常状态，可以再次删除，恢复的集群计入项目配额",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "恢复集群",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.RestoreClusterResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/deleted-tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取已删除集群的任务，最新提交的任务在前，任务随集群一起删除与恢复",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "已删除的任务列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID，为空时列出全部已删除集群的任务",
+                        "name": "clusterId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，默认为1",
+                        "name": "pageNo",
+                        "in": "query",
+                        "required": true
                     },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListDeletedTaskResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取修改类接口的审计事件，最新的事件在前，请求体中的密码、令牌等敏感字段已脱敏",
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "审计事件列表",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "查询条件，默认为空",
-                        "name": "filterKey",
+                        "x-example": "anonymous",
+                        "description": "调用者身份",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1700000000",
+                        "description": "起始时间，unix 时间戳（秒）",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1900000000",
+                        "description": "结束时间，unix 时间戳（秒）",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数",
+                        "name": "pageNo",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
-                        "description": "查询值，默认为空",
-                        "name": "filterValue",
+                        "description": "分页令牌，使用上一页返回的 nextPageToken 获取下一页",
+                        "name": "pageToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回总数，使用页码分页时总是返回",
+                        "name": "withTotal",
                         "in": "query"
                     }
                 ],
@@ -63,25 +214,70 @@ const docTemplate = `{
                     "200": {
                         "description": "响应",
                         "schema": {
-                            "$ref": "#/definitions/model.ListClusterResp"
+                            "$ref": "#/definitions/model.ListAuditEventResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取项目列表",
+                "tags": [
+                    "Project"
+                ],
+                "summary": "项目列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，默认为1",
+                        "name": "pageNo",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListProjectResp"
                         }
                     }
                 }
             },
             "post": {
-                "description": "根据配置参数自动创建集群",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建项目，集群归属于项目，调用者通过限定项目的角色绑定访问项目中的集群",
                 "tags": [
-                    "Cluster"
+                    "Project"
                 ],
-                "summary": "创建集群",
+                "summary": "创建项目",
                 "parameters": [
                     {
                         "description": "请求",
-                        "name": "CreateClusterReq",
+                        "name": "CreateProjectReq",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateClusterReq"
+                            "$ref": "#/definitions/model.CreateProjectReq"
                         }
                     }
                 ],
@@ -89,25 +285,30 @@ const docTemplate = `{
                     "200": {
                         "description": "响应",
                         "schema": {
-                            "$ref": "#/definitions/model.CreateClusterResp"
+                            "$ref": "#/definitions/model.CreateProjectResp"
                         }
                     }
                 }
             }
         },
-        "/clusters/{clusterId}": {
+        "/projects/{projectId}": {
             "get": {
-                "description": "根据集群ID获取集群详细信息",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据项目ID获取项目详细信息",
                 "tags": [
-                    "Cluster"
+                    "Project"
                 ],
-                "summary": "集群详情",
+                "summary": "项目详情",
                 "parameters": [
                     {
                         "type": "string",
-                        "x-example": "cluster-sedqqz7ka",
-                        "description": "集群资源ID",
-                        "name": "clusterId",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
                         "in": "path",
                         "required": true
                     }
@@ -116,25 +317,39 @@ const docTemplate = `{
                     "200": {
                         "description": "响应",
                         "schema": {
-                            "$ref": "#/definitions/model.GetClusterResp"
+                            "$ref": "#/definitions/model.GetProjectResp"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "根据集群ID自动删除集群",
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "修改项目描述与集群数量配额",
                 "tags": [
-                    "Cluster"
+                    "Project"
                 ],
-                "summary": "删除集群",
+                "summary": "修改项目",
                 "parameters": [
                     {
                         "type": "string",
-                        "x-example": "cluster-sedqqz7ka",
-                        "description": "集群资源ID",
-                        "name": "clusterId",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "UpdateProjectReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateProjectReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -147,37 +362,2746 @@ const docTemplate = `{
                 }
             }
         },
-        "/tools/check-cidr": {
-            "post": {
-                "description": "检查集群CIDR是否存在网段冲突",
+        "/projects/{projectId}/clusters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取集群列表，支持多个过滤条件与排序\n过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔\n过滤字段：name、resourceID、type、version、runtime、status、createTime、updateTime，时间使用 unix 时间戳（秒），状态可以使用名称\n排序字段：name、version、status、createTime、updateTime",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
                 "tags": [
-                    "Tools"
+                    "Cluster"
                 ],
-                "summary": "CIDR网段检查",
+                "summary": "集群列表",
                 "parameters": [
                     {
-                        "description": "请求",
-                        "name": "CheckCIDRReq",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CheckCIDRReq"
-                        }
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数",
+                        "name": "pageNo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页令牌，使用上一页返回的 nextPageToken 获取下一页",
+                        "name": "pageToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回总数，使用页码分页时总是返回",
+                        "name": "withTotal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "x-example": "status:in:Running",
+                        "description": "过滤条件，格式为 field:op:value",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "createTime",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向，默认为 desc",
+                        "name": "sortOrder",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询条件，已废弃，使用 filter 代替",
+                        "name": "filterKey",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询值，已废弃，使用 filter 代替",
+                        "name": "filterValue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "env=prod",
+                        "description": "标签选择器，语法与 k8s 相同，默认为空",
+                        "name": "labelSelector",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "响应",
                         "schema": {
-                            "$ref": "#/definitions/model.CheckCIDRResp"
+                            "$ref": "#/definitions/model.ListClusterResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据配置参数自动创建集群",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "创建集群",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "CreateClusterReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateClusterReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateClusterResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据集群ID获取集群详细信息",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "集群详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetClusterResp"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "集群的资源版本"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据集群ID自动删除集群",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "删除集群",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "集群详情返回的 ETag，不匹配时返回 Conflict",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "使用 JSON Merge Patch 修改集群的名称、描述、标签与注解，其他字段不允许修改",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "修改集群",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "PatchClusterReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ClusterPatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "集群详情返回的 ETag，不匹配时返回 Conflict",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetClusterResp"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "集群的资源版本"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "以 Server-Sent Events 推送集群状态、最新任务状态与新的任务日志，连接后先推送集群与最新任务的当前状态。事件ID为已推送的最后一条任务日志ID，重连时通过 Last-Event-ID 头部携带，只推送之后的任务日志。集群删除后推送 Deleted 状态并结束",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "集群事件流",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "10",
+                        "description": "上次收到的最后一个事件ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "连接失败时的响应，成功时为事件流",
+                        "schema": {
+                            "$ref": "#/definitions/model.ClusterEventsResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/nodepools": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取集群的节点池列表",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "节点池列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListNodePoolResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建节点池，添加节点时可以指定节点池继承角色、标签与污点",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "创建节点池",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "CreateNodePoolReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateNodePoolReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateNodePoolResp"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.BaseResponse": {
+        },
+        "/projects/{projectId}/clusters/{clusterId}/nodes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取集群节点列表，支持多个过滤条件与排序\n过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔\n过滤字段：resourceID、nodePoolID、role、ip、status、createTime，时间使用 unix 时间戳（秒），状态可以使用名称\n排序字段：role、ip、status、createTime",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "节点列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，默认为1",
+                        "name": "pageNo",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "x-example": "role:eq:worker",
+                        "description": "过滤条件，格式为 field:op:value",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "ip",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向，默认为 desc",
+                        "name": "sortOrder",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListNodeResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "通过 SSH 将主机加入集群，可以指定节点池继承角色、标签与污点",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "添加节点",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "AddNodeReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AddNodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.AddNodeResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/nodes/{nodeId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "驱逐节点后将节点从集群中移除并清理主机，不允许移除第一个控制面节点",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "移除节点",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "node-k2jd8sm1qa",
+                        "description": "节点资源ID",
+                        "name": "nodeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/nodes/{nodeId}/drain": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "驱逐节点上的容器组并停止调度",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "驱逐节点",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "node-k2jd8sm1qa",
+                        "description": "节点资源ID",
+                        "name": "nodeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/nodes/{nodeId}/shell": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "通过 WebSocket 以 root 登录节点的交互式终端，浏览器无法设置头部时使用 access_token 参数携带令牌。客户端以 JSON 文本帧发送 model.ShellMessage，type 为 input 时 data 为终端输入，type 为 resize 时调整终端大小；终端输出以二进制帧返回；会话结束时服务端发送 type 为 exit 的消息并关闭连接。超过空闲时长没有输入时断开，配置录像目录时以 asciicast v2 格式录像",
+                "tags": [
+                    "Node"
+                ],
+                "summary": "节点终端",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "node-k2jd8sm1qa",
+                        "description": "节点资源ID",
+                        "name": "nodeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "80",
+                        "description": "终端列数，默认为 80",
+                        "name": "cols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "24",
+                        "description": "终端行数，默认为 24",
+                        "name": "rows",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "终端类型，默认为 xterm-256color",
+                        "name": "term",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "令牌，只用于 WebSocket 升级请求",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "连接失败时的响应，成功时升级为 WebSocket",
+                        "schema": {
+                            "$ref": "#/definitions/model.NodeShellResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取集群的任务列表，默认最新提交的任务在前，已删除集群的任务通过管理接口查询\n过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔\n过滤字段：action、status、retryCount、requestID、createTime、updateTime，时间使用 unix 时间戳（秒），状态可以使用名称\n排序字段：status、retryCount、createTime、updateTime",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数",
+                        "name": "pageNo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页令牌，使用上一页返回的 nextPageToken 获取下一页",
+                        "name": "pageToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回总数，使用页码分页时总是返回",
+                        "name": "withTotal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "x-example": "action:eq:CreateCluster",
+                        "description": "过滤条件，格式为 field:op:value",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "createTime",
+                        "description": "排序字段",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "排序方向，默认为 desc",
+                        "name": "sortOrder",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListTaskResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/clusters/{clusterId}/upgrade": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据目标版本滚动升级集群，先升级控制面节点，再升级工作节点",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Cluster"
+                ],
+                "summary": "升级集群",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "cluster-sedqqz7ka",
+                        "description": "集群资源ID",
+                        "name": "clusterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "UpgradeClusterReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpgradeClusterReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "集群详情返回的 ETag，不匹配时返回 Conflict",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/tasks/{taskId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据任务ID获取任务详细信息",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "任务ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetTaskResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/tasks/{taskId}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "取消等待执行或正在执行的任务，集群或节点进入异常状态，集群健康时由同步任务恢复为运行中",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "取消任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "任务ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/tasks/{taskId}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取任务每次执行的日志，最新的日志在前，支持页码与分页令牌两种分页方式",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "任务ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数",
+                        "name": "pageNo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页令牌，使用上一页返回的 nextPageToken 获取下一页",
+                        "name": "pageToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回总数，使用页码分页时总是返回",
+                        "name": "withTotal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListTaskLogResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/tasks/{taskId}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "重新执行集群最近一个已失败或已取消的任务，重置重试次数",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "重试任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "任务ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取项目的 webhook 列表，不返回签名密钥",
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "webhook 列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListWebhookResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "订阅项目中集群与任务的状态变化事件，事件以 POST 请求投递到指定地址，请求头 X-Webhook-Signature 为 sha256=hex(HMAC-SHA256(secret, X-Webhook-Timestamp + \".\" + body))，投递失败时按指数退避重试",
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "创建 webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求",
+                        "name": "CreateWebhookReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateWebhookResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/webhooks/{webhookId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除 webhook 与 webhook 的投递记录，等待投递的事件不再投递",
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "删除 webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "webhook-k2jd8sm1qa",
+                        "description": "webhook 资源ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.DeleteWebhookResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{projectId}/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取 webhook 的投递记录，最新的记录在前",
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "webhook 投递记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "project-default",
+                        "description": "项目资源ID",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "x-example": "webhook-k2jd8sm1qa",
+                        "description": "webhook 资源ID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数",
+                        "name": "pageNo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "x-example": "10",
+                        "description": "分页大小，默认为10",
+                        "name": "pageSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "分页令牌，使用上一页返回的 nextPageToken 获取下一页",
+                        "name": "pageToken",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回总数，使用页码分页时总是返回",
+                        "name": "withTotal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListWebhookDeliveryResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/rolebindings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取角色绑定列表，可以按调用者身份过滤",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "角色绑定列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "alice",
+                        "description": "调用者身份",
+                        "name": "subject",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListRoleBindingResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将角色授予调用者，可以限定角色只在指定项目中生效",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "创建角色绑定",
+                "parameters": [
+                    {
+                        "description": "请求",
+                        "name": "CreateRoleBindingReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoleBindingReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoleBindingResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/rolebindings/{bindingId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "收回调用者的角色",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "删除角色绑定",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "x-example": "1",
+                        "description": "角色绑定ID",
+                        "name": "bindingId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取全部内置角色与自定义角色",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "角色列表",
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListRoleResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建自定义角色，角色名称不能与已有角色重复",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "创建角色",
+                "parameters": [
+                    {
+                        "description": "请求",
+                        "name": "CreateRoleReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoleReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoleResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{roleName}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除自定义角色与该角色的全部绑定，内置角色不允许删除",
+                "tags": [
+                    "Role"
+                ],
+                "summary": "删除角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "x-example": "auditor",
+                        "description": "角色名称",
+                        "name": "roleName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tools/check-cidr": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "检查集群CIDR是否存在网段冲突",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-yaml",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "Tools"
+                ],
+                "summary": "CIDR网段检查",
+                "parameters": [
+                    {
+                        "description": "请求",
+                        "name": "CheckCIDRReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckCIDRReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "响应",
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckCIDRResp"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.AddNodeReq": {
+            "type": "object",
+            "required": [
+                "ip",
+                "password",
+                "port"
+            ],
+            "properties": {
+                "ip": {
+                    "description": "IP地址",
+                    "type": "string",
+                    "example": "192.168.1.11"
+                },
+                "labels": {
+                    "description": "节点标签，与节点池标签合并，同名时以节点为准",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "nodePoolId": {
+                    "description": "节点池资源ID，节点继承节点池的角色、标签与污点",
+                    "type": "string",
+                    "example": "nodepool-x2kq8sj3ma"
+                },
+                "password": {
+                    "description": "root密码",
+                    "type": "string",
+                    "example": "password"
+                },
+                "port": {
+                    "description": "SSH端口",
+                    "type": "integer",
+                    "maximum": 65535,
+                    "minimum": 1,
+                    "example": 22
+                },
+                "role": {
+                    "description": "角色：master、worker",
+                    "type": "string",
+                    "enum": [
+                        "master",
+                        "worker"
+                    ],
+                    "example": "worker"
+                },
+                "taints": {
+                    "description": "节点污点，追加到节点池污点之后",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Taint"
+                    }
+                }
+            }
+        },
+        "model.AddNodeResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "节点资源ID",
+                    "type": "string",
+                    "example": "node-k2jd8sm1qa"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.AuditEventItem": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "调用者身份",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "clientIp": {
+                    "description": "客户端IP",
+                    "type": "string",
+                    "example": "192.168.1.10"
+                },
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "id": {
+                    "description": "事件ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "latency": {
+                    "description": "耗时（毫秒）",
+                    "type": "integer",
+                    "example": 12
+                },
+                "method": {
+                    "description": "请求方法",
+                    "type": "string",
+                    "example": "DELETE"
+                },
+                "path": {
+                    "description": "请求路径",
+                    "type": "string",
+                    "example": "/api/v1.0/projects/project-default/clusters/cluster-sedqqz7ka"
+                },
+                "projectId": {
+                    "description": "项目资源ID",
+                    "type": "string",
+                    "example": "project-default"
+                },
+                "requestBody": {
+                    "description": "脱敏后的请求体，请求体不是 JSON 或超过长度限制时为空",
+                    "type": "object"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "resourceId": {
+                    "description": "操作的资源ID，优先使用路径中的资源ID，创建类请求使用响应中的资源ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
+                "responseCode": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "route": {
+                    "description": "路由",
+                    "type": "string",
+                    "example": "/api/v1.0/projects/:projectId/clusters/:clusterId"
+                },
+                "statusCode": {
+                    "description": "HTTP状态码",
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "model.BaseResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CheckCIDRReq": {
+            "type": "object",
+            "required": [
+                "podCidr",
+                "serviceCidr"
+            ],
+            "properties": {
+                "ipv6": {
+                    "description": "ipv6开关",
+                    "type": "boolean",
+                    "example": false
+                },
+                "podCidr": {
+                    "description": "容器组网段",
+                    "type": "string",
+                    "example": "10.244.0.0/16"
+                },
+                "podCidrIpv6": {
+                    "description": "ipv6容器组网段, ipv6为true时有效",
+                    "type": "string",
+                    "example": "fc00::/48"
+                },
+                "serviceCidr": {
+                    "description": "服务网段",
+                    "type": "string",
+                    "example": "10.96.0.0/16"
+                },
+                "serviceCidrIpv6": {
+                    "description": "ipv6服务网",
+                    "type": "string",
+                    "example": "fd00::/108"
+                }
+            }
+        },
+        "model.CheckCIDRResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.ClusterDetail": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "description": "注解",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "description": {
+                    "description": "描述",
+                    "type": "string",
+                    "example": "amazing-cluster-description"
+                },
+                "k8sType": {
+                    "description": "类型：k8s、k3s",
+                    "type": "string",
+                    "example": "k8s"
+                },
+                "labels": {
+                    "description": "标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "imortal-cluster-name"
+                },
+                "projectId": {
+                    "description": "项目ID",
+                    "type": "string",
+                    "example": "project-default"
+                },
+                "resourceID": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
+                "resourceVersion": {
+                    "description": "资源版本，每次更新加一，修改集群时可以通过 If-Match 头部携带",
+                    "type": "integer",
+                    "example": 3
+                },
+                "runtime": {
+                    "description": "容器运行时",
+                    "type": "string",
+                    "example": "cri-o"
+                },
+                "status": {
+                    "description": "集群状态",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string",
+                    "example": "1.22.5"
+                }
+            }
+        },
+        "model.ClusterEventsResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.ClusterHost": {
+            "type": "object",
+            "required": [
+                "ip",
+                "password",
+                "port",
+                "role"
+            ],
+            "properties": {
+                "ip": {
+                    "description": "IP地址",
+                    "type": "string",
+                    "example": "192.168.1.10"
+                },
+                "password": {
+                    "description": "root密码",
+                    "type": "string",
+                    "example": "password"
+                },
+                "port": {
+                    "description": "SSH端口",
+                    "type": "integer",
+                    "maximum": 65535,
+                    "minimum": 1,
+                    "example": 22
+                },
+                "role": {
+                    "description": "角色：master、worker",
+                    "type": "string",
+                    "enum": [
+                        "master",
+                        "worker"
+                    ],
+                    "example": "master"
+                }
+            }
+        },
+        "model.ClusterPatch": {
+            "type": "object",
+            "properties": {
+                "annotations": {
+                    "description": "注解，null 表示删除全部注解，注解值为 null 表示删除该注解",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "description": "描述，支持 0~255 位字符，null 表示清空",
+                    "type": "string",
+                    "example": "amazing-cluster-description"
+                },
+                "labels": {
+                    "description": "标签，null 表示删除全部标签，标签值为 null 表示删除该标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称，不允许为 null，规则与创建集群相同",
+                    "type": "string",
+                    "example": "imortal-cluster-name"
+                }
+            }
+        },
+        "model.ClusterSummary": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "description": {
+                    "description": "描述",
+                    "type": "string",
+                    "example": "amazing-cluster-description"
+                },
+                "k8sType": {
+                    "description": "类型：k8s、k3s",
+                    "type": "string",
+                    "example": "k8s"
+                },
+                "labels": {
+                    "description": "标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "imortal-cluster-name"
+                },
+                "resourceID": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
+                "resourceVersion": {
+                    "description": "资源版本，每次更新加一，修改集群时可以通过 If-Match 头部携带",
+                    "type": "integer",
+                    "example": 3
+                },
+                "runtime": {
+                    "description": "容器运行时",
+                    "type": "string",
+                    "example": "cri-o"
+                },
+                "status": {
+                    "description": "集群状态",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string",
+                    "example": "1.22.5"
+                }
+            }
+        },
+        "model.CreateClusterReq": {
+            "type": "object",
+            "required": [
+                "hosts",
+                "k8sType",
+                "runtime",
+                "version"
+            ],
+            "properties": {
+                "annotations": {
+                    "description": "注解，保存不用于选择集群的附加信息",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "description": "描述，支持 0~255 位字符",
+                    "type": "string",
+                    "example": "amazing-cluster-description"
+                },
+                "hosts": {
+                    "description": "集群主机，至少包含一个控制面节点",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.ClusterHost"
+                    }
+                },
+                "k8sType": {
+                    "description": "类型：k8s、k3s",
+                    "type": "string",
+                    "enum": [
+                        "k8s",
+                        "k3s"
+                    ],
+                    "example": "k8s"
+                },
+                "labels": {
+                    "description": "标签，格式与 k8s 标签相同，可以在列表中通过 labelSelector 选择集群",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)",
+                    "type": "string",
+                    "example": "imortal-cluster-name"
+                },
+                "runtime": {
+                    "description": "容器运行时",
+                    "type": "string",
+                    "enum": [
+                        "cri-o",
+                        "containerd"
+                    ],
+                    "example": "cri-o"
+                },
+                "version": {
+                    "description": "版本，格式为 Major.Minor.Patch，允许以 v 开头",
+                    "type": "string",
+                    "example": "1.22.5"
+                }
+            }
+        },
+        "model.CreateClusterResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "集群资源ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7kavbh"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CreateNodePoolReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "labels": {
+                    "description": "节点标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)",
+                    "type": "string",
+                    "example": "gpu-pool"
+                },
+                "role": {
+                    "description": "角色：master、worker",
+                    "type": "string",
+                    "enum": [
+                        "master",
+                        "worker"
+                    ],
+                    "example": "worker"
+                },
+                "taints": {
+                    "description": "节点污点",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Taint"
+                    }
+                }
+            }
+        },
+        "model.CreateNodePoolResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "节点池资源ID",
+                    "type": "string",
+                    "example": "nodepool-x2kq8sj3ma"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CreateProjectReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "clusterQuota": {
+                    "description": "集群数量配额，0 表示不限制",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "description": {
+                    "description": "描述",
+                    "type": "string",
+                    "example": "clusters of team a"
+                },
+                "name": {
+                    "description": "名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)",
+                    "type": "string",
+                    "example": "team-a"
+                }
+            }
+        },
+        "model.CreateProjectResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "项目资源ID",
+                    "type": "string",
+                    "example": "project-k2jd8sm1qa"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CreateRoleBindingReq": {
+            "type": "object",
+            "required": [
+                "role",
+                "subject"
+            ],
+            "properties": {
+                "projectId": {
+                    "description": "项目资源ID，为空时角色在全部项目中生效，限定项目的角色绑定不授予 role:read、role:update、project:update 权限",
+                    "type": "string",
+                    "example": "project-default"
+                },
+                "role": {
+                    "description": "角色名称",
+                    "type": "string",
+                    "example": "operator"
+                },
+                "subject": {
+                    "description": "调用者身份，API 令牌的 subject 或 JWT 的 sub 声明",
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "model.CreateRoleBindingResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "角色绑定ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CreateRoleReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "permissions"
+            ],
+            "properties": {
+                "description": {
+                    "description": "描述",
+                    "type": "string",
+                    "example": "read only access to tasks"
+                },
+                "name": {
+                    "description": "名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)",
+                    "type": "string",
+                    "example": "auditor"
+                },
+                "permissions": {
+                    "description": "权限列表：*、cluster:read、cluster:create、cluster:update、cluster:delete、task:read、task:update、role:read、role:update、project:read、project:update",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task:read"
+                    ]
+                }
+            }
+        },
+        "model.CreateRoleResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "角色名称",
+                    "type": "string",
+                    "example": "auditor"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.CreateWebhookReq": {
+            "type": "object",
+            "required": [
+                "events",
+                "secret",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "description": "订阅的事件类型：cluster.running、cluster.error、cluster.deleted、task.succeeded、task.failed、task.canceled",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "description": "事件类型",
+                        "type": "string",
+                        "example": "cluster.running"
+                    },
+                    "example": [
+                        "cluster.running",
+                        "task.failed"
+                    ]
+                },
+                "secret": {
+                    "description": "签名密钥，至少 16 位，创建后不再返回",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16,
+                    "example": "0123456789abcdef"
+                },
+                "url": {
+                    "description": "投递地址，只支持 http 与 https，不允许解析到回环、内网、链路本地等非公网地址，不跟随重定向",
+                    "type": "string",
+                    "example": "https://example.com/hooks/cluster"
+                }
+            }
+        },
+        "model.CreateWebhookResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "webhook 资源ID",
+                    "type": "string",
+                    "example": "webhook-k2jd8sm1qa"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.DeleteWebhookResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.DeletedCluster": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "deleteTime": {
+                    "description": "删除时间，超过保留时长后彻底删除",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "k8sType": {
+                    "description": "类型：k8s、k3s",
+                    "type": "string",
+                    "example": "k8s"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "imortal-cluster-name"
+                },
+                "projectId": {
+                    "description": "项目资源ID",
+                    "type": "string",
+                    "example": "project-default"
+                },
+                "resourceID": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string",
+                    "example": "1.22.5"
+                }
+            }
+        },
+        "model.DeletedTask": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "任务操作",
+                    "type": "string",
+                    "example": "DeleteCluster"
+                },
+                "clusterId": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "deleteTime": {
+                    "description": "删除时间，超过保留时长后彻底删除",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "id": {
+                    "description": "任务ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "projectId": {
+                    "description": "项目资源ID",
+                    "type": "string",
+                    "example": "project-default"
+                },
+                "status": {
+                    "description": "任务状态",
+                    "type": "string",
+                    "example": "Success"
+                }
+            }
+        },
+        "model.GetClusterResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "集群详情",
+                    "$ref": "#/definitions/model.ClusterDetail"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.GetProjectResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "项目详情",
+                    "$ref": "#/definitions/model.ProjectItem"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.GetTaskResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "任务详情",
+                    "$ref": "#/definitions/model.TaskDetail"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.ListAuditEventResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "审计事件列表，最新的事件在前",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditEventItem"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "nextPageToken": {
+                    "description": "下一页的分页令牌，没有下一页时为空",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "事件总数，不计算总数时为空",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListClusterResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "集群概要列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClusterSummary"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "nextPageToken": {
+                    "description": "下一页的分页令牌，没有下一页时为空",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "集群总数，不计算总数时为空",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "model.ListDeletedClusterResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "已删除的集群列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeletedCluster"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "已删除的集群总数",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListDeletedTaskResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "已删除的任务列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DeletedTask"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "已删除的任务总数",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListNodePoolResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "节点池列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NodePoolSummary"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.ListNodeResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "节点概要列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NodeSummary"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "节点总数",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListProjectResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "项目列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProjectItem"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "项目总数",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListRoleBindingResp": {
             "type": "object",
             "properties": {
                 "code": {
@@ -185,6 +3109,13 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Success"
                 },
+                "data": {
+                    "description": "角色绑定列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RoleBindingItem"
+                    }
+                },
                 "message": {
                     "description": "响应消息",
                     "type": "string",
@@ -197,41 +3128,312 @@ const docTemplate = `{
                 }
             }
         },
-        "model.CheckCIDRReq": {
+        "model.ListRoleResp": {
             "type": "object",
-            "required": [
-                "podCidr",
-                "serviceCidr"
-            ],
             "properties": {
-                "ipv6": {
-                    "description": "ipv6开关",
-                    "type": "boolean",
-                    "example": false
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
                 },
-                "podCidr": {
-                    "description": "容器组网段",
+                "data": {
+                    "description": "角色列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RoleItem"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.ListTaskLogResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "任务日志列表，最新的日志在前",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TaskLogItem"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "nextPageToken": {
+                    "description": "下一页的分页令牌，没有下一页时为空",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "日志总数，不计算总数时为空",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListTaskResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "任务概要列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TaskSummary"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "nextPageToken": {
+                    "description": "下一页的分页令牌，没有下一页时为空",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "任务总数，不计算总数时为空",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListWebhookDeliveryResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "投递记录列表，最新的记录在前",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookDelivery"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "nextPageToken": {
+                    "description": "下一页的分页令牌，没有下一页时为空",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "totalCount": {
+                    "description": "投递记录总数，不计算总数时为空",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "model.ListWebhookResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "data": {
+                    "description": "webhook 列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookSummary"
+                    }
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.NodePoolSummary": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "labels": {
+                    "description": "节点标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "gpu-pool"
+                },
+                "resourceID": {
+                    "description": "节点池ID",
+                    "type": "string",
+                    "example": "nodepool-x2kq8sj3ma"
+                },
+                "role": {
+                    "description": "角色：master、worker",
+                    "type": "string",
+                    "example": "worker"
+                },
+                "taints": {
+                    "description": "节点污点",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Taint"
+                    }
+                }
+            }
+        },
+        "model.NodeShellResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "响应码",
+                    "type": "string",
+                    "example": "Success"
+                },
+                "message": {
+                    "description": "响应消息",
+                    "type": "string",
+                    "example": "调用成功"
+                },
+                "requestId": {
+                    "description": "请求ID",
+                    "type": "string",
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                }
+            }
+        },
+        "model.NodeSummary": {
+            "type": "object",
+            "properties": {
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "ip": {
+                    "description": "IP地址",
+                    "type": "string",
+                    "example": "192.168.1.11"
+                },
+                "labels": {
+                    "description": "节点标签",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "nodePoolId": {
+                    "description": "节点池ID",
+                    "type": "string",
+                    "example": "nodepool-x2kq8sj3ma"
+                },
+                "port": {
+                    "description": "SSH端口",
+                    "type": "integer",
+                    "example": 22
+                },
+                "resourceID": {
+                    "description": "节点ID",
+                    "type": "string",
+                    "example": "node-k2jd8sm1qa"
+                },
+                "role": {
+                    "description": "角色：master、worker",
+                    "type": "string",
+                    "example": "worker"
+                },
+                "status": {
+                    "description": "节点状态",
+                    "type": "string",
+                    "example": "Running"
+                },
+                "taints": {
+                    "description": "节点污点",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Taint"
+                    }
+                }
+            }
+        },
+        "model.ProjectItem": {
+            "type": "object",
+            "properties": {
+                "clusterQuota": {
+                    "description": "集群数量配额，0 表示不限制",
+                    "type": "integer",
+                    "example": 10
+                },
+                "createTime": {
+                    "description": "创建时间",
                     "type": "string",
-                    "example": "10.244.0.0/16"
+                    "example": "2006-01-02 15:04:05"
                 },
-                "podCidrIpv6": {
-                    "description": "ipv6容器组网段, ipv6为true时有效",
+                "description": {
+                    "description": "描述",
                     "type": "string",
-                    "example": "fc00::/48"
+                    "example": "clusters of team a"
                 },
-                "serviceCidr": {
-                    "description": "服务网段",
+                "name": {
+                    "description": "名称",
                     "type": "string",
-                    "example": "10.96.0.0/16"
+                    "example": "team-a"
                 },
-                "serviceCidrIpv6": {
-                    "description": "ipv6服务网",
+                "resourceID": {
+                    "description": "项目ID",
                     "type": "string",
-                    "example": "fd00::/108"
+                    "example": "project-k2jd8sm1qa"
                 }
             }
         },
-        "model.CheckCIDRResp": {
+        "model.RestoreClusterResp": {
             "type": "object",
             "properties": {
                 "code": {
@@ -239,6 +3441,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Success"
                 },
+                "data": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
                 "message": {
                     "description": "响应消息",
                     "type": "string",
@@ -251,7 +3458,7 @@ const docTemplate = `{
                 }
             }
         },
-        "model.ClusterDetail": {
+        "model.RoleBindingItem": {
             "type": "object",
             "properties": {
                 "createTime": {
@@ -259,210 +3466,371 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2006-01-02 15:04:05"
                 },
-                "description": {
-                    "description": "描述",
+                "id": {
+                    "description": "角色绑定ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "projectId": {
+                    "description": "项目资源ID，为空时角色在全部项目中生效",
                     "type": "string",
-                    "example": "amazing-cluster-description"
+                    "example": "project-default"
                 },
-                "k8sType": {
-                    "description": "类型：k8s、k3s",
+                "role": {
+                    "description": "角色名称",
                     "type": "string",
-                    "example": "k8s"
+                    "example": "operator"
+                },
+                "subject": {
+                    "description": "调用者身份",
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "model.RoleItem": {
+            "type": "object",
+            "properties": {
+                "builtin": {
+                    "description": "是否内置，内置角色不允许删除",
+                    "type": "boolean",
+                    "example": true
+                },
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "description": {
+                    "description": "描述",
+                    "type": "string",
+                    "example": "manage clusters, nodes and tasks except deleting clusters"
                 },
                 "name": {
                     "description": "名称",
                     "type": "string",
-                    "example": "imortal-cluster-name"
+                    "example": "operator"
                 },
-                "resourceID": {
-                    "description": "集群ID",
+                "permissions": {
+                    "description": "权限列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "cluster:read"
+                    ]
+                }
+            }
+        },
+        "model.Taint": {
+            "type": "object",
+            "required": [
+                "effect",
+                "key"
+            ],
+            "properties": {
+                "effect": {
+                    "description": "效果：NoSchedule、PreferNoSchedule、NoExecute",
                     "type": "string",
-                    "example": "cluster-sedqqz7ka"
+                    "enum": [
+                        "NoSchedule",
+                        "PreferNoSchedule",
+                        "NoExecute"
+                    ],
+                    "example": "NoSchedule"
                 },
-                "runtime": {
-                    "description": "容器运行时",
+                "key": {
+                    "description": "键",
                     "type": "string",
-                    "example": "cri-o"
-                },
-                "status": {
-                    "description": "集群状态",
-                    "type": "string"
+                    "example": "dedicated"
                 },
-                "version": {
-                    "description": "版本",
+                "value": {
+                    "description": "值",
                     "type": "string",
-                    "example": "1.22.5"
+                    "example": "gpu"
                 }
             }
         },
-        "model.ClusterSummary": {
+        "model.TaskDetail": {
             "type": "object",
             "properties": {
+                "action": {
+                    "description": "任务操作",
+                    "type": "string",
+                    "example": "CreateCluster"
+                },
+                "clusterId": {
+                    "description": "集群ID",
+                    "type": "string",
+                    "example": "cluster-sedqqz7ka"
+                },
                 "createTime": {
                     "description": "创建时间",
                     "type": "string",
                     "example": "2006-01-02 15:04:05"
                 },
-                "description": {
-                    "description": "描述",
-                    "type": "string",
-                    "example": "amazing-cluster-description"
+                "id": {
+                    "description": "任务ID",
+                    "type": "integer",
+                    "example": 1
                 },
-                "k8sType": {
-                    "description": "类型：k8s、k3s",
+                "nodeId": {
+                    "description": "节点任务操作的节点ID",
                     "type": "string",
-                    "example": "k8s"
+                    "example": "node-k2jd8sm1qa"
                 },
-                "name": {
-                    "description": "名称",
+                "requestId": {
+                    "description": "提交任务的请求ID",
                     "type": "string",
-                    "example": "imortal-cluster-name"
+                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
                 },
-                "resourceID": {
-                    "description": "集群ID",
+                "retryAt": {
+                    "description": "下次重试时间，未重试时为空",
                     "type": "string",
-                    "example": "cluster-sedqqz7ka"
+                    "example": "2006-01-02 15:04:05"
                 },
-                "runtime": {
-                    "description": "容器运行时",
-                    "type": "string",
-                    "example": "cri-o"
+                "retryCount": {
+                    "description": "已重试次数",
+                    "type": "integer",
+                    "example": 3
+                },
+                "retryLimit": {
+                    "description": "重试次数限制",
+                    "type": "integer",
+                    "example": 150
                 },
                 "status": {
-                    "description": "集群状态",
-                    "type": "string"
+                    "description": "任务状态",
+                    "type": "string",
+                    "example": "Retrying"
+                },
+                "step": {
+                    "description": "已完成的步骤数，重试时从下一个步骤继续执行",
+                    "type": "integer",
+                    "example": 2
+                },
+                "updateTime": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
                 },
                 "version": {
-                    "description": "版本",
+                    "description": "升级任务的目标版本",
                     "type": "string",
-                    "example": "1.22.5"
+                    "example": "1.23.17"
                 }
             }
         },
-        "model.CreateClusterReq": {
+        "model.TaskLogItem": {
             "type": "object",
-            "required": [
-                "k8sType",
-                "runtime",
-                "version"
-            ],
             "properties": {
-                "description": {
-                    "description": "描述，支持 0~255 位字符",
+                "endTime": {
+                    "description": "结束时间",
                     "type": "string",
-                    "example": "amazing-cluster-description"
+                    "example": "2006-01-02 15:04:05"
                 },
-                "k8sType": {
-                    "description": "类型：k8s、k3s",
+                "id": {
+                    "description": "日志ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "message": {
+                    "description": "信息",
                     "type": "string",
-                    "enum": [
-                        "k8s",
-                        "k3s"
-                    ],
-                    "example": "k8s"
+                    "example": "host 192.168.1.10: dial tcp: i/o timeout"
                 },
-                "name": {
-                    "description": "名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)",
+                "reason": {
+                    "description": "原因",
                     "type": "string",
-                    "example": "imortal-cluster-name"
+                    "example": "Retrying"
                 },
-                "runtime": {
-                    "description": "容器运行时",
+                "startTime": {
+                    "description": "起始时间",
                     "type": "string",
-                    "enum": [
-                        "cri-o",
-                        "containerd"
-                    ],
-                    "example": "cri-o"
+                    "example": "2006-01-02 15:04:05"
                 },
-                "version": {
-                    "description": "版本",
+                "step": {
+                    "description": "步骤名称，任务状态变化的日志中为失败的步骤",
                     "type": "string",
-                    "example": "1.22.5"
+                    "example": "init control plane"
                 }
             }
         },
-        "model.CreateClusterResp": {
+        "model.TaskSummary": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "响应码",
+                "action": {
+                    "description": "任务操作",
                     "type": "string",
-                    "example": "Success"
+                    "example": "CreateCluster"
                 },
-                "data": {
-                    "description": "集群资源ID",
+                "clusterId": {
+                    "description": "集群ID",
                     "type": "string",
-                    "example": "cluster-sedqqz7kavbh"
+                    "example": "cluster-sedqqz7ka"
                 },
-                "message": {
-                    "description": "响应消息",
+                "createTime": {
+                    "description": "创建时间",
                     "type": "string",
-                    "example": "调用成功"
+                    "example": "2006-01-02 15:04:05"
+                },
+                "id": {
+                    "description": "任务ID",
+                    "type": "integer",
+                    "example": 1
                 },
                 "requestId": {
-                    "description": "请求ID",
+                    "description": "提交任务的请求ID",
                     "type": "string",
                     "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                },
+                "retryAt": {
+                    "description": "下次重试时间，未重试时为空",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
+                },
+                "retryCount": {
+                    "description": "已重试次数",
+                    "type": "integer",
+                    "example": 3
+                },
+                "retryLimit": {
+                    "description": "重试次数限制",
+                    "type": "integer",
+                    "example": 150
+                },
+                "status": {
+                    "description": "任务状态",
+                    "type": "string",
+                    "example": "Retrying"
+                },
+                "updateTime": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
                 }
             }
         },
-        "model.GetClusterResp": {
+        "model.UpdateProjectReq": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "响应码",
+                "clusterQuota": {
+                    "description": "集群数量配额，0 表示不限制，小于已有集群数量时只限制新建集群",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "description": {
+                    "description": "描述",
                     "type": "string",
-                    "example": "Success"
+                    "example": "clusters of team a"
+                }
+            }
+        },
+        "model.UpgradeClusterReq": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "version": {
+                    "description": "目标版本，不允许降级或跳过次版本",
+                    "type": "string",
+                    "example": "1.23.17"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "已投递次数",
+                    "type": "integer",
+                    "example": 1
                 },
-                "data": {
-                    "description": "集群详情",
-                    "$ref": "#/definitions/model.ClusterDetail"
+                "createTime": {
+                    "description": "创建时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
                 },
-                "message": {
-                    "description": "响应消息",
+                "eventId": {
+                    "description": "事件ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "eventType": {
+                    "description": "事件类型",
                     "type": "string",
-                    "example": "调用成功"
+                    "example": "cluster.running"
                 },
-                "requestId": {
-                    "description": "请求ID",
+                "id": {
+                    "description": "投递ID，与请求头 X-Webhook-Delivery 相同",
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastError": {
+                    "description": "最近一次投递的错误",
+                    "type": "string"
+                },
+                "nextAttemptTime": {
+                    "description": "下次投递时间，等待投递时有效",
                     "type": "string",
-                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
+                    "example": "2006-01-02 15:04:05"
+                },
+                "status": {
+                    "description": "投递状态：Pending、Succeeded、Failed",
+                    "type": "string",
+                    "example": "Succeeded"
+                },
+                "updateTime": {
+                    "description": "更新时间",
+                    "type": "string",
+                    "example": "2006-01-02 15:04:05"
                 }
             }
         },
-        "model.ListClusterResp": {
+        "model.WebhookSummary": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "响应码",
+                "createTime": {
+                    "description": "创建时间",
                     "type": "string",
-                    "example": "Success"
+                    "example": "2006-01-02 15:04:05"
                 },
-                "data": {
-                    "description": "集群概要列表",
+                "events": {
+                    "description": "订阅的事件类型",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.ClusterSummary"
-                    }
+                        "description": "事件类型",
+                        "type": "string",
+                        "example": "cluster.running"
+                    },
+                    "example": [
+                        "cluster.running",
+                        "task.failed"
+                    ]
                 },
-                "message": {
-                    "description": "响应消息",
+                "resourceID": {
+                    "description": "webhook 资源ID",
                     "type": "string",
-                    "example": "调用成功"
+                    "example": "webhook-k2jd8sm1qa"
                 },
-                "requestId": {
-                    "description": "请求ID",
+                "url": {
+                    "description": "投递地址",
                     "type": "string",
-                    "example": "6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"
-                },
-                "totalCount": {
-                    "description": "集群总数",
-                    "type": "integer",
-                    "example": 100
+                    "example": "https://example.com/hooks/cluster"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "API token or JWT, format: Bearer {token}",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
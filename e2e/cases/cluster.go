@@ -11,4 +11,22 @@ var StandardCreateClusterRequest = &model.CreateClusterReq{
 	Type:        "k8s",
 	Version:     "1.22.5",
 	Runtime:     "cri-o",
+	Hosts: []model.ClusterHost{
+		{IP: "127.0.0.1", Port: 22, Password: "e2e-password", Role: "master"},
+	},
+	Labels: map[string]string{"env": "e2e"},
+}
+
+// StandardUpgradeClusterRequest 标准集群升级请求
+var StandardUpgradeClusterRequest = &model.UpgradeClusterReq{
+	Version: "1.23.17",
+}
+
+// StandardAddNodeRequest 标准节点添加请求
+var StandardAddNodeRequest = &model.AddNodeReq{
+	IP:       "127.0.0.2",
+	Port:     22,
+	Password: "e2e-password",
+	Role:     "worker",
+	Labels:   map[string]string{"e2e": "true"},
 }
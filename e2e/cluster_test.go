@@ -1,7 +1,11 @@
 package e2e
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/e2e/cases"
@@ -37,7 +41,7 @@ var _ = Describe("Cluster", func() {
 		response := &model.CreateClusterResp{}
 		err := httpClient.POST(
 			ctx,
-			"/api/v1.0/clusters",
+			projectPath+"/clusters",
 			request,
 			response,
 		)
@@ -47,22 +51,60 @@ var _ = Describe("Cluster", func() {
 		// 保存集群ID
 		e2eClusterID = response.Data
 	})
+	It("ListAuditEvent", func(ctx SpecContext) {
+		request := &model.ListAuditEventReq{
+			Actor:    "anonymous",
+			PageSize: 100,
+		}
+		response := &model.ListAuditEventResp{}
+		err := httpClient.GET(
+			ctx,
+			auditPath,
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data).To(ContainElement(And(
+			HaveField("ResourceID", e2eClusterID),
+			HaveField("Method", http.MethodPost),
+			HaveField("ResponseCode", string(model.CodeSuccess)),
+		)))
+	})
+	It("WatchClusterEvents", func(ctx SpecContext) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s/clusters/%s/events", baseURL, projectPath, e2eClusterID), nil)
+		Expect(err).To(BeNil())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/event-stream"))
+		// 连接后先推送集群的当前状态
+		scanner := bufio.NewScanner(resp.Body)
+		lines := []string{}
+		for len(lines) < 3 && scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		Expect(lines).To(ContainElement("event:cluster"))
+		Expect(lines).To(ContainElement(ContainSubstring(e2eClusterID)))
+	})
 	It("ListCluster", func(ctx SpecContext) {
 		request := &model.ListClusterReq{
-			PageNo:   1,
-			PageSize: 10,
+			PageNo:        1,
+			PageSize:      10,
+			LabelSelector: "env=e2e",
 		}
 		response := &model.ListClusterResp{}
 		err := httpClient.GET(
 			ctx,
-			"/api/v1.0/clusters",
+			projectPath+"/clusters",
 			request,
 			response,
 		)
 		Expect(err).To(BeNil())
 		Expect(response.Data).NotTo(BeEmpty())
-		Expect(response.TotalCount).NotTo(BeZero())
-		GinkgoWriter.Printf("cluster totalCount: %d\n", response.TotalCount)
+		Expect(response.Data[0].Labels).To(HaveKeyWithValue("env", "e2e"))
+		Expect(response.TotalCount).NotTo(BeNil())
+		Expect(*response.TotalCount).NotTo(BeZero())
+		GinkgoWriter.Printf("cluster totalCount: %d\n", *response.TotalCount)
 	})
 	It("GetCluster", func(ctx SpecContext) {
 		Eventually(func(g Gomega) {
@@ -70,7 +112,7 @@ var _ = Describe("Cluster", func() {
 			response := &model.GetClusterResp{}
 			err := httpClient.GET(
 				ctx,
-				fmt.Sprintf("/api/v1.0/clusters/%s", e2eClusterID),
+				fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
 				request,
 				response,
 			)
@@ -79,15 +121,306 @@ var _ = Describe("Cluster", func() {
 			GinkgoWriter.Printf("cluster running: %s\n", e2eClusterID)
 		}, time.Minute*120, time.Minute*3).Should(Succeed())
 	})
+	It("GetClusterLegacyPath", func(ctx SpecContext) {
+		// 没有项目ID的旧路径绑定到默认项目
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1.0/clusters/%s", baseURL, e2eClusterID), nil)
+		Expect(err).To(BeNil())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Deprecation")).To(Equal("true"))
+		Expect(resp.Header.Get("Link")).To(ContainSubstring(fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID)))
+		response := &model.GetClusterResp{}
+		Expect(json.NewDecoder(resp.Body).Decode(response)).To(Succeed())
+		Expect(response.Code).To(Equal(model.CodeSuccess))
+		Expect(response.Data.ResourceID).To(Equal(e2eClusterID))
+		Expect(response.Data.ProjectID).To(Equal(storage.DefaultProjectID))
+	})
+	It("PatchCluster", func(ctx SpecContext) {
+		request := &model.PatchClusterReq{
+			Fields: map[string]json.RawMessage{
+				"description": json.RawMessage(`null`),
+				"labels":      json.RawMessage(`{"env":"e2e"}`),
+			},
+		}
+		response := &model.GetClusterResp{}
+		err := httpClient.PATCH(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data.Description).To(BeEmpty())
+		Expect(response.Data.Labels).To(HaveKeyWithValue("env", "e2e"))
+		// 不允许修改创建后固定的字段
+		request = &model.PatchClusterReq{
+			Fields: map[string]json.RawMessage{
+				"runtime": json.RawMessage(`"containerd"`),
+			},
+		}
+		err = httpClient.PATCH(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+			request,
+			&model.GetClusterResp{},
+		)
+		Expect(err).NotTo(BeNil())
+	})
+	It("ClusterIfMatch", func(ctx SpecContext) {
+		do := func(method string, ifMatch string, body string) (string, *model.GetClusterResp) {
+			req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s%s/clusters/%s", baseURL, projectPath, e2eClusterID), strings.NewReader(body))
+			Expect(err).To(BeNil())
+			req.Header.Set("Content-Type", "application/json")
+			if len(ifMatch) > 0 {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).To(BeNil())
+			defer resp.Body.Close()
+			response := &model.GetClusterResp{}
+			Expect(json.NewDecoder(resp.Body).Decode(response)).To(Succeed())
+			return resp.Header.Get("ETag"), response
+		}
+		// 集群详情返回资源版本
+		etag, response := do(http.MethodGet, "", "")
+		Expect(response.Code).To(Equal(model.CodeSuccess))
+		Expect(etag).To(Equal(fmt.Sprintf(`"%d"`, response.Data.ResourceVersion)))
+		// 携带匹配的 ETag 修改成功，返回新的资源版本
+		newETag, response := do(http.MethodPatch, etag, `{"labels":{"env":"e2e","owner":"e2e"}}`)
+		Expect(response.Code).To(Equal(model.CodeSuccess))
+		Expect(newETag).NotTo(BeEmpty())
+		Expect(newETag).NotTo(Equal(etag))
+		// 携带过期的 ETag 修改与删除返回冲突
+		_, response = do(http.MethodPatch, etag, `{"labels":{"env":"stale"}}`)
+		Expect(response.Code).To(Equal(model.CodeConflict))
+		_, response = do(http.MethodDelete, etag, "")
+		Expect(response.Code).To(Equal(model.CodeConflict))
+		// 冲突的请求不修改集群
+		_, response = do(http.MethodGet, "", "")
+		Expect(response.Code).To(Equal(model.CodeSuccess))
+		Expect(response.Data.Status).To(Equal(storage.ClusterStatusRunning.String()))
+		Expect(response.Data.Labels).To(HaveKeyWithValue("env", "e2e"))
+	})
+	It("ListTask", func(ctx SpecContext) {
+		request := &model.ListTaskReq{
+			PageNo:   1,
+			PageSize: 10,
+		}
+		response := &model.ListTaskResp{}
+		err := httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/tasks", projectPath, e2eClusterID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data).NotTo(BeEmpty())
+		task := response.Data[len(response.Data)-1]
+		Expect(task.Action).To(Equal(storage.ClusterActionCreate))
+		Expect(task.Status).To(Equal(storage.TaskStatusSuccess.String()))
+		// 查询任务日志
+		logRequest := &model.ListTaskLogReq{
+			PageNo:   1,
+			PageSize: 10,
+		}
+		logResponse := &model.ListTaskLogResp{}
+		err = httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/tasks/%d/logs", projectPath, task.ID),
+			logRequest,
+			logResponse,
+		)
+		Expect(err).To(BeNil())
+		Expect(logResponse.Data).NotTo(BeEmpty())
+		Expect(logResponse.Data[0].Reason).To(Equal(storage.TaskStatusSuccess.String()))
+	})
+	var e2eNodeID string
+	It("AddNode", func(ctx SpecContext) {
+		request := cases.StandardAddNodeRequest
+		response := &model.AddNodeResp{}
+		err := httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/nodes", projectPath, e2eClusterID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data).To(HavePrefix("node"))
+		GinkgoWriter.Printf("node added: %s\n", response.Data)
+		// 保存节点ID
+		e2eNodeID = response.Data
+		Eventually(func(g Gomega) {
+			g.Expect(getNodeStatus(ctx, g, e2eClusterID, e2eNodeID)).To(Equal(storage.NodeStatusRunning.String()))
+			GinkgoWriter.Printf("node running: %s\n", e2eNodeID)
+		}, time.Minute*60, time.Second*30).Should(Succeed())
+	})
+	It("NodeShellRequiresWebSocket", func(ctx SpecContext) {
+		// 未升级为 WebSocket 的请求返回参数错误
+		request := &model.NodeShellReq{}
+		response := &model.NodeShellResp{}
+		err := httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/nodes/%s/shell", projectPath, e2eClusterID, e2eNodeID),
+			request,
+			response,
+		)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring(string(model.CodeParamError)))
+	})
+	It("DrainNode", func(ctx SpecContext) {
+		request := &model.BaseRequest{}
+		response := &model.BaseResponse{}
+		err := httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/nodes/%s/drain", projectPath, e2eClusterID, e2eNodeID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Eventually(func(g Gomega) {
+			g.Expect(getNodeStatus(ctx, g, e2eClusterID, e2eNodeID)).To(Equal(storage.NodeStatusDrained.String()))
+			GinkgoWriter.Printf("node drained: %s\n", e2eNodeID)
+		}, time.Minute*30, time.Second*30).Should(Succeed())
+	})
+	It("RemoveNode", func(ctx SpecContext) {
+		request := &model.BaseRequest{}
+		response := &model.BaseResponse{}
+		err := httpClient.DELETE(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/nodes/%s", projectPath, e2eClusterID, e2eNodeID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Eventually(func(g Gomega) {
+			g.Expect(getNodeStatus(ctx, g, e2eClusterID, e2eNodeID)).To(BeEmpty())
+			GinkgoWriter.Printf("node removed: %s\n", e2eNodeID)
+		}, time.Minute*30, time.Second*30).Should(Succeed())
+	})
+	It("UpgradeCluster", func(ctx SpecContext) {
+		request := cases.StandardUpgradeClusterRequest
+		response := &model.BaseResponse{}
+		err := httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/upgrade", projectPath, e2eClusterID),
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Eventually(func(g Gomega) {
+			request := &model.GetClusterReq{}
+			response := &model.GetClusterResp{}
+			err := httpClient.GET(
+				ctx,
+				fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+				request,
+				response,
+			)
+			g.Expect(err).To(BeNil())
+			g.Expect(response.Data.Status).To(Equal(storage.ClusterStatusRunning.String()))
+			g.Expect(response.Data.Version).To(Equal(cases.StandardUpgradeClusterRequest.Version))
+			GinkgoWriter.Printf("cluster upgraded: %s\n", e2eClusterID)
+		}, time.Minute*60, time.Second*30).Should(Succeed())
+	})
 	It("DeleteCluster", func(ctx SpecContext) {
 		request := &model.BaseRequest{}
 		response := &model.BaseResponse{}
 		err := httpClient.DELETE(
 			ctx,
-			fmt.Sprintf("/api/v1.0/clusters/%s", e2eClusterID),
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
 			request,
 			response,
 		)
 		Expect(err).To(BeNil())
 	})
+	It("WaitClusterDeleted", func(ctx SpecContext) {
+		Eventually(func(g Gomega) {
+			request := &model.GetClusterReq{}
+			response := &model.GetClusterResp{}
+			err := httpClient.GET(
+				ctx,
+				fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+				request,
+				response,
+			)
+			g.Expect(err).NotTo(BeNil())
+			g.Expect(err.Error()).To(ContainSubstring(string(model.CodeNotExists)))
+			GinkgoWriter.Printf("cluster deleted: %s\n", e2eClusterID)
+		}, time.Minute*30, time.Second*10).Should(Succeed())
+	})
+	It("ListDeletedCluster", func(ctx SpecContext) {
+		request := &model.ListDeletedClusterReq{
+			ProjectID: storage.DefaultProjectID,
+			PageNo:    1,
+			PageSize:  100,
+		}
+		response := &model.ListDeletedClusterResp{}
+		err := httpClient.GET(
+			ctx,
+			adminPath+"/deleted-clusters",
+			request,
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data).To(ContainElement(HaveField("ResourceID", e2eClusterID)))
+		taskResponse := &model.ListDeletedTaskResp{}
+		err = httpClient.GET(
+			ctx,
+			adminPath+"/deleted-tasks",
+			&model.ListDeletedTaskReq{ClusterID: e2eClusterID, PageNo: 1, PageSize: 100},
+			taskResponse,
+		)
+		Expect(err).To(BeNil())
+		Expect(taskResponse.Data).NotTo(BeEmpty())
+	})
+	It("RestoreCluster", func(ctx SpecContext) {
+		err := httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/deleted-clusters/%s/restore", adminPath, e2eClusterID),
+			&model.RestoreClusterReq{},
+			&model.RestoreClusterResp{},
+		)
+		Expect(err).To(BeNil())
+		response := &model.GetClusterResp{}
+		err = httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+			&model.GetClusterReq{},
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data.Status).To(Equal(storage.ClusterStatusError.String()))
+		GinkgoWriter.Printf("cluster restored: %s\n", e2eClusterID)
+		// 恢复后再次删除
+		err = httpClient.DELETE(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+			&model.BaseRequest{},
+			&model.BaseResponse{},
+		)
+		Expect(err).To(BeNil())
+	})
 })
+
+// getNodeStatus 从节点列表中获取节点状态，节点不存在时返回空字符串
+func getNodeStatus(ctx SpecContext, g Gomega, clusterID string, nodeID string) string {
+	request := &model.ListNodeReq{
+		PageNo:   1,
+		PageSize: 100,
+	}
+	response := &model.ListNodeResp{}
+	err := httpClient.GET(
+		ctx,
+		fmt.Sprintf("%s/clusters/%s/nodes", projectPath, clusterID),
+		request,
+		response,
+	)
+	g.Expect(err).To(BeNil())
+	for _, node := range response.Data {
+		if node.ResourceID == nodeID {
+			return node.Status
+		}
+	}
+	return ""
+}
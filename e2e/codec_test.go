@@ -0,0 +1,76 @@
+package e2e
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Codec", func() {
+	checkCIDRPath := baseURL + "/api/v1.0/tools/check-cidr"
+	// do 发送请求，返回状态码、Content-Type 与响应体
+	do := func(ctx SpecContext, method string, url string, contentType string, accept string, body []byte) (int, string, []byte) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		Expect(err).To(BeNil())
+		if len(contentType) > 0 {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		Expect(err).To(BeNil())
+		return resp.StatusCode, resp.Header.Get("Content-Type"), data
+	}
+	It("CheckCIDRYaml", func(ctx SpecContext) {
+		body := []byte("podCidr: 10.244.0.0/16\nserviceCidr: 10.96.0.0/16\n")
+		status, contentType, data := do(ctx, http.MethodPost, checkCIDRPath, "application/x-yaml", "application/x-yaml", body)
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(contentType).To(HavePrefix("application/x-yaml"))
+		response := &model.CheckCIDRResp{}
+		Expect(yaml.Unmarshal(data, response)).To(Succeed())
+		Expect(response.Code).To(Equal(model.CodeSuccess))
+	})
+	It("CheckCIDRProto", func(ctx SpecContext) {
+		body, err := proto.Marshal(&pb.CheckCIDRReq{PodCidr: "10.96.0.0/16", ServiceCidr: "10.96.0.0/16"})
+		Expect(err).To(BeNil())
+		status, contentType, data := do(ctx, http.MethodPost, checkCIDRPath, "application/x-protobuf", "application/x-protobuf", body)
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(contentType).To(Equal("application/x-protobuf"))
+		response := &pb.CheckCIDRResp{}
+		Expect(proto.Unmarshal(data, response)).To(Succeed())
+		// 网段重叠
+		Expect(response.Code).To(Equal(string(model.CodeParamError)))
+		Expect(response.RequestId).NotTo(BeEmpty())
+	})
+	It("ListClusterProto", func(ctx SpecContext) {
+		status, _, data := do(ctx, http.MethodGet, baseURL+projectPath+"/clusters?pageSize=10&withTotal=true", "", "application/x-protobuf", nil)
+		Expect(status).To(Equal(http.StatusOK))
+		response := &pb.ListClusterResp{}
+		Expect(proto.Unmarshal(data, response)).To(Succeed())
+		Expect(response.Code).To(Equal(string(model.CodeSuccess)))
+		Expect(response.TotalCount).NotTo(BeNil())
+		Expect(response.Data).To(HaveLen(int(min(*response.TotalCount, 10))))
+	})
+	It("NotAcceptable", func(ctx SpecContext) {
+		// 只有集群、任务与工具接口支持 protobuf
+		status, _, data := do(ctx, http.MethodGet, baseURL+projectPath+"/webhooks", "", "application/x-protobuf", nil)
+		Expect(status).To(Equal(http.StatusNotAcceptable))
+		Expect(string(data)).To(ContainSubstring(string(model.CodeNotAcceptable)))
+	})
+	It("UnsupportedMediaType", func(ctx SpecContext) {
+		status, _, data := do(ctx, http.MethodPost, checkCIDRPath, "text/plain", "", []byte("podCidr=10.244.0.0/16"))
+		Expect(status).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(string(data)).To(ContainSubstring(string(model.CodeUnsupportedMediaType)))
+	})
+})
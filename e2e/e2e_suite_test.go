@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -15,6 +16,13 @@ func TestE2e(t *testing.T) {
 
 var (
 	baseURL = "http://127.0.0.1:8080"
+	// gRPC 服务的监听地址
+	grpcAddr = "127.0.0.1:9090"
+	// 集群与任务接口使用默认项目
+	projectPath = "/api/v1.0/projects/" + storage.DefaultProjectID
+	// 管理接口不限定项目
+	adminPath = "/api/v1.0/admin"
+	auditPath = "/api/v1.0/audit-events"
 )
 
 var httpClient *utils.HTTPClient
@@ -0,0 +1,79 @@
+package e2e
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("GRPC", func() {
+	var conn *grpc.ClientConn
+	BeforeEach(func() {
+		var err error
+		conn, err = grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).To(BeNil())
+		DeferCleanup(conn.Close)
+	})
+	It("Health", func(ctx SpecContext) {
+		response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		Expect(err).To(BeNil())
+		Expect(response.Status).To(Equal(healthpb.HealthCheckResponse_SERVING))
+	})
+	It("ListCluster", func(ctx SpecContext) {
+		ctx2 := metadata.AppendToOutgoingContext(ctx, "x-request-id", "e2e-grpc-list-cluster")
+		var header metadata.MD
+		response, err := pb.NewClusterServiceClient(conn).ListCluster(ctx2, &pb.ListClusterRequest{
+			ProjectId: storage.DefaultProjectID,
+			Query:     &pb.ListClusterReq{PageSize: 10, WithTotal: true},
+		}, grpc.Header(&header))
+		Expect(err).To(BeNil())
+		Expect(response.RequestId).To(Equal("e2e-grpc-list-cluster"))
+		Expect(header.Get("x-request-id")).To(ConsistOf("e2e-grpc-list-cluster"))
+		Expect(response.TotalCount).NotTo(BeNil())
+		Expect(response.Data).To(HaveLen(int(min(*response.TotalCount, 10))))
+		// 任务列表与任务详情
+		for _, cluster := range response.Data {
+			tasks, err := pb.NewTaskServiceClient(conn).ListTask(ctx, &pb.ListTaskRequest{
+				ProjectId: storage.DefaultProjectID,
+				ClusterId: cluster.ResourceId,
+				Query:     &pb.ListTaskReq{PageSize: 1},
+			})
+			Expect(err).To(BeNil())
+			if len(tasks.Data) == 0 {
+				continue
+			}
+			task, err := pb.NewTaskServiceClient(conn).GetTask(ctx, &pb.GetTaskRequest{
+				ProjectId: storage.DefaultProjectID,
+				TaskId:    tasks.Data[0].Id,
+			})
+			Expect(err).To(BeNil())
+			Expect(task.Data.ClusterId).To(Equal(cluster.ResourceId))
+			break
+		}
+	})
+	It("NotFound", func(ctx SpecContext) {
+		_, err := pb.NewClusterServiceClient(conn).GetCluster(ctx, &pb.GetClusterRequest{
+			ProjectId: storage.DefaultProjectID,
+			ClusterId: "cls-not-exists",
+		})
+		st := status.Convert(err)
+		Expect(st.Code()).To(Equal(codes.NotFound))
+		Expect(st.Details()).To(HaveLen(1))
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		Expect(ok).To(BeTrue())
+		Expect(info.Reason).To(Equal(string(model.CodeNotExists)))
+	})
+	It("InvalidArgument", func(ctx SpecContext) {
+		_, err := pb.NewTaskServiceClient(conn).GetTask(ctx, &pb.GetTaskRequest{ProjectId: storage.DefaultProjectID})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+})
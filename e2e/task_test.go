@@ -0,0 +1,86 @@
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/e2e/cases"
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Task", func() {
+	var e2eClusterID string
+	var e2eTaskID uint64
+	It("CancelTask", func(ctx SpecContext) {
+		createResponse := &model.CreateClusterResp{}
+		err := httpClient.POST(
+			ctx,
+			projectPath+"/clusters",
+			cases.StandardCreateClusterRequest,
+			createResponse,
+		)
+		Expect(err).To(BeNil())
+		e2eClusterID = createResponse.Data
+		// 获取创建任务并取消
+		listResponse := &model.ListTaskResp{}
+		err = httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s/tasks", projectPath, e2eClusterID),
+			&model.ListTaskReq{PageNo: 1, PageSize: 10},
+			listResponse,
+		)
+		Expect(err).To(BeNil())
+		Expect(listResponse.Data).To(HaveLen(1))
+		e2eTaskID = listResponse.Data[0].ID
+		err = httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/tasks/%d/cancel", projectPath, e2eTaskID),
+			&model.BaseRequest{},
+			&model.BaseResponse{},
+		)
+		Expect(err).To(BeNil())
+		taskResponse := &model.GetTaskResp{}
+		err = httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/tasks/%d", projectPath, e2eTaskID),
+			&model.GetTaskReq{},
+			taskResponse,
+		)
+		Expect(err).To(BeNil())
+		Expect(taskResponse.Data.Status).To(Equal(storage.TaskStatusCanceled.String()))
+		GinkgoWriter.Printf("task canceled: %d\n", e2eTaskID)
+	})
+	It("RetryTask", func(ctx SpecContext) {
+		err := httpClient.POST(
+			ctx,
+			fmt.Sprintf("%s/tasks/%d/retry", projectPath, e2eTaskID),
+			&model.BaseRequest{},
+			&model.BaseResponse{},
+		)
+		Expect(err).To(BeNil())
+		Eventually(func(g Gomega) {
+			response := &model.GetTaskResp{}
+			err := httpClient.GET(
+				ctx,
+				fmt.Sprintf("%s/tasks/%d", projectPath, e2eTaskID),
+				&model.GetTaskReq{},
+				response,
+			)
+			g.Expect(err).To(BeNil())
+			g.Expect(response.Data.Status).To(Equal(storage.TaskStatusSuccess.String()))
+			GinkgoWriter.Printf("task retried: %d\n", e2eTaskID)
+		}, time.Minute*120, time.Second*30).Should(Succeed())
+	})
+	It("DeleteCluster", func(ctx SpecContext) {
+		err := httpClient.DELETE(
+			ctx,
+			fmt.Sprintf("%s/clusters/%s", projectPath, e2eClusterID),
+			&model.BaseRequest{},
+			&model.BaseResponse{},
+		)
+		Expect(err).To(BeNil())
+	})
+})
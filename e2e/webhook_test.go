@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"fmt"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook", func() {
+	var e2eWebhookID string
+	It("CreateWebhook", func(ctx SpecContext) {
+		response := &model.CreateWebhookResp{}
+		err := httpClient.POST(
+			ctx,
+			projectPath+"/webhooks",
+			&model.CreateWebhookReq{
+				URL:    "http://203.0.113.10:1/webhook",
+				Secret: "e2e-webhook-secret",
+				Events: []storage.WebhookEventType{storage.WebhookEventClusterRunning, storage.WebhookEventTaskFailed},
+			},
+			response,
+		)
+		Expect(err).To(BeNil())
+		e2eWebhookID = response.Data
+		GinkgoWriter.Printf("webhook created: %s\n", e2eWebhookID)
+	})
+	It("CreateWebhookPrivate", func(ctx SpecContext) {
+		// 回环、链路本地与内网地址都不允许
+		for _, u := range []string{"http://127.0.0.1:8080/debug/pprof/", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.1/webhook", "http://[::1]/webhook"} {
+			err := httpClient.POST(
+				ctx,
+				projectPath+"/webhooks",
+				&model.CreateWebhookReq{
+					URL:    u,
+					Secret: "e2e-webhook-secret",
+					Events: []storage.WebhookEventType{storage.WebhookEventClusterRunning},
+				},
+				&model.CreateWebhookResp{},
+			)
+			Expect(err).To(MatchError(ContainSubstring(string(model.CodeParamError))), u)
+		}
+	})
+	It("ListWebhook", func(ctx SpecContext) {
+		response := &model.ListWebhookResp{}
+		err := httpClient.GET(
+			ctx,
+			projectPath+"/webhooks",
+			&model.ListWebhookReq{},
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.Data).To(ContainElement(HaveField("ResourceID", e2eWebhookID)))
+	})
+	It("ListWebhookDelivery", func(ctx SpecContext) {
+		response := &model.ListWebhookDeliveryResp{}
+		err := httpClient.GET(
+			ctx,
+			fmt.Sprintf("%s/webhooks/%s/deliveries", projectPath, e2eWebhookID),
+			&model.ListWebhookDeliveryReq{PageNo: 1, PageSize: 10},
+			response,
+		)
+		Expect(err).To(BeNil())
+		Expect(response.TotalCount).NotTo(BeNil())
+	})
+	It("DeleteWebhook", func(ctx SpecContext) {
+		err := httpClient.DELETE(
+			ctx,
+			fmt.Sprintf("%s/webhooks/%s", projectPath, e2eWebhookID),
+			&model.BaseRequest{},
+			&model.BaseResponse{},
+		)
+		Expect(err).To(BeNil())
+	})
+})
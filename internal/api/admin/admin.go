@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type ListDeletedClusterCtrl struct {
+	model.BaseController[model.ListDeletedClusterReq, model.ListDeletedClusterResp]
+}
+
+// @Summary     已删除的集群列表
+// @Description 分页获取已删除的集群，最近删除的集群在前，超过保留时长的集群会被彻底删除
+// @Tags        Admin
+// @Param       projectId query    string                       false "项目资源ID，为空时列出全部项目" extensions(x-example=project-default)
+// @Param       pageNo    query    int                          true  "分页号，默认为1"         extensions(x-example=1)
+// @Param       pageSize  query    int                          true  "分页大小，默认为10"       extensions(x-example=10)
+// @Response    200       {object} model.ListDeletedClusterResp "响应"
+// @Security    BearerAuth
+// @Router      /admin/deleted-clusters [get]
+func (ctrl *ListDeletedClusterCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	items, err := storage.ListDeletedCluster(req.ProjectID, (req.PageNo-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		logger.Errorf("list deleted cluster: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list deleted cluster")
+		return
+	}
+	count, err := storage.CountDeletedCluster(req.ProjectID)
+	if err != nil {
+		logger.Errorf("count deleted cluster: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "count deleted cluster")
+		return
+	}
+	ctrl.Response.Data = make([]model.DeletedCluster, 0, len(items))
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.DeletedCluster{
+			Name:       item.Name,
+			ResourceID: item.ResourceID,
+			ProjectID:  item.ProjectID,
+			Type:       item.Type,
+			Version:    item.Version,
+			CreateTime: utils.FormatTime(item.CreatedAt),
+			DeleteTime: utils.FormatTime(item.DeletedAt.Time),
+		})
+	}
+	ctrl.Response.TotalCount = count
+}
+
+type RestoreClusterCtrl struct {
+	model.BaseController[model.RestoreClusterReq, model.RestoreClusterResp]
+}
+
+// @Summary     恢复集群
+// @Description 恢复已删除的集群与集群的任务，集群的主机在删除时已重置，恢复后集群进入异常状态，可以再次删除，恢复的集群计入项目配额
+// @Tags        Admin
+// @Param       clusterId path     string                   true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Response    200       {object} model.RestoreClusterResp "响应"
+// @Security    BearerAuth
+// @Router      /admin/deleted-clusters/{clusterId}/restore [post]
+func (ctrl *RestoreClusterCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g).WithField("clusterID", g.Param("clusterId"))
+	cluster, err := storage.RestoreCluster(g.Param("clusterId"))
+	if err != nil {
+		switch err {
+		case storage.ErrDoesNotExist:
+			ctrl.Response.Update(model.CodeNotExists, "deleted cluster not found")
+		case storage.ErrQuotaExceeded:
+			ctrl.Response.Update(model.CodeQuotaExceeded, "cluster quota exceeded")
+		case storage.ErrConflict:
+			ctrl.Response.Update(model.CodeConflict, "cluster has been modified")
+		default:
+			logger.Errorf("restore cluster: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "restore cluster")
+		}
+		return
+	}
+	logger.WithField("projectID", cluster.ProjectID).Info("cluster restored")
+	ctrl.Response.Data = cluster.ResourceID
+}
+
+type ListDeletedTaskCtrl struct {
+	model.BaseController[model.ListDeletedTaskReq, model.ListDeletedTaskResp]
+}
+
+// @Summary     已删除的任务列表
+// @Description 分页获取已删除集群的任务，最新提交的任务在前，任务随集群一起删除与恢复
+// @Tags        Admin
+// @Param       clusterId query    string                    false "集群资源ID，为空时列出全部已删除集群的任务" extensions(x-example=cluster-sedqqz7ka)
+// @Param       pageNo    query    int                       true  "分页号，默认为1"               extensions(x-example=1)
+// @Param       pageSize  query    int                       true  "分页大小，默认为10"             extensions(x-example=10)
+// @Response    200       {object} model.ListDeletedTaskResp "响应"
+// @Security    BearerAuth
+// @Router      /admin/deleted-tasks [get]
+func (ctrl *ListDeletedTaskCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	items, err := storage.ListDeletedTask(req.ClusterID, (req.PageNo-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		logger.Errorf("list deleted task: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list deleted task")
+		return
+	}
+	count, err := storage.CountDeletedTask(req.ClusterID)
+	if err != nil {
+		logger.Errorf("count deleted task: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "count deleted task")
+		return
+	}
+	ctrl.Response.Data = make([]model.DeletedTask, 0, len(items))
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.DeletedTask{
+			ID:         item.ID,
+			ClusterID:  item.ResourceID,
+			ProjectID:  item.ProjectID,
+			Action:     item.Action,
+			Status:     item.Status.String(),
+			CreateTime: utils.FormatTime(item.CreatedAt),
+			DeleteTime: utils.FormatTime(item.DeletedAt.Time),
+		})
+	}
+	ctrl.Response.TotalCount = count
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"gitbub.com/wbuntu/gin-template/internal/api/middleware"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
 	"github.com/pkg/errors"
@@ -19,6 +20,8 @@ type Server struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	logger log.Logger
+	// 审计事件文件，未配置时为空
+	auditSink *middleware.AuditFileSink
 }
 
 func (s *Server) Setup(ctx context.Context, cfg *config.Config) error {
@@ -65,5 +68,15 @@ func (s *Server) Shutdown() error {
 		})
 	}
 	g.Wait()
+	if s.auditSink != nil {
+		if err := s.auditSink.Close(); err != nil {
+			return errors.Wrap(err, "close audit sink")
+		}
+	}
 	return nil
 }
+
+// Handler 处理 REST 接口的 handler，gRPC 服务通过它复用中间件与控制器
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
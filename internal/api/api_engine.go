@@ -6,13 +6,17 @@ import (
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"gitbub.com/wbuntu/gin-template/internal/api/middleware"
+	"gitbub.com/wbuntu/gin-template/internal/api/node"
 	"gitbub.com/wbuntu/gin-template/internal/model"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/metrics"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
 )
 
 func (s *Server) setupEngine(cfg *config.Config) error {
@@ -26,17 +30,26 @@ func (s *Server) setupEngine(cfg *config.Config) error {
 	g.Use(
 		gin.Recovery(),
 	)
-	// healthz && pprof && swagger && metrics
+	// 认证中间件，healthz、readyz、swagger 等通用路由不需要认证
+	auth, err := middleware.Auth(cfg)
+	if err != nil {
+		return errors.Wrap(err, "setup auth")
+	}
+	// healthz && swagger
 	s.addCommonRoutes(g)
+	// pprof && metrics
+	s.addDebugRoutes(g, cfg, auth)
 	// controller
-	s.addControllerRoute(g)
+	if err := s.addControllerRoute(g, cfg, auth); err != nil {
+		return err
+	}
 	// srv
 	s.srv = &http.Server{
 		Addr:    cfg.API.Addr,
 		Handler: g,
 	}
 	// tlsSrv
-	if len(cfg.API.TLSAddr) > 0 && len(cfg.API.TLSCrt) > 0 && len(cfg.API.TLSCrt) > 0 {
+	if len(cfg.API.TLSAddr) > 0 && len(cfg.API.TLSCrt) > 0 && len(cfg.API.TLSKey) > 0 {
 		s.tlsSrv = &http.Server{
 			Addr:    cfg.API.TLSAddr,
 			Handler: g,
@@ -54,22 +67,44 @@ func (s *Server) addCommonRoutes(g *gin.Engine) {
 	g.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, model.RespSuccess)
 	})
-	// pprof
-	pprof.Register(g)
 	// swagger
 	g.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, func(c *ginSwagger.Config) {
 		c.DefaultModelsExpandDepth = 10
 	}))
 }
 
-func (s *Server) addControllerRoute(g *gin.Engine) {
+// addDebugRoutes pprof 与 metrics 会暴露进程内部信息，启用认证时需要 admin:read 权限
+func (s *Server) addDebugRoutes(g *gin.Engine, cfg *config.Config, auth gin.HandlerFunc) {
+	debug := g.Group("", auth)
+	if cfg.Auth.Enable {
+		debug.Use(middleware.Authorize(storage.PermissionAdminRead))
+	}
+	// pprof
+	pprof.RouteRegister(debug)
+	// metrics
+	debug.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
+
+func (s *Server) addControllerRoute(g *gin.Engine, cfg *config.Config, auth gin.HandlerFunc) error {
 	v1 := g.Group("/api/v1.0")
 	// 配置中间件
 	v1.Use(
 		middleware.HeaderExtracter(),
 		middleware.RequestLogger(),
+		middleware.Metrics(),
+		auth,
 		middleware.Gzip(),
 	)
+	// 审计文件
+	if cfg.Audit.Enable && len(cfg.Audit.File) > 0 {
+		sink, err := middleware.NewAuditFileSink(cfg.Audit.File)
+		if err != nil {
+			return errors.Wrap(err, "setup audit sink")
+		}
+		s.auditSink = sink
+	}
+	// 节点终端
+	node.SetupShell(cfg)
 	// 配置 routes
 	funcMap := map[string]func(string, ...gin.HandlerFunc) gin.IRoutes{
 		http.MethodGet:     v1.GET,
@@ -88,12 +123,47 @@ func (s *Server) addControllerRoute(g *gin.Engine) {
 		if !ok {
 			continue
 		}
-		v.Middleware = append(v.Middleware, handlerFuncWrapper(v.Factory))
+		// 修改类请求支持幂等键，在权限校验之后执行
+		if cfg.General.EnableDB && isMutatingMethod(v.Method) {
+			v.Middleware = append([]gin.HandlerFunc{middleware.Idempotency(cfg.API.IdempotencyTTL)}, v.Middleware...)
+		}
+		// 启用认证时，在执行控制器前校验路由声明的权限
+		if cfg.Auth.Enable && len(v.Permission) > 0 {
+			v.Middleware = append([]gin.HandlerFunc{middleware.Authorize(v.Permission)}, v.Middleware...)
+		}
+		// 修改类请求记录审计事件，在权限校验之前执行，被拒绝的请求同样记录
+		if cfg.Audit.Enable && isMutatingMethod(v.Method) {
+			v.Middleware = append([]gin.HandlerFunc{middleware.Audit(cfg.General.EnableDB, s.auditSink)}, v.Middleware...)
+		}
+		// 兼容路由绑定项目ID，在审计与权限校验之前执行
+		if len(v.ProjectID) > 0 {
+			v.Middleware = append([]gin.HandlerFunc{middleware.BindProject(v.ProjectID)}, v.Middleware...)
+		}
+		// 校验并设置允许的内容编码
+		if err := v.CheckCodecs(); err != nil {
+			return errors.Wrapf(err, "route %s %s", v.Method, v.Path)
+		}
+		codecs := v.Codecs
+		if len(codecs) == 0 {
+			codecs = model.DefaultCodecs
+		}
+		v.Middleware = append(v.Middleware, handlerFuncWrapper(v.Factory, codecs))
 		fn(v.Path, v.Middleware...)
 	}
+	return nil
 }
 
-func handlerFuncWrapper(factory func() model.Controller) gin.HandlerFunc {
+// isMutatingMethod 是否为修改资源的请求方法
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func handlerFuncWrapper(factory func() model.Controller, codecs []model.Codec) gin.HandlerFunc {
 	return func(g *gin.Context) {
 		// 根据类型创建实例
 		ctrlInstance := factory()
@@ -110,12 +180,29 @@ func handlerFuncWrapper(factory func() model.Controller) gin.HandlerFunc {
 		// 跳过自动化的 request 反序列化和 response 序列化，由 controller 直接处理请求，例如 Websocket、SSE、Proxy 等
 		if ctrlInstance.Codec().Streaming() {
 			ctrlInstance.Serve(g)
+			// 未开始推送时返回控制器设置的响应，例如资源不存在
+			if !g.Writer.Written() {
+				g.Set(middleware.GinCtxResponseCode, response.GetCode())
+				g.JSON(http.StatusOK, response)
+			}
+			return
+		}
+		// 根据 Accept 与 Content-Type 头部协商响应与请求的编码，不支持响应格式时使用 JSON 返回错误
+		respCodec, ok := model.NegotiateResponseCodec(g, codecs)
+		if !ok {
+			renderError(g, model.CodecJSON, http.StatusNotAcceptable, model.CodeNotAcceptable, "accept "+g.GetHeader("Accept"))
+			return
+		}
+		reqCodec, ok := model.NegotiateRequestCodec(g, codecs)
+		if !ok {
+			renderError(g, respCodec, http.StatusUnsupportedMediaType, model.CodeUnsupportedMediaType, "content type "+g.ContentType())
 			return
 		}
 		// 反序列化请求
-		if err := ctrlInstance.Codec().BindRequest(g, request); err != nil {
+		if err := reqCodec.BindRequest(g, request); err != nil {
 			log.G(g).Errorf("unmarshal and validate request: %s", err)
-			g.JSON(http.StatusOK, model.BaseResponse{
+			g.Set(middleware.GinCtxResponseCode, string(model.CodeParamError))
+			respCodec.RenderResponse(g, http.StatusOK, &model.BaseResponse{
 				Code:    model.CodeParamError,
 				Message: fmt.Sprintf("param error: %s", err),
 			})
@@ -124,6 +211,15 @@ func handlerFuncWrapper(factory func() model.Controller) gin.HandlerFunc {
 		// 执行控制器逻辑
 		ctrlInstance.Serve(g)
 		// 序列化响应
-		ctrlInstance.Codec().RenderResponse(g, response)
+		g.Set(middleware.GinCtxResponseCode, response.GetCode())
+		respCodec.RenderResponse(g, http.StatusOK, response)
 	}
 }
+
+// renderError 使用指定的编码返回错误响应
+func renderError(g *gin.Context, codec model.Codec, status int, code model.Code, message string) {
+	resp := &model.BaseResponse{RequestID: g.GetString(middleware.GinCtxRequestID)}
+	resp.Update(code, message)
+	g.Set(middleware.GinCtxResponseCode, string(code))
+	codec.RenderResponse(g, status, resp)
+}
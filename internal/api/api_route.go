@@ -2,28 +2,134 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
+	"gitbub.com/wbuntu/gin-template/internal/api/admin"
+	"gitbub.com/wbuntu/gin-template/internal/api/audit"
 	"gitbub.com/wbuntu/gin-template/internal/api/cluster"
+	"gitbub.com/wbuntu/gin-template/internal/api/node"
+	"gitbub.com/wbuntu/gin-template/internal/api/project"
+	"gitbub.com/wbuntu/gin-template/internal/api/role"
+	"gitbub.com/wbuntu/gin-template/internal/api/task"
 	"gitbub.com/wbuntu/gin-template/internal/api/tools"
+	"gitbub.com/wbuntu/gin-template/internal/api/webhook"
 	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
 )
 
 func getRoutes() []model.Route {
 	routes := []model.Route{}
+	routes = append(routes, projectRoute...)
 	routes = append(routes, clusterRoute...)
+	routes = append(routes, nodeRoute...)
+	routes = append(routes, taskRoute...)
+	routes = append(routes, webhookRoute...)
+	routes = append(routes, roleRoute...)
+	routes = append(routes, adminRoute...)
+	routes = append(routes, auditRoute...)
 	routes = append(routes, toolsRoute...)
+	// 项目化之前的集群、节点与任务路由，已废弃
+	routes = append(routes, legacyRoutes(clusterRoute, nodeRoute, taskRoute)...)
 	return routes
 }
 
+// 项目路由的前缀
+const projectPathPrefix = "/projects/:projectId"
+
+// legacyRoutes 去掉项目前缀生成兼容路由，绑定到默认项目
+func legacyRoutes(groups ...[]model.Route) []model.Route {
+	routes := []model.Route{}
+	for _, group := range groups {
+		for _, v := range group {
+			if !strings.HasPrefix(v.Path, projectPathPrefix+"/") {
+				continue
+			}
+			v.Path = strings.TrimPrefix(v.Path, projectPathPrefix)
+			v.ProjectID = storage.DefaultProjectID
+			routes = append(routes, v)
+		}
+	}
+	return routes
+}
+
+var projectRoute = []model.Route{
+	// project
+	{Method: http.MethodPost, Path: "/projects", Permission: storage.PermissionProjectUpdate, Factory: func() model.Controller { return new(project.CreateProjectCtrl) }},
+	{Method: http.MethodGet, Path: "/projects", Permission: storage.PermissionProjectRead, Factory: func() model.Controller { return new(project.ListProjectCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId", Permission: storage.PermissionProjectRead, Factory: func() model.Controller { return new(project.GetProjectCtrl) }},
+	{Method: http.MethodPut, Path: "/projects/:projectId", Permission: storage.PermissionProjectUpdate, Factory: func() model.Controller { return new(project.UpdateProjectCtrl) }},
+}
+
+// 集群、任务与工具接口提供了 protobuf 消息，在默认编码之外支持 protobuf，gRPC 服务通过 protobuf 编码调用这些接口
+var protoCodecs = []model.Codec{model.CodecJSON, model.CodecYaml, model.CodecProto}
+
+// 集群、节点与任务的路由都以项目为前缀，存储层按项目过滤，调用者无法访问其他项目的资源
 var clusterRoute = []model.Route{
 	// cluster
-	{Method: http.MethodPost, Path: "/clusters", Factory: func() model.Controller { return new(cluster.CreateClusterCtrl) }},
-	{Method: http.MethodDelete, Path: "/clusters/:clusterId", Factory: func() model.Controller { return new(cluster.DeleteClusterCtrl) }},
-	{Method: http.MethodGet, Path: "/clusters/:clusterId", Factory: func() model.Controller { return new(cluster.GetClusterCtrl) }},
-	{Method: http.MethodGet, Path: "/clusters", Factory: func() model.Controller { return new(cluster.ListClusterCtrl) }},
+	{Method: http.MethodPost, Path: "/projects/:projectId/clusters", Permission: storage.PermissionClusterCreate, Codecs: protoCodecs, Factory: func() model.Controller { return new(cluster.CreateClusterCtrl) }},
+	{Method: http.MethodDelete, Path: "/projects/:projectId/clusters/:clusterId", Permission: storage.PermissionClusterDelete, Codecs: protoCodecs, Factory: func() model.Controller { return new(cluster.DeleteClusterCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId", Permission: storage.PermissionClusterRead, Codecs: protoCodecs, Factory: func() model.Controller { return new(cluster.GetClusterCtrl) }},
+	{Method: http.MethodPatch, Path: "/projects/:projectId/clusters/:clusterId", Permission: storage.PermissionClusterUpdate, Factory: func() model.Controller { return new(cluster.PatchClusterCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters", Permission: storage.PermissionClusterRead, Codecs: protoCodecs, Factory: func() model.Controller { return new(cluster.ListClusterCtrl) }},
+	{Method: http.MethodPost, Path: "/projects/:projectId/clusters/:clusterId/upgrade", Permission: storage.PermissionClusterUpdate, Codecs: protoCodecs, Factory: func() model.Controller { return new(cluster.UpgradeClusterCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId/events", Permission: storage.PermissionClusterRead, Factory: func() model.Controller { return new(cluster.ClusterEventsCtrl) }},
+}
+
+var nodeRoute = []model.Route{
+	// node
+	{Method: http.MethodPost, Path: "/projects/:projectId/clusters/:clusterId/nodes", Permission: storage.PermissionClusterUpdate, Factory: func() model.Controller { return new(node.AddNodeCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId/nodes", Permission: storage.PermissionClusterRead, Factory: func() model.Controller { return new(node.ListNodeCtrl) }},
+	{Method: http.MethodPost, Path: "/projects/:projectId/clusters/:clusterId/nodes/:nodeId/drain", Permission: storage.PermissionClusterUpdate, Factory: func() model.Controller { return new(node.DrainNodeCtrl) }},
+	{Method: http.MethodDelete, Path: "/projects/:projectId/clusters/:clusterId/nodes/:nodeId", Permission: storage.PermissionClusterUpdate, Factory: func() model.Controller { return new(node.RemoveNodeCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId/nodes/:nodeId/shell", Permission: storage.PermissionNodeShell, Factory: func() model.Controller { return new(node.NodeShellCtrl) }},
+	// node pool
+	{Method: http.MethodPost, Path: "/projects/:projectId/clusters/:clusterId/nodepools", Permission: storage.PermissionClusterUpdate, Factory: func() model.Controller { return new(node.CreateNodePoolCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId/nodepools", Permission: storage.PermissionClusterRead, Factory: func() model.Controller { return new(node.ListNodePoolCtrl) }},
+}
+
+var taskRoute = []model.Route{
+	// task
+	{Method: http.MethodGet, Path: "/projects/:projectId/clusters/:clusterId/tasks", Permission: storage.PermissionTaskRead, Codecs: protoCodecs, Factory: func() model.Controller { return new(task.ListTaskCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/tasks/:taskId", Permission: storage.PermissionTaskRead, Codecs: protoCodecs, Factory: func() model.Controller { return new(task.GetTaskCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/tasks/:taskId/logs", Permission: storage.PermissionTaskRead, Codecs: protoCodecs, Factory: func() model.Controller { return new(task.ListTaskLogCtrl) }},
+	{Method: http.MethodPost, Path: "/projects/:projectId/tasks/:taskId/cancel", Permission: storage.PermissionTaskUpdate, Codecs: protoCodecs, Factory: func() model.Controller { return new(task.CancelTaskCtrl) }},
+	{Method: http.MethodPost, Path: "/projects/:projectId/tasks/:taskId/retry", Permission: storage.PermissionTaskUpdate, Codecs: protoCodecs, Factory: func() model.Controller { return new(task.RetryTaskCtrl) }},
+}
+
+var webhookRoute = []model.Route{
+	// webhook
+	{Method: http.MethodPost, Path: "/projects/:projectId/webhooks", Permission: storage.PermissionWebhookUpdate, Factory: func() model.Controller { return new(webhook.CreateWebhookCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/webhooks", Permission: storage.PermissionWebhookRead, Factory: func() model.Controller { return new(webhook.ListWebhookCtrl) }},
+	{Method: http.MethodDelete, Path: "/projects/:projectId/webhooks/:webhookId", Permission: storage.PermissionWebhookUpdate, Factory: func() model.Controller { return new(webhook.DeleteWebhookCtrl) }},
+	{Method: http.MethodGet, Path: "/projects/:projectId/webhooks/:webhookId/deliveries", Permission: storage.PermissionWebhookRead, Factory: func() model.Controller { return new(webhook.ListWebhookDeliveryCtrl) }},
+}
+
+var roleRoute = []model.Route{
+	// role
+	{Method: http.MethodPost, Path: "/roles", Permission: storage.PermissionRoleUpdate, Factory: func() model.Controller { return new(role.CreateRoleCtrl) }},
+	{Method: http.MethodGet, Path: "/roles", Permission: storage.PermissionRoleRead, Factory: func() model.Controller { return new(role.ListRoleCtrl) }},
+	{Method: http.MethodDelete, Path: "/roles/:roleName", Permission: storage.PermissionRoleUpdate, Factory: func() model.Controller { return new(role.DeleteRoleCtrl) }},
+	// role binding
+	{Method: http.MethodPost, Path: "/rolebindings", Permission: storage.PermissionRoleUpdate, Factory: func() model.Controller { return new(role.CreateRoleBindingCtrl) }},
+	{Method: http.MethodGet, Path: "/rolebindings", Permission: storage.PermissionRoleRead, Factory: func() model.Controller { return new(role.ListRoleBindingCtrl) }},
+	{Method: http.MethodDelete, Path: "/rolebindings/:bindingId", Permission: storage.PermissionRoleUpdate, Factory: func() model.Controller { return new(role.DeleteRoleBindingCtrl) }},
+}
+
+// 管理接口跨项目访问已删除的集群与任务，只能通过不限定项目的角色绑定授权
+var adminRoute = []model.Route{
+	// deleted cluster
+	{Method: http.MethodGet, Path: "/admin/deleted-clusters", Permission: storage.PermissionAdminRead, Factory: func() model.Controller { return new(admin.ListDeletedClusterCtrl) }},
+	{Method: http.MethodPost, Path: "/admin/deleted-clusters/:clusterId/restore", Permission: storage.PermissionAdminUpdate, Factory: func() model.Controller { return new(admin.RestoreClusterCtrl) }},
+	// deleted task
+	{Method: http.MethodGet, Path: "/admin/deleted-tasks", Permission: storage.PermissionAdminRead, Factory: func() model.Controller { return new(admin.ListDeletedTaskCtrl) }},
+}
+
+var auditRoute = []model.Route{
+	// audit event
+	{Method: http.MethodGet, Path: "/audit-events", Permission: storage.PermissionAuditRead, Factory: func() model.Controller { return new(audit.ListAuditEventCtrl) }},
 }
 
 var toolsRoute = []model.Route{
 	// utils
-	{Method: http.MethodPost, Path: "/tools/check-cidr", Factory: func() model.Controller { return new(tools.CheckCIDRCtrl) }},
+	{Method: http.MethodPost, Path: "/tools/check-cidr", Codecs: protoCodecs, Factory: func() model.Controller { return new(tools.CheckCIDRCtrl) }},
 }
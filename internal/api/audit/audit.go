@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type ListAuditEventCtrl struct {
+	model.BaseController[model.ListAuditEventReq, model.ListAuditEventResp]
+}
+
+// @Summary     审计事件列表
+// @Description 分页获取修改类接口的审计事件，最新的事件在前，请求体中的密码、令牌等敏感字段已脱敏
+// @Tags        Audit
+// @Param       actor     query    string                   false "调用者身份"                              extensions(x-example=anonymous)
+// @Param       startTime query    int                      false "起始时间，unix 时间戳（秒）"                   extensions(x-example=1700000000)
+// @Param       endTime   query    int                      false "结束时间，unix 时间戳（秒）"                   extensions(x-example=1900000000)
+// @Param       pageNo    query    int                      false "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数" extensions(x-example=1)
+// @Param       pageSize  query    int                      true  "分页大小，默认为10"                         extensions(x-example=10)
+// @Param       pageToken query    string                   false "分页令牌，使用上一页返回的 nextPageToken 获取下一页"
+// @Param       withTotal query    bool                     false "是否返回总数，使用页码分页时总是返回"
+// @Response    200       {object} model.ListAuditEventResp "响应"
+// @Security    BearerAuth
+// @Router      /audit-events [get]
+func (ctrl *ListAuditEventCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	var start, end time.Time
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	query := &storage.ListQuery{SortOrder: storage.SortOrderDesc}
+	query.Where(storage.AuditEventFilters(req.Actor, start, end)...)
+	// 分页令牌只能用于过滤条件相同的查询
+	query.Scope = utils.QueryScope(g.Request.URL, model.PageParams...)
+	if err := query.SetPage(req.PageNo, req.PageSize, req.PageToken); err != nil {
+		logger.Errorf("set page: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	items, nextPageToken, err := storage.ListAuditEvent(query)
+	if err != nil {
+		logger.Errorf("list audit event: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list audit event")
+		return
+	}
+	// 使用页码分页时总是计算总数
+	if req.PageNo > 0 || req.WithTotal {
+		count, err := storage.CountAuditEvent(query)
+		if err != nil {
+			logger.Errorf("count audit event: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "count audit event")
+			return
+		}
+		ctrl.Response.TotalCount = &count
+	}
+	ctrl.Response.Data = make([]model.AuditEventItem, 0, len(items))
+	for i := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.NewAuditEventItem(&items[i]))
+	}
+	ctrl.Response.NextPageToken = nextPageToken
+}
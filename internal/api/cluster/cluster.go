@@ -1,10 +1,11 @@
 package cluster
 
 import (
+	"encoding/json"
 	"os"
 	"strconv"
 	"strings"
-	"time"
+	"unicode/utf8"
 
 	"gitbub.com/wbuntu/gin-template/internal/model"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
@@ -12,7 +13,10 @@ import (
 	"gitbub.com/wbuntu/gin-template/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 type CreateClusterCtrl struct {
@@ -22,11 +26,27 @@ type CreateClusterCtrl struct {
 // @Summary     创建集群
 // @Description 根据配置参数自动创建集群
 // @Tags        Cluster
+// @Accept      json,application/x-yaml,application/x-protobuf
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId        path     string                  true "项目资源ID" extensions(x-example=project-default)
 // @Param       CreateClusterReq body     model.CreateClusterReq  true "请求"
 // @Response    200              {object} model.CreateClusterResp "响应"
-// @Router      /clusters [post]
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters [post]
 func (ctrl *CreateClusterCtrl) Serve(g *gin.Context) {
 	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 检查参数
+	if err := checkCreateClusterReq(req); err != nil {
+		logger.Errorf("check request: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 获取项目
+	project, ok := getProject(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
 	// 生成集群资源ID
 	resourceID, err := storage.GenerateClusterResourceID()
 	if err != nil {
@@ -34,15 +54,17 @@ func (ctrl *CreateClusterCtrl) Serve(g *gin.Context) {
 		ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
 		return
 	}
-	req := &ctrl.Request
 	cluster := &storage.Cluster{
-		Name:        req.Name,
-		Description: req.Description,
-		ResourceID:  resourceID.ResourceID,
-		Type:        req.Type,
-		Version:     req.Version,
-		Runtime:     req.Runtime,
-		Status:      storage.ClusterStatusCreating,
+		Name:            req.Name,
+		Description:     req.Description,
+		ResourceID:      resourceID.ResourceID,
+		ProjectID:       project.ResourceID,
+		Type:            req.Type,
+		Version:         req.Version,
+		Runtime:         req.Runtime,
+		Status:          storage.ClusterStatusCreating,
+		ResourceVersion: 1,
+		Annotations:     datatypes.JSONType[map[string]string]{Data: req.Annotations},
 	}
 	// 超过150分钟未Ready判定为异常
 	limit := uint16(150)
@@ -53,6 +75,7 @@ func (ctrl *CreateClusterCtrl) Serve(g *gin.Context) {
 	}
 	task := &storage.Task{
 		ResourceID: cluster.ResourceID,
+		ProjectID:  cluster.ProjectID,
 		RequestID:  req.RequestID,
 		Status:     storage.TaskStatusEnqueued,
 		RetryType:  storage.TaskRetryTypeFixed,
@@ -61,16 +84,51 @@ func (ctrl *CreateClusterCtrl) Serve(g *gin.Context) {
 		RetryLimit: limit,
 		Action:     storage.ClusterActionCreate,
 	}
-	// 在事务中创建集群、提交任务
+	// 生成集群节点与登录凭据
+	nodes := make([]*storage.Node, 0, len(req.Hosts))
+	credentials := make([]*storage.Credential, 0, len(req.Hosts))
+	for _, h := range req.Hosts {
+		node, credential, err := storage.NewNodeWithCredential(cluster.ResourceID, storage.ClusterHost{
+			IP:       h.IP,
+			Port:     h.Port,
+			Password: h.Password,
+			Role:     h.Role,
+		})
+		if err != nil {
+			logger.Errorf("new node: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
+			return
+		}
+		nodes = append(nodes, node)
+		credentials = append(credentials, credential)
+	}
+	// 在事务中检查项目配额，创建集群、节点，提交任务
 	if err := storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := storage.CheckClusterQuota(tx, project.ResourceID); err != nil {
+			return err
+		}
 		if err := tx.Create(cluster).Error; err != nil {
 			return errors.Wrap(err, "create cluster")
 		}
+		if err := storage.ReplaceClusterLabels(tx, cluster.ResourceID, req.Labels); err != nil {
+			return err
+		}
+		if err := tx.Create(credentials).Error; err != nil {
+			return errors.Wrap(err, "create credentials")
+		}
+		if err := tx.Create(nodes).Error; err != nil {
+			return errors.Wrap(err, "create nodes")
+		}
 		if err := tx.Create(task).Error; err != nil {
 			return errors.Wrap(err, "create task")
 		}
 		return nil
 	}); err != nil {
+		if err == storage.ErrQuotaExceeded {
+			logger.WithField("projectID", project.ResourceID).Warnf("cluster quota %d exceeded", project.ClusterQuota)
+			ctrl.Response.Update(model.CodeQuotaExceeded, "cluster quota exceeded")
+			return
+		}
 		logger.Errorf("transaction: %s", err)
 		ctrl.Response.Update(model.CodeInternalError, "commit request")
 		return
@@ -79,10 +137,90 @@ func (ctrl *CreateClusterCtrl) Serve(g *gin.Context) {
 		"clusterID": task.ResourceID,
 		"taskID":    task.ID,
 	}).Info("create cluster task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, task.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
 	// 返回响应
 	ctrl.Response.Data = resourceID.ResourceID
 }
 
+// getProject 根据路径参数获取项目，出错时更新响应
+func getProject(g *gin.Context, logger log.Logger, resp *model.BaseResponse) (*storage.Project, bool) {
+	projectID := g.Param("projectId")
+	project, err := storage.GetProjectByResourceID(projectID)
+	if err != nil {
+		logger.WithField("projectID", projectID).Errorf("get project: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "project not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get project")
+		}
+		return nil, false
+	}
+	return project, true
+}
+
+// checkIfMatch 检查 If-Match 头部是否匹配集群的资源版本，不匹配时更新响应
+func checkIfMatch(g *gin.Context, logger log.Logger, resp *model.BaseResponse, cluster *storage.Cluster) bool {
+	ifMatch := g.GetHeader("If-Match")
+	if utils.MatchETag(ifMatch, cluster.ResourceVersion) {
+		return true
+	}
+	logger.WithField("clusterID", cluster.ResourceID).Warnf("if-match %s mismatch resource version %d", ifMatch, cluster.ResourceVersion)
+	resp.Update(model.CodeConflict, "resource version mismatch")
+	return false
+}
+
+func checkCreateClusterReq(req *model.CreateClusterReq) error {
+	if err := utils.CheckName(req.Name); err != nil {
+		return errors.Wrap(err, "invalid name")
+	}
+	if err := checkDescription(req.Description); err != nil {
+		return err
+	}
+	if err := utils.CheckLabels(req.Labels); err != nil {
+		return err
+	}
+	if err := utils.CheckAnnotations(req.Annotations); err != nil {
+		return err
+	}
+	// 版本号会被拼接到部署命令中，只保存规范化的版本号
+	version, err := utils.ParseVersion(req.Version)
+	if err != nil {
+		return err
+	}
+	req.Version = version.String()
+	// k3s 内置 containerd
+	if req.Type == storage.K8sTypeK3s && req.Runtime != "containerd" {
+		return errors.Errorf("unsupported runtime for k3s: %s", req.Runtime)
+	}
+	// 至少包含一个控制面节点，且主机不重复
+	masters := 0
+	ips := map[string]struct{}{}
+	for _, h := range req.Hosts {
+		if h.Role == storage.HostRoleMaster {
+			masters++
+		}
+		if _, ok := ips[h.IP]; ok {
+			return errors.Errorf("duplicated host: %s", h.IP)
+		}
+		ips[h.IP] = struct{}{}
+	}
+	if masters == 0 {
+		return errors.New("no master host")
+	}
+	return nil
+}
+
+// checkDescription 检查描述长度，支持 0~255 位字符
+func checkDescription(description string) error {
+	if utf8.RuneCountInString(description) > 255 {
+		return errors.New("invalid description: length mismatch")
+	}
+	return nil
+}
+
 type DeleteClusterCtrl struct {
 	model.BaseController[model.BaseRequest, model.BaseResponse]
 }
@@ -90,15 +228,19 @@ type DeleteClusterCtrl struct {
 // @Summary     删除集群
 // @Description 根据集群ID自动删除集群
 // @Tags        Cluster
-// @Param       clusterId path     string             true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string             true  "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId path     string             true  "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       If-Match  header   string             false "集群详情返回的 ETag，不匹配时返回 Conflict"
 // @Response    200       {object} model.BaseResponse "响应"
-// @Router      /clusters/{clusterId} [delete]
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId} [delete]
 func (ctrl *DeleteClusterCtrl) Serve(g *gin.Context) {
 	logger := log.GetLogger(g)
 	// 获取资源ID
 	clusterID := g.Param("clusterId")
 	// 获取集群
-	cluster, err := storage.GetClusterByResourceID(clusterID)
+	cluster, err := storage.GetProjectClusterByResourceID(g.Param("projectId"), clusterID)
 	if err != nil {
 		logger.WithField("clusterID", clusterID).Errorf("get cluster: %s", err)
 		if err == storage.ErrDoesNotExist {
@@ -108,6 +250,9 @@ func (ctrl *DeleteClusterCtrl) Serve(g *gin.Context) {
 		}
 		return
 	}
+	if !checkIfMatch(g, logger, &ctrl.Response, cluster) {
+		return
+	}
 	// 检查状态，稳态才可以提交任务
 	if cluster.Status < storage.ClusterStatusRunning {
 		logger.WithField("clusterID", clusterID).Error("cluster status pending")
@@ -117,6 +262,7 @@ func (ctrl *DeleteClusterCtrl) Serve(g *gin.Context) {
 	// 超过30分钟未删除判定为异常
 	task := &storage.Task{
 		ResourceID: cluster.ResourceID,
+		ProjectID:  cluster.ProjectID,
 		RequestID:  ctrl.Request.RequestID,
 		Status:     storage.TaskStatusEnqueued,
 		RetryType:  storage.TaskRetryTypeFixed,
@@ -128,8 +274,8 @@ func (ctrl *DeleteClusterCtrl) Serve(g *gin.Context) {
 	// 在事务中更改集群状态、提交任务
 	cluster.Status = storage.ClusterStatusDeleting
 	if err := storage.DB().Transaction(func(tx *gorm.DB) error {
-		if err := tx.Updates(cluster).Error; err != nil {
-			return errors.Wrap(err, "update cluster")
+		if err := storage.UpdateCluster(tx, cluster, map[string]interface{}{"status": cluster.Status}); err != nil {
+			return err
 		}
 		if err := tx.Create(task).Error; err != nil {
 			return errors.Wrap(err, "create task")
@@ -137,13 +283,292 @@ func (ctrl *DeleteClusterCtrl) Serve(g *gin.Context) {
 		return nil
 	}); err != nil {
 		logger.Errorf("transaction: %s", err)
-		ctrl.Response.Update(model.CodeInternalError, "commit request")
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
 		return
 	}
+	g.Header("ETag", utils.FormatETag(cluster.ResourceVersion))
 	logger.WithFields(log.Fields{
 		"clusterID": task.ResourceID,
 		"taskID":    task.ID,
 	}).Info("delete cluster task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, task.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+}
+
+type UpgradeClusterCtrl struct {
+	model.BaseController[model.UpgradeClusterReq, model.BaseResponse]
+}
+
+// @Summary     升级集群
+// @Description 根据目标版本滚动升级集群，先升级控制面节点，再升级工作节点
+// @Tags        Cluster
+// @Accept      json,application/x-yaml,application/x-protobuf
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId         path     string                  true  "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId         path     string                  true  "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       UpgradeClusterReq body     model.UpgradeClusterReq true  "请求"
+// @Param       If-Match          header   string                  false "集群详情返回的 ETag，不匹配时返回 Conflict"
+// @Response    200               {object} model.BaseResponse      "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/upgrade [post]
+func (ctrl *UpgradeClusterCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	// 获取资源ID
+	clusterID := g.Param("clusterId")
+	// 获取集群
+	cluster, err := storage.GetProjectClusterByResourceID(g.Param("projectId"), clusterID)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("get cluster: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "cluster not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get cluster")
+		}
+		return
+	}
+	if !checkIfMatch(g, logger, &ctrl.Response, cluster) {
+		return
+	}
+	// 检查状态，运行中的集群才可以升级
+	if cluster.Status != storage.ClusterStatusRunning {
+		logger.WithField("clusterID", clusterID).Errorf("cluster status %s", cluster.Status)
+		ctrl.Response.Update(model.CodeForbidOperate, "cluster not running")
+		return
+	}
+	// 检查版本，只保存规范化的版本号
+	if err := utils.CheckUpgradeVersion(cluster.Version, ctrl.Request.Version); err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("check upgrade version: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	target, _ := utils.ParseVersion(ctrl.Request.Version)
+	version := target.String()
+	// 超过60分钟未升级判定为异常
+	task := &storage.Task{
+		ResourceID: cluster.ResourceID,
+		ProjectID:  cluster.ProjectID,
+		RequestID:  ctrl.Request.RequestID,
+		Status:     storage.TaskStatusEnqueued,
+		RetryType:  storage.TaskRetryTypeFixed,
+		RetryDelay: 10,
+		RetryCount: 0,
+		RetryLimit: 20,
+		Action:     storage.ClusterActionUpgrade,
+	}
+	if err := task.SetConfig(&storage.ClusterTaskConfig{Version: version}); err != nil {
+		logger.Errorf("set task config: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "set task config")
+		return
+	}
+	// 在事务中更改集群状态、提交任务
+	cluster.Status = storage.ClusterStatusUpgrading
+	if err := storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := storage.UpdateCluster(tx, cluster, map[string]interface{}{"status": cluster.Status}); err != nil {
+			return err
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return errors.Wrap(err, "create task")
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf("transaction: %s", err)
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	g.Header("ETag", utils.FormatETag(cluster.ResourceVersion))
+	logger.WithFields(log.Fields{
+		"clusterID": task.ResourceID,
+		"taskID":    task.ID,
+		"version":   version,
+	}).Info("upgrade cluster task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, task.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+}
+
+type PatchClusterCtrl struct {
+	model.BaseController[model.PatchClusterReq, model.GetClusterResp]
+}
+
+// @Summary     修改集群
+// @Description 使用 JSON Merge Patch 修改集群的名称、描述、标签与注解，其他字段不允许修改
+// @Tags        Cluster
+// @Accept      json,application/merge-patch+json
+// @Param       projectId       path     string               true  "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId       path     string               true  "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       PatchClusterReq body     model.ClusterPatch   true  "请求"
+// @Param       If-Match        header   string               false "集群详情返回的 ETag，不匹配时返回 Conflict"
+// @Response    200             {object} model.GetClusterResp "响应"
+// @Header      200             {string} ETag                 "集群的资源版本"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId} [patch]
+func (ctrl *PatchClusterCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	// 获取资源ID
+	clusterID := g.Param("clusterId")
+	// 检查参数
+	if len(ctrl.Request.Fields) == 0 {
+		ctrl.Response.Update(model.CodeParamError, "empty patch")
+		return
+	}
+	for field := range ctrl.Request.Fields {
+		if err := checkPatchField(field); err != nil {
+			logger.WithField("clusterID", clusterID).Errorf("check patch: %s", err)
+			ctrl.Response.Update(model.CodeParamError, err.Error())
+			return
+		}
+	}
+	// 获取集群
+	cluster, err := storage.GetProjectClusterByResourceID(g.Param("projectId"), clusterID)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("get cluster: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "cluster not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get cluster")
+		}
+		return
+	}
+	if !checkIfMatch(g, logger, &ctrl.Response.BaseResponse, cluster) {
+		return
+	}
+	labels, err := storage.GetClusterLabels(cluster.ResourceID)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("get cluster labels: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get cluster labels")
+		return
+	}
+	// 合并修改的字段
+	fields, labelsPatched, err := applyClusterPatch(cluster, labels, ctrl.Request.Fields)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("apply patch: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 在事务中更新集群与标签，标签修改同样增加资源版本
+	if err := storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := storage.UpdateCluster(tx, cluster, fields); err != nil {
+			return err
+		}
+		if labelsPatched {
+			return storage.ReplaceClusterLabels(tx, cluster.ResourceID, labels)
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf("transaction: %s", err)
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithField("clusterID", clusterID).Info("cluster patched")
+	if err := storage.PublishClusterEvent(g, clusterID); err != nil {
+		logger.Warnf("publish cluster event: %s", err)
+	}
+	g.Header("ETag", utils.FormatETag(cluster.ResourceVersion))
+	ctrl.Response.Data = toClusterDetail(cluster, labels)
+}
+
+// checkPatchField 检查字段是否允许修改，字段名不区分大小写
+func checkPatchField(field string) error {
+	switch strings.ToLower(field) {
+	case "name", "description", "labels", "annotations":
+		return nil
+	case "type", "k8stype", "runtime", "version", "resourceid", "projectid", "status", "resourceversion", "createtime", "hosts":
+		return errors.Errorf("field %s is immutable", field)
+	default:
+		return errors.Errorf("unknown field %s", field)
+	}
+}
+
+// applyClusterPatch 将修改合并到集群与标签，返回需要更新的集群字段与标签是否被修改
+func applyClusterPatch(cluster *storage.Cluster, labels map[string]string, patch map[string]json.RawMessage) (map[string]interface{}, bool, error) {
+	fields := map[string]interface{}{}
+	labelsPatched := false
+	for field, raw := range patch {
+		switch strings.ToLower(field) {
+		case "name":
+			var name *string
+			if err := json.Unmarshal(raw, &name); err != nil {
+				return nil, false, errors.Wrap(err, "invalid name")
+			}
+			if name == nil {
+				return nil, false, errors.New("invalid name: null")
+			}
+			if err := utils.CheckName(*name); err != nil {
+				return nil, false, errors.Wrap(err, "invalid name")
+			}
+			cluster.Name = *name
+			fields["name"] = cluster.Name
+		case "description":
+			// null 表示清空描述
+			var description string
+			if err := json.Unmarshal(raw, &description); err != nil {
+				return nil, false, errors.Wrap(err, "invalid description")
+			}
+			if err := checkDescription(description); err != nil {
+				return nil, false, err
+			}
+			cluster.Description = description
+			fields["description"] = cluster.Description
+		case "labels":
+			if err := mergeStringMap(labels, raw); err != nil {
+				return nil, false, errors.Wrap(err, "invalid labels")
+			}
+			if err := utils.CheckLabels(labels); err != nil {
+				return nil, false, err
+			}
+			labelsPatched = true
+		case "annotations":
+			annotations := cluster.Annotations.Data
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			if err := mergeStringMap(annotations, raw); err != nil {
+				return nil, false, errors.Wrap(err, "invalid annotations")
+			}
+			if err := utils.CheckAnnotations(annotations); err != nil {
+				return nil, false, err
+			}
+			cluster.Annotations = datatypes.JSONType[map[string]string]{Data: annotations}
+			fields["annotations"] = cluster.Annotations
+		}
+	}
+	return fields, labelsPatched, nil
+}
+
+// mergeStringMap 按照 JSON Merge Patch 合并字典，null 表示删除全部键，值为 null 表示删除该键
+func mergeStringMap(dst map[string]string, raw json.RawMessage) error {
+	var items map[string]*string
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+	if items == nil {
+		for k := range dst {
+			delete(dst, k)
+		}
+	}
+	for k, v := range items {
+		if v == nil {
+			delete(dst, k)
+		} else {
+			dst[k] = *v
+		}
+	}
+	return nil
 }
 
 type GetClusterCtrl struct {
@@ -153,15 +578,19 @@ type GetClusterCtrl struct {
 // @Summary     集群详情
 // @Description 根据集群ID获取集群详细信息
 // @Tags        Cluster
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string               true "项目资源ID" extensions(x-example=project-default)
 // @Param       clusterId path     string               true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
 // @Response    200       {object} model.GetClusterResp "响应"
-// @Router      /clusters/{clusterId} [get]
+// @Header      200       {string} ETag                 "集群的资源版本"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId} [get]
 func (ctrl *GetClusterCtrl) Serve(g *gin.Context) {
 	logger := log.GetLogger(g)
 	// 获取资源ID
 	resourceID := g.Param("clusterId")
 	// 获取集群
-	cluster, err := storage.GetClusterByResourceID(resourceID)
+	cluster, err := storage.GetProjectClusterByResourceID(g.Param("projectId"), resourceID)
 	if err != nil {
 		logger.WithField("clusterID", resourceID).Errorf("get cluster: %s", err)
 		if err == storage.ErrDoesNotExist {
@@ -171,15 +600,30 @@ func (ctrl *GetClusterCtrl) Serve(g *gin.Context) {
 		}
 		return
 	}
-	ctrl.Response.Data = &model.ClusterDetail{
-		Name:        cluster.Name,
-		Description: cluster.Description,
-		ResourceID:  cluster.ResourceID,
-		Type:        cluster.Type,
-		Version:     cluster.Version,
-		Runtime:     cluster.Runtime,
-		Status:      cluster.Status.String(),
-		CreateTime:  utils.FormatTime(cluster.CreatedAt),
+	labels, err := storage.GetClusterLabels(cluster.ResourceID)
+	if err != nil {
+		logger.WithField("clusterID", resourceID).Errorf("get cluster labels: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get cluster labels")
+		return
+	}
+	g.Header("ETag", utils.FormatETag(cluster.ResourceVersion))
+	ctrl.Response.Data = toClusterDetail(cluster, labels)
+}
+
+func toClusterDetail(cluster *storage.Cluster, labels map[string]string) *model.ClusterDetail {
+	return &model.ClusterDetail{
+		Name:            cluster.Name,
+		Description:     cluster.Description,
+		ResourceID:      cluster.ResourceID,
+		ProjectID:       cluster.ProjectID,
+		Type:            cluster.Type,
+		Version:         cluster.Version,
+		Runtime:         cluster.Runtime,
+		Status:          cluster.Status.String(),
+		ResourceVersion: cluster.ResourceVersion,
+		Labels:          labels,
+		Annotations:     cluster.Annotations.Data,
+		CreateTime:      utils.FormatTime(cluster.CreatedAt),
 	}
 }
 
@@ -188,134 +632,148 @@ type ListClusterCtrl struct {
 }
 
 // @Summary     集群列表
-// @Description 分页获取集群列表
+// @Description 分页获取集群列表，支持多个过滤条件与排序
+// @Description 过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔
+// @Description 过滤字段：name、resourceID、type、version、runtime、status、createTime、updateTime，时间使用 unix 时间戳（秒），状态可以使用名称
+// @Description 排序字段：name、version、status、createTime、updateTime
 // @Tags        Cluster
-// @Param       pageNo      query    int                   true  "分页号，默认为1"   extensions(x-example=1)
-// @Param       pageSize    query    int                   true  "分页大小，默认为10" extensions(x-example=10)
-// @Param       filterKey   query    string                false "查询条件，默认为空"
-// @Param       filterValue query    string                false "查询值，默认为空"
-// @Response    200         {object} model.ListClusterResp "响应"
-// @Router      /clusters [get]
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId     path     string                true  "项目资源ID"                             extensions(x-example=project-default)
+// @Param       pageNo        query    int                   false "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数" extensions(x-example=1)
+// @Param       pageSize      query    int                   true  "分页大小，默认为10"                         extensions(x-example=10)
+// @Param       pageToken     query    string                false "分页令牌，使用上一页返回的 nextPageToken 获取下一页"
+// @Param       withTotal     query    bool                  false "是否返回总数，使用页码分页时总是返回"
+// @Param       filter        query    []string              false "过滤条件，格式为 field:op:value" collectionFormat(multi) extensions(x-example=status:in:Running,Error)
+// @Param       sortBy        query    string                false "排序字段"                    extensions(x-example=createTime)
+// @Param       sortOrder     query    string                false "排序方向，默认为 desc"           Enums(asc, desc)
+// @Param       filterKey     query    string                false "查询条件，已废弃，使用 filter 代替"
+// @Param       filterValue   query    string                false "查询值，已废弃，使用 filter 代替"
+// @Param       labelSelector query    string                false "标签选择器，语法与 k8s 相同，默认为空" extensions(x-example=env=prod,team in (a,b))
+// @Response    200           {object} model.ListClusterResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters [get]
 func (ctrl *ListClusterCtrl) Serve(g *gin.Context) {
 	logger := log.GetLogger(g)
 	req := ctrl.Request
+	query, err := storage.ClusterListSchema.ParseListQuery(req.Filter, req.SortBy, req.SortOrder)
+	if err != nil {
+		logger.Errorf("parse list query: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 分页令牌只能用于过滤与排序条件相同的查询
+	query.Scope = utils.QueryScope(g.Request.URL, model.PageParams...)
+	if err := query.SetPage(req.PageNo, req.PageSize, req.PageToken); err != nil {
+		logger.Errorf("set page: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
 	filter, err := buildClusterFilter(req.FilterKey, req.FilterValue)
 	if err != nil {
 		logger.Errorf("build cluster filter: %s", err)
 		ctrl.Response.Update(model.CodeParamError, "invalid filterKey or filterValue")
 		return
 	}
-
-	items, err := storage.ListCluster((req.PageNo-1)*req.PageSize, req.PageSize, filter)
+	query.Where(filter)
+	filters, err := buildLabelSelectorFilters(req.LabelSelector)
+	if err != nil {
+		logger.Errorf("build label selector filters: %s", err)
+		ctrl.Response.Update(model.CodeParamError, "invalid labelSelector: "+err.Error())
+		return
+	}
+	query.Where(filters...)
+	project, ok := getProject(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	items, nextPageToken, err := storage.ListCluster(project.ResourceID, query)
 	if err != nil {
 		logger.Errorf("list cluster: %s", err)
 		ctrl.Response.Update(model.CodeInternalError, "list cluster")
 		return
 	}
-	count, err := storage.CountCluster(filter)
+	// 使用页码分页时总是计算总数，兼容之前的调用方式
+	if req.PageNo > 0 || req.WithTotal {
+		count, err := storage.CountCluster(project.ResourceID, query)
+		if err != nil {
+			logger.Errorf("count cluster: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "count cluster")
+			return
+		}
+		ctrl.Response.TotalCount = &count
+	}
+	clusterIDs := make([]string, 0, len(items))
+	for _, cluster := range items {
+		clusterIDs = append(clusterIDs, cluster.ResourceID)
+	}
+	clusterLabels, err := storage.ListClusterLabels(clusterIDs)
 	if err != nil {
-		logger.Errorf("count cluster: %s", err)
-		ctrl.Response.Update(model.CodeInternalError, "count cluster")
+		logger.Errorf("list cluster labels: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list cluster labels")
 		return
 	}
 	ctrl.Response.Data = make([]model.ClusterSummary, 0)
 	for _, cluster := range items {
 		ctrl.Response.Data = append(ctrl.Response.Data, model.ClusterSummary{
-			Name:        cluster.Name,
-			Description: cluster.Description,
-			ResourceID:  cluster.ResourceID,
-			Type:        cluster.Type,
-			Version:     cluster.Version,
-			Runtime:     cluster.Runtime,
-			Status:      cluster.Status.String(),
-			CreateTime:  utils.FormatTime(cluster.CreatedAt),
+			Name:            cluster.Name,
+			Description:     cluster.Description,
+			ResourceID:      cluster.ResourceID,
+			Type:            cluster.Type,
+			Version:         cluster.Version,
+			Runtime:         cluster.Runtime,
+			Status:          cluster.Status.String(),
+			ResourceVersion: cluster.ResourceVersion,
+			Labels:          clusterLabels[cluster.ResourceID],
+			CreateTime:      utils.FormatTime(cluster.CreatedAt),
 		})
 	}
-	ctrl.Response.TotalCount = int(count)
+	ctrl.Response.NextPageToken = nextPageToken
 }
 
+// buildLabelSelectorFilters 解析 k8s 格式的标签选择器，每个条件转换为一个过滤条件
+func buildLabelSelectorFilters(labelSelector string) ([]*storage.Filter, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	requirements, _ := selector.Requirements()
+	filters := make([]*storage.Filter, 0, len(requirements))
+	for _, r := range requirements {
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			filters = append(filters, storage.ClusterLabelFilter(r.Key(), r.Values().List(), false))
+		case selection.NotEquals, selection.NotIn:
+			filters = append(filters, storage.ClusterLabelFilter(r.Key(), r.Values().List(), true))
+		case selection.Exists:
+			filters = append(filters, storage.ClusterLabelFilter(r.Key(), nil, false))
+		case selection.DoesNotExist:
+			filters = append(filters, storage.ClusterLabelFilter(r.Key(), nil, true))
+		default:
+			return nil, errors.Errorf("unsupported operator %s", r.Operator())
+		}
+	}
+	return filters, nil
+}
+
+// buildClusterFilter 将废弃的 filterKey 与 filterValue 转换为过滤条件
 func buildClusterFilter(filterKey string, filterValue string) (*storage.Filter, error) {
 	if len(filterKey) == 0 {
 		return nil, nil
 	}
-	if len(filterKey) > 0 && len(filterValue) == 0 {
-		return nil, errors.New("empty filterValue")
-	}
-	var filter *storage.Filter
 	switch filterKey {
 	case "name":
 		// 模糊查询
-		filter = &storage.Filter{
-			Query: "name LIKE ?",
-			Args:  []interface{}{"%" + filterValue + "%"},
-		}
+		return storage.ClusterListSchema.NewFilter(filterKey, storage.FilterOpLike, filterValue)
 	case "resourceID":
 		// 文本匹配
-		filter = &storage.Filter{
-			Query: "resource_id = ?",
-			Args:  []interface{}{filterValue},
-		}
-	case "type":
-		// 多选
-		items := strings.Split(filterValue, ",")
-		if len(items) == 0 {
-			return nil, errors.New("invalid type")
-		}
-		filter = &storage.Filter{
-			Query: "type IN ?",
-			Args:  []interface{}{},
-		}
-		filter.Args = append(filter.Args, items)
-	case "version":
-		// 多选
-		items := strings.Split(filterValue, ",")
-		if len(items) == 0 {
-			return nil, errors.New("invalid version")
-		}
-		filter = &storage.Filter{
-			Query: "version IN ?",
-			Args:  []interface{}{},
-		}
-		filter.Args = append(filter.Args, items)
-	case "status":
+		return storage.ClusterListSchema.NewFilter(filterKey, storage.FilterOpEq, filterValue)
+	case "type", "version", "status":
 		// 多选
-		items := strings.Split(filterValue, ",")
-		if len(items) == 0 {
-			return nil, errors.Errorf("invalid status: %s", filterValue)
-		}
-		for i := range items {
-			if _, err := strconv.Atoi(items[i]); err != nil {
-				return nil, errors.Errorf("invalid status: %s", filterValue)
-			}
-		}
-		filter = &storage.Filter{
-			Query: "status IN ?",
-			Args:  []interface{}{},
-		}
-		filter.Args = append(filter.Args, items)
+		return storage.ClusterListSchema.NewFilter(filterKey, storage.FilterOpIn, filterValue)
 	case "createTime":
-		// 多选
-		items := strings.Split(filterValue, ",")
-		if len(items) != 2 {
-			return nil, errors.New("invalid createTime")
-		}
-		start, err := strconv.Atoi(items[0])
-		if err != nil {
-			return nil, errors.New("invalid createTime: startTime")
-		}
-		end, err := strconv.Atoi(items[1])
-		if err != nil {
-			return nil, errors.New("invalid createTime: endTime")
-		}
-		filter = &storage.Filter{
-			Query: "created_at BETWEEN ? AND ?",
-			Args: []interface{}{
-				time.Unix(int64(start), 0),
-				time.Unix(int64(end), 0),
-			},
-		}
+		// 时间范围
+		return storage.ClusterListSchema.NewFilter(filterKey, storage.FilterOpBetween, filterValue)
 	default:
 		return nil, errors.New("unsupported filterKey")
 	}
-	return filter, nil
 }
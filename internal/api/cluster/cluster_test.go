@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+func TestBuildLabelSelectorFilters(t *testing.T) {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	// cluster-d 没有标签，cluster-c 没有 team 标签
+	clusters := map[string]map[string]string{
+		"cluster-a": {"env": "prod", "team": "a"},
+		"cluster-b": {"env": "dev", "team": "b"},
+		"cluster-c": {"env": "prod"},
+		"cluster-d": nil,
+	}
+	for resourceID, labels := range clusters {
+		if err := storage.DB().Create(&storage.Cluster{ResourceID: resourceID, Name: resourceID, ProjectID: storage.DefaultProjectID}).Error; err != nil {
+			t.Fatalf("create cluster: %s", err)
+		}
+		for key, value := range labels {
+			if err := storage.DB().Create(&storage.ClusterLabel{ClusterID: resourceID, Key: key, Value: value}).Error; err != nil {
+				t.Fatalf("create cluster label: %s", err)
+			}
+		}
+	}
+	tests := []struct {
+		selector string
+		expected []string
+		err      string
+	}{
+		{selector: "", expected: []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"}},
+		{selector: "env=prod", expected: []string{"cluster-a", "cluster-c"}},
+		{selector: "env==prod", expected: []string{"cluster-a", "cluster-c"}},
+		{selector: "env!=prod", expected: []string{"cluster-b", "cluster-d"}},
+		{selector: "team in (a,b)", expected: []string{"cluster-a", "cluster-b"}},
+		{selector: "team notin (a)", expected: []string{"cluster-b", "cluster-c", "cluster-d"}},
+		{selector: "team", expected: []string{"cluster-a", "cluster-b"}},
+		{selector: "!team", expected: []string{"cluster-c", "cluster-d"}},
+		{selector: "env=prod,!team", expected: []string{"cluster-c"}},
+		{selector: "env in (prod,dev),team!=b", expected: []string{"cluster-a", "cluster-c"}},
+		{selector: "env=unknown", expected: []string{}},
+		{selector: "team>1", err: "unsupported operator"},
+		{selector: "env=(", err: "unable to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			filters, err := buildLabelSelectorFilters(tt.selector)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("build label selector filters: %s", err)
+			}
+			query, err := storage.ClusterListSchema.ParseListQuery(nil, "", "")
+			if err != nil {
+				t.Fatalf("parse list query: %s", err)
+			}
+			if err := query.SetPage(0, 100, ""); err != nil {
+				t.Fatalf("set page: %s", err)
+			}
+			query.Where(filters...)
+			items, _, err := storage.ListCluster(storage.DefaultProjectID, query)
+			if err != nil {
+				t.Fatalf("list cluster: %s", err)
+			}
+			resourceIDs := []string{}
+			for _, item := range items {
+				resourceIDs = append(resourceIDs, item.ResourceID)
+			}
+			sort.Strings(resourceIDs)
+			if !reflect.DeepEqual(resourceIDs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, resourceIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	clusterEventsResync    = 30 * time.Second // 没有收到集群事件时，兜底检查集群、任务与任务日志变化的间隔
+	clusterEventsHeartbeat = 15 * time.Second // 没有事件时的心跳间隔，避免代理关闭空闲连接
+	clusterEventsBatchSize = 100              // 单次读取的任务日志数量
+)
+
+// SSE 事件名称
+const (
+	clusterEventCluster = "cluster" // 集群状态，数据为 model.ClusterStatusEvent
+	clusterEventTask    = "task"    // 最新任务的状态，数据为 model.TaskSummary
+	clusterEventLog     = "log"     // 新的任务日志，数据为 model.TaskLogEvent
+)
+
+type ClusterEventsCtrl struct {
+	model.BaseController[model.ClusterEventsReq, model.ClusterEventsResp]
+}
+
+func (ctrl *ClusterEventsCtrl) Codec() model.Codec {
+	return model.CodecSSE
+}
+
+// @Summary     集群事件流
+// @Description 以 Server-Sent Events 推送集群状态、最新任务状态与新的任务日志，连接后先推送集群与最新任务的当前状态。事件ID为已推送的最后一条任务日志ID，重连时通过 Last-Event-ID 头部携带，只推送之后的任务日志。集群删除后推送 Deleted 状态并结束
+// @Tags        Cluster
+// @Produce     text/event-stream
+// @Param       projectId     path     string                  true  "项目资源ID"        extensions(x-example=project-default)
+// @Param       clusterId     path     string                  true  "集群资源ID"        extensions(x-example=cluster-sedqqz7ka)
+// @Param       Last-Event-ID header   string                  false "上次收到的最后一个事件ID" extensions(x-example=10)
+// @Response    200           {object} model.ClusterEventsResp "连接失败时的响应，成功时为事件流"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/events [get]
+func (ctrl *ClusterEventsCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	projectID := g.Param("projectId")
+	clusterID := g.Param("clusterId")
+	var lastLogID uint64
+	if v := g.GetHeader("Last-Event-ID"); len(v) > 0 {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			ctrl.Response.Update(model.CodeParamError, "invalid Last-Event-ID")
+			return
+		}
+		lastLogID = id
+	}
+	cluster, err := storage.GetProjectClusterByResourceID(projectID, clusterID)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("get cluster: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "cluster not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get cluster")
+		}
+		return
+	}
+	g.Header("Cache-Control", "no-cache")
+	g.Header("Connection", "keep-alive")
+	// 禁止 nginx 缓冲响应
+	g.Header("X-Accel-Buffering", "no")
+	g.Header("Content-Type", "text/event-stream")
+	g.Status(http.StatusOK)
+	g.Writer.WriteHeaderNow()
+	// 在读取当前状态前订阅，避免遗漏之间的变化
+	events, unsubscribe := storage.SubscribeClusterEvent(clusterID)
+	defer unsubscribe()
+	stream := &clusterEventStream{g: g, projectID: projectID, clusterID: clusterID, lastLogID: lastLogID}
+	// 先推送当前状态，再推送之后的任务日志
+	if err := stream.sendCluster(cluster); err != nil {
+		logger.Errorf("send cluster event: %s", err)
+		return
+	}
+	resync := time.NewTicker(clusterEventsResync)
+	defer resync.Stop()
+	heartbeat := time.NewTicker(clusterEventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		done, err := stream.poll()
+		if err != nil {
+			logger.Errorf("poll cluster events: %s", err)
+			return
+		}
+		if done {
+			return
+		}
+		if !stream.wait(events, resync.C, heartbeat.C) {
+			return
+		}
+	}
+}
+
+// clusterEventStream 记录已推送的状态，只推送发生变化的状态
+type clusterEventStream struct {
+	g         *gin.Context
+	projectID string
+	clusterID string
+	lastLogID uint64           // 已推送的最后一条任务日志ID
+	lastSent  time.Time        // 最后一次推送的时间
+	cluster   *storage.Cluster // 已推送的集群状态
+	task      *storage.Task    // 已推送的最新任务状态
+}
+
+// poll 推送新的任务日志与变化的任务、集群状态，集群已删除时推送剩余的任务日志与删除状态后返回 true
+func (s *clusterEventStream) poll() (bool, error) {
+	cluster, err := storage.GetProjectClusterByResourceID(s.projectID, s.clusterID)
+	if err != nil && err != storage.ErrDoesNotExist {
+		return false, errors.Wrap(err, "get cluster")
+	}
+	for {
+		items, err := storage.ListClusterTaskLogAfter(s.clusterID, s.lastLogID, clusterEventsBatchSize)
+		if err != nil {
+			return false, errors.Wrap(err, "list task log")
+		}
+		for i := range items {
+			s.lastLogID = items[i].ID
+			if err := s.send(clusterEventLog, toTaskLogEvent(&items[i])); err != nil {
+				return false, err
+			}
+		}
+		if len(items) < clusterEventsBatchSize {
+			break
+		}
+	}
+	if cluster == nil {
+		// 集群删除后软删除，推送删除状态后结束
+		deleted := *s.cluster
+		deleted.Status = storage.ClusterStatusDeleted
+		deleted.UpdatedAt = time.Now()
+		return true, s.sendCluster(&deleted)
+	}
+	task, err := storage.GetLatestTaskByResourceID(s.clusterID)
+	if err != nil && err != storage.ErrDoesNotExist {
+		return false, errors.Wrap(err, "get latest task")
+	}
+	if task != nil && (s.task == nil || s.task.ID != task.ID || s.task.Status != task.Status || s.task.RetryCount != task.RetryCount) {
+		s.task = task
+		if err := s.send(clusterEventTask, toTaskSummary(task)); err != nil {
+			return false, err
+		}
+	}
+	if cluster.ResourceVersion != s.cluster.ResourceVersion || cluster.Status != s.cluster.Status {
+		return false, s.sendCluster(cluster)
+	}
+	return false, nil
+}
+
+// wait 等待集群事件或兜底检查，期间长时间没有推送时发送注释作为心跳，连接断开时返回 false
+func (s *clusterEventStream) wait(events <-chan struct{}, resync <-chan time.Time, heartbeat <-chan time.Time) bool {
+	for {
+		select {
+		case <-s.g.Request.Context().Done():
+			return false
+		case <-events:
+			return true
+		case <-resync:
+			return true
+		case <-heartbeat:
+			if time.Since(s.lastSent) >= clusterEventsHeartbeat {
+				if err := s.ping(); err != nil {
+					return false
+				}
+			}
+		}
+	}
+}
+
+func (s *clusterEventStream) sendCluster(cluster *storage.Cluster) error {
+	s.cluster = cluster
+	return s.send(clusterEventCluster, &model.ClusterStatusEvent{
+		ResourceID:      cluster.ResourceID,
+		Status:          cluster.Status.String(),
+		ResourceVersion: cluster.ResourceVersion,
+		UpdateTime:      utils.FormatTime(cluster.UpdatedAt),
+	})
+}
+
+// send 推送事件并立即刷新，事件ID为已推送的最后一条任务日志ID
+func (s *clusterEventStream) send(event string, data interface{}) error {
+	if err := sse.Encode(s.g.Writer, sse.Event{
+		Id:    strconv.FormatUint(s.lastLogID, 10),
+		Event: event,
+		Data:  data,
+	}); err != nil {
+		return errors.Wrapf(err, "send %s event", event)
+	}
+	s.g.Writer.Flush()
+	s.lastSent = time.Now()
+	return nil
+}
+
+func (s *clusterEventStream) ping() error {
+	if _, err := fmt.Fprint(s.g.Writer, ": ping\n\n"); err != nil {
+		return errors.Wrap(err, "send ping")
+	}
+	s.g.Writer.Flush()
+	s.lastSent = time.Now()
+	return nil
+}
+
+func toTaskSummary(task *storage.Task) *model.TaskSummary {
+	item := &model.TaskSummary{
+		ID:         task.ID,
+		ClusterID:  task.ResourceID,
+		Action:     task.Action,
+		Status:     task.Status.String(),
+		RetryCount: task.RetryCount,
+		RetryLimit: task.RetryLimit,
+		CreateTime: utils.FormatTime(task.CreatedAt),
+		UpdateTime: utils.FormatTime(task.UpdatedAt),
+		RequestID:  task.RequestID,
+	}
+	if task.RetryAt.Valid {
+		item.RetryAt = utils.FormatTime(task.RetryAt.Time)
+	}
+	return item
+}
+
+func toTaskLogEvent(item *storage.TaskLog) *model.TaskLogEvent {
+	return &model.TaskLogEvent{
+		TaskID: item.TaskID,
+		TaskLogItem: model.TaskLogItem{
+			ID:        item.ID,
+			Reason:    item.Reason,
+			Step:      item.Step,
+			Message:   item.Message,
+			StartTime: utils.FormatTime(item.StartAt),
+			EndTime:   utils.FormatTime(item.EndAt),
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// 审计事件保存的请求体长度上限，超过时不保存请求体
+const auditMaxBodySize = 64 * 1024
+
+// 路径中表示资源ID的参数，按优先级排列，项目ID只在没有其他资源ID时使用
+var auditResourceParams = []string{"nodeId", "taskId", "webhookId", "bindingId", "roleName", "clusterId"}
+
+// AuditFileSink 以 JSON lines 格式将审计事件追加到文件
+type AuditFileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewAuditFileSink 打开审计文件，文件不存在时创建
+func NewAuditFileSink(path string) (*AuditFileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "open audit file")
+	}
+	return &AuditFileSink{file: file}, nil
+}
+
+// Write 将审计事件序列化为一行 JSON 写入文件
+func (s *AuditFileSink) Write(item *model.AuditEventItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return errors.Wrap(err, "marshal audit event")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close 关闭审计文件
+func (s *AuditFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Audit 记录修改类请求的审计事件，saveDB 为 true 时保存到数据库，sink 不为空时同时写入文件，保存失败只记录日志，不影响请求
+func Audit(saveDB bool, sink *AuditFileSink) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		start := time.Now()
+		logger := log.G(g)
+		// 读取请求体用于审计，之后恢复请求体供控制器读取，非 JSON 请求体不保存
+		var requestBody []byte
+		if g.Request.Body != nil {
+			body, err := io.ReadAll(g.Request.Body)
+			if err != nil {
+				logger.Errorf("read request body: %s", err)
+				abortWithCode(g, http.StatusBadRequest, model.CodeParamError, "read request body")
+				return
+			}
+			g.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if len(body) > 0 && len(body) <= auditMaxBodySize {
+				requestBody, _ = utils.RedactJSON(body)
+			}
+		}
+		// 记录响应体，创建类请求的资源ID在响应中
+		writer := &bodyWriter{ResponseWriter: g.Writer}
+		g.Writer = writer
+		g.Next()
+		item := &storage.AuditEvent{
+			RequestID:    g.GetString(GinCtxRequestID),
+			Actor:        GetIdentity(g),
+			Method:       g.Request.Method,
+			Route:        g.FullPath(),
+			Path:         g.Request.URL.Path,
+			ProjectID:    g.Param("projectId"),
+			ResourceID:   auditResourceID(g, writer.body.Bytes()),
+			RequestBody:  requestBody,
+			StatusCode:   g.Writer.Status(),
+			ResponseCode: g.GetString(GinCtxResponseCode),
+			Latency:      time.Since(start).Milliseconds(),
+			ClientIP:     g.ClientIP(),
+			CreatedAt:    time.Now(),
+		}
+		if saveDB {
+			if err := storage.CreateAuditEvent(item); err != nil {
+				logger.Errorf("create audit event: %s", err)
+			}
+		}
+		if sink != nil {
+			event := model.NewAuditEventItem(item)
+			if err := sink.Write(&event); err != nil {
+				logger.Errorf("write audit event: %s", err)
+			}
+		}
+	}
+}
+
+// auditResourceID 获取请求操作的资源ID，优先使用路径中的资源ID，其次使用响应中字符串类型的 data，最后使用项目ID
+func auditResourceID(g *gin.Context, response []byte) string {
+	for _, param := range auditResourceParams {
+		if id := g.Param(param); len(id) > 0 {
+			return id
+		}
+	}
+	resp := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	var id string
+	if err := json.Unmarshal(response, &resp); err == nil && json.Unmarshal(resp.Data, &id) == nil && len(id) > 0 {
+		return id
+	}
+	return g.Param("projectId")
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	// 调用者身份
+	GinCtxIdentity = "X-Identity"
+	// 未启用认证时的调用者身份
+	AnonymousIdentity = "anonymous"
+)
+
+// Auth 认证请求，支持数据库中的 API 令牌与 HS256/RS256 签名的 JWT，WebSocket 升级请求可以通过 access_token 参数携带令牌，认证通过后将调用者身份写入请求上下文与日志字段
+func Auth(cfg *config.Config) (gin.HandlerFunc, error) {
+	if !cfg.Auth.Enable {
+		return func(g *gin.Context) {
+			g.Set(GinCtxIdentity, AnonymousIdentity)
+		}, nil
+	}
+	parser, err := newJWTParser(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "new jwt parser")
+	}
+	return func(g *gin.Context) {
+		token, ok := strings.CutPrefix(g.GetHeader("Authorization"), "Bearer ")
+		// 浏览器的 WebSocket 无法设置头部，升级请求可以通过 access_token 参数携带令牌
+		if !ok && IsWebSocketUpgrade(g.Request) {
+			token, ok = g.Query("access_token"), true
+		}
+		if !ok || len(token) == 0 {
+			abortUnauthorized(g, "missing bearer token")
+			return
+		}
+		var identity string
+		var err error
+		if strings.HasPrefix(token, storage.APITokenPrefix) {
+			identity, err = authAPIToken(token)
+		} else {
+			identity, err = parser.parse(token)
+		}
+		if err != nil {
+			log.G(g).Warnf("authenticate: %s", err)
+			abortUnauthorized(g, "invalid token")
+			return
+		}
+		g.Set(GinCtxIdentity, identity)
+		g.Request = g.Request.WithContext(log.S(g.Request.Context(), log.G(g).WithField("identity", identity)))
+	}, nil
+}
+
+// IsWebSocketUpgrade 是否为 WebSocket 升级请求
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// GetIdentity 获取调用者身份
+func GetIdentity(g *gin.Context) string {
+	return g.GetString(GinCtxIdentity)
+}
+
+// Authorize 校验调用者绑定的角色是否拥有访问路由需要的权限，路径中包含项目时同时检查限定该项目的角色绑定
+func Authorize(permission storage.Permission) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		identity := GetIdentity(g)
+		allowed, err := storage.SubjectHasPermission(identity, g.Param("projectId"), permission)
+		if err != nil {
+			log.G(g).Errorf("check permission: %s", err)
+			abortWithCode(g, http.StatusInternalServerError, model.CodeInternalError, "check permission")
+			return
+		}
+		if !allowed {
+			log.G(g).Warnf("permission denied: %s", permission)
+			abortWithCode(g, http.StatusForbidden, model.CodeForbidden, "permission required: "+string(permission))
+			return
+		}
+	}
+}
+
+func abortUnauthorized(g *gin.Context, message string) {
+	abortWithCode(g, http.StatusUnauthorized, model.CodeUnauthorized, message)
+}
+
+func abortWithCode(g *gin.Context, status int, code model.Code, message string) {
+	resp := model.BaseResponse{RequestID: g.GetString(GinCtxRequestID)}
+	resp.Update(code, message)
+	g.Set(GinCtxResponseCode, string(resp.Code))
+	g.AbortWithStatusJSON(status, resp)
+}
+
+// authAPIToken 校验 API 令牌，返回令牌所属的调用者身份
+func authAPIToken(token string) (string, error) {
+	item, err := storage.GetAPITokenByToken(token)
+	if err != nil {
+		return "", errors.Wrap(err, "get api token")
+	}
+	if item.Expired() {
+		return "", errors.Errorf("api token %s expired", item.ResourceID)
+	}
+	return item.Subject, nil
+}
+
+// jwtParser 校验 JWT 的签名、签发者与受众，未配置密钥时不接受 JWT
+type jwtParser struct {
+	key    interface{}
+	parser *jwt.Parser
+}
+
+func newJWTParser(cfg *config.Config) (*jwtParser, error) {
+	p := &jwtParser{}
+	switch cfg.Auth.JWTAlgorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if len(cfg.Auth.JWTSecret) > 0 {
+			p.key = []byte(cfg.Auth.JWTSecret)
+		}
+	case jwt.SigningMethodRS256.Alg():
+		if len(cfg.Auth.JWTPublicKey) > 0 {
+			data, err := os.ReadFile(cfg.Auth.JWTPublicKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "read jwt public key")
+			}
+			key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, errors.Wrap(err, "parse jwt public key")
+			}
+			p.key = key
+		}
+	default:
+		return nil, errors.Errorf("unsupported jwt algorithm: %s", cfg.Auth.JWTAlgorithm)
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Auth.JWTAlgorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if len(cfg.Auth.JWTIssuer) > 0 {
+		opts = append(opts, jwt.WithIssuer(cfg.Auth.JWTIssuer))
+	}
+	if len(cfg.Auth.JWTAudience) > 0 {
+		opts = append(opts, jwt.WithAudience(cfg.Auth.JWTAudience))
+	}
+	p.parser = jwt.NewParser(opts...)
+	return p, nil
+}
+
+// parse 校验 JWT，返回 sub 声明作为调用者身份
+func (p *jwtParser) parse(token string) (string, error) {
+	if p.key == nil {
+		return "", errors.New("jwt is not configured")
+	}
+	claims := &jwt.RegisteredClaims{}
+	if _, err := p.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return p.key, nil
+	}); err != nil {
+		return "", errors.Wrap(err, "parse jwt")
+	}
+	if len(claims.Subject) == 0 {
+		return "", errors.New("jwt subject is empty")
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testJWTSecret   = "test-secret"
+	testJWTIssuer   = "test-issuer"
+	testJWTAudience = "test-audience"
+)
+
+func testAuthConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Auth.Enable = true
+	cfg.Auth.JWTAlgorithm = jwt.SigningMethodHS256.Alg()
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Auth.JWTIssuer = testJWTIssuer
+	cfg.Auth.JWTAudience = testJWTAudience
+	return cfg
+}
+
+func signJWT(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign jwt: %s", err)
+	}
+	return token
+}
+
+func TestJWTParserParse(t *testing.T) {
+	parser, err := newJWTParser(testAuthConfig())
+	if err != nil {
+		t.Fatalf("newJWTParser: %s", err)
+	}
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "alice",
+			Issuer:    testJWTIssuer,
+			Audience:  jwt.ClaimStrings{testJWTAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}
+	}
+	secret := []byte(testJWTSecret)
+	tests := []struct {
+		name    string
+		token   func() string
+		subject string
+	}{
+		{"valid", func() string {
+			return signJWT(t, jwt.SigningMethodHS256, secret, valid())
+		}, "alice"},
+		{"wrong secret", func() string {
+			return signJWT(t, jwt.SigningMethodHS256, []byte("other-secret"), valid())
+		}, ""},
+		{"unexpected alg", func() string {
+			return signJWT(t, jwt.SigningMethodHS384, secret, valid())
+		}, ""},
+		{"alg none", func() string {
+			return signJWT(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid())
+		}, ""},
+		{"wrong issuer", func() string {
+			claims := valid()
+			claims.Issuer = "other-issuer"
+			return signJWT(t, jwt.SigningMethodHS256, secret, claims)
+		}, ""},
+		{"wrong audience", func() string {
+			claims := valid()
+			claims.Audience = jwt.ClaimStrings{"other-audience"}
+			return signJWT(t, jwt.SigningMethodHS256, secret, claims)
+		}, ""},
+		{"expired", func() string {
+			claims := valid()
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return signJWT(t, jwt.SigningMethodHS256, secret, claims)
+		}, ""},
+		{"missing exp", func() string {
+			claims := valid()
+			claims.ExpiresAt = nil
+			return signJWT(t, jwt.SigningMethodHS256, secret, claims)
+		}, ""},
+		{"empty subject", func() string {
+			claims := valid()
+			claims.Subject = ""
+			return signJWT(t, jwt.SigningMethodHS256, secret, claims)
+		}, ""},
+		{"malformed", func() string {
+			return "not-a-jwt"
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := parser.parse(tt.token())
+			if len(tt.subject) == 0 {
+				if err == nil {
+					t.Errorf("expected error, got subject %q", subject)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if subject != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, subject)
+			}
+		})
+	}
+	// 未配置密钥时不接受 JWT
+	cfg := testAuthConfig()
+	cfg.Auth.JWTSecret = ""
+	unconfigured, err := newJWTParser(cfg)
+	if err != nil {
+		t.Fatalf("newJWTParser: %s", err)
+	}
+	if _, err := unconfigured.parse(signJWT(t, jwt.SigningMethodHS256, secret, valid())); err == nil {
+		t.Error("expected error without jwt secret")
+	}
+	// 不支持的算法
+	cfg = testAuthConfig()
+	cfg.Auth.JWTAlgorithm = "none"
+	if _, err := newJWTParser(cfg); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
+
+func TestAuthAPIToken(t *testing.T) {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	item, token, err := storage.CreateAPIToken("valid", "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	// 数据库中只保存令牌摘要
+	if item.TokenHash == token || item.TokenHash != storage.HashAPIToken(token) {
+		t.Errorf("unexpected token hash: %s", item.TokenHash)
+	}
+	_, forever, err := storage.CreateAPIToken("forever", "bob", 0)
+	if err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	expiredItem, expired, err := storage.CreateAPIToken("expired", "carol", time.Hour)
+	if err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	if err := storage.DB().Model(expiredItem).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire api token: %s", err)
+	}
+	tests := []struct {
+		name    string
+		token   string
+		subject string
+	}{
+		{"valid", token, "alice"},
+		{"without expiry", forever, "bob"},
+		{"expired", expired, ""},
+		{"unknown", storage.APITokenPrefix + "unknown", ""},
+		{"hash as token", item.TokenHash, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := authAPIToken(tt.token)
+			if len(tt.subject) == 0 {
+				if err == nil {
+					t.Errorf("expected error, got subject %q", subject)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if subject != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, subject)
+			}
+		})
+	}
+}
+
+func TestAuthAccessTokenQuery(t *testing.T) {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	_, token, err := storage.CreateAPIToken("valid", "alice", time.Hour)
+	if err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	auth, err := Auth(testAuthConfig())
+	if err != nil {
+		t.Fatalf("Auth: %s", err)
+	}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/", auth, func(g *gin.Context) {
+		g.String(http.StatusOK, GetIdentity(g))
+	})
+	tests := []struct {
+		name    string
+		header  http.Header
+		query   string
+		status  int
+		subject string
+	}{
+		{"bearer header", http.Header{"Authorization": {"Bearer " + token}}, "", http.StatusOK, "alice"},
+		{"missing token", http.Header{}, "", http.StatusUnauthorized, ""},
+		{"invalid token", http.Header{"Authorization": {"Bearer " + storage.APITokenPrefix + "unknown"}}, "", http.StatusUnauthorized, ""},
+		{"query on websocket upgrade", http.Header{"Upgrade": {"websocket"}}, token, http.StatusOK, "alice"},
+		{"query without upgrade", http.Header{}, token, http.StatusUnauthorized, ""},
+		{"empty query on websocket upgrade", http.Header{"Upgrade": {"websocket"}}, "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header = tt.header
+			if len(tt.query) > 0 {
+				r.URL.RawQuery = "access_token=" + tt.query
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusOK && w.Body.String() != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 幂等键的最大长度
+	idempotencyKeyMaxLength = 255
+	// 响应头，标记响应为重放的响应
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Idempotency 处理携带 Idempotency-Key 头部的修改类请求，有效期内重复的请求直接返回保存的响应，幂等键用于不同请求时拒绝请求
+func Idempotency(ttl time.Duration) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		key := g.GetHeader(utils.HeaderIdempotencyKey)
+		if len(key) == 0 {
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			abortWithCode(g, http.StatusBadRequest, model.CodeParamError, "idempotency key too long")
+			return
+		}
+		logger := log.G(g).WithField("idempotencyKey", key)
+		// 读取请求体计算哈希，之后恢复请求体供控制器读取
+		body, err := io.ReadAll(g.Request.Body)
+		if err != nil {
+			logger.Errorf("read request body: %s", err)
+			abortWithCode(g, http.StatusBadRequest, model.CodeParamError, "read request body")
+			return
+		}
+		g.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		hash.Write([]byte(g.Request.Method + "\n" + g.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		record, reserved, err := storage.ReserveIdempotencyKey(GetIdentity(g), key, requestHash, ttl)
+		if err != nil {
+			logger.Errorf("reserve idempotency key: %s", err)
+			abortWithCode(g, http.StatusInternalServerError, model.CodeInternalError, "reserve idempotency key")
+			return
+		}
+		if !reserved {
+			switch {
+			case record.RequestHash != requestHash:
+				logger.Warn("idempotency key reused with different request")
+				abortWithCode(g, http.StatusUnprocessableEntity, model.CodeIdempotencyMismatch, "idempotency key reused with different request")
+			case !record.Completed:
+				abortWithCode(g, http.StatusConflict, model.CodeIdempotencyInProgress, "request with the same idempotency key in progress")
+			default:
+				logger.Info("replay idempotent response")
+				g.Header(HeaderIdempotentReplayed, "true")
+				g.Set(GinCtxResponseCode, record.ResponseCode)
+				g.Data(record.StatusCode, record.ContentType, record.Response)
+				g.Abort()
+			}
+			return
+		}
+		// 控制器 panic 时释放幂等键，避免幂等键在有效期内一直处于执行中
+		defer func() {
+			if r := recover(); r != nil {
+				if err := storage.ReleaseIdempotencyKey(record); err != nil {
+					logger.Errorf("release idempotency key: %s", err)
+				}
+				panic(r)
+			}
+		}()
+		// 记录响应体，控制器执行完成后保存
+		writer := &bodyWriter{ResponseWriter: g.Writer}
+		g.Writer = writer
+		g.Next()
+		// 内部错误不保存响应，允许客户端使用相同幂等键重试
+		code := g.GetString(GinCtxResponseCode)
+		if g.Writer.Status() >= http.StatusInternalServerError || code == string(model.CodeInternalError) {
+			if err := storage.ReleaseIdempotencyKey(record); err != nil {
+				logger.Errorf("release idempotency key: %s", err)
+			}
+			return
+		}
+		record.StatusCode = g.Writer.Status()
+		record.ResponseCode = code
+		record.ContentType = g.Writer.Header().Get("Content-Type")
+		record.Response = writer.body.Bytes()
+		if err := storage.CompleteIdempotencyKey(record); err != nil {
+			logger.Errorf("complete idempotency key: %s", err)
+		}
+	}
+}
+
+// bodyWriter 在写入响应的同时保存响应体
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
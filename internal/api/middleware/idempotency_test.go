@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyTestServer 控制器的行为由请求体决定：panic、error、block，其余请求返回请求体与执行次数
+type idempotencyTestServer struct {
+	engine  *gin.Engine
+	calls   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newIdempotencyTestServer(t *testing.T) *idempotencyTestServer {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	gin.SetMode(gin.TestMode)
+	s := &idempotencyTestServer{
+		engine:  gin.New(),
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	s.engine.Use(gin.CustomRecovery(func(g *gin.Context, err interface{}) {
+		g.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	s.engine.POST("/", func(g *gin.Context) {
+		g.Set(GinCtxIdentity, g.GetHeader("X-Subject"))
+	}, Idempotency(time.Hour), func(g *gin.Context) {
+		calls := atomic.AddInt32(&s.calls, 1)
+		body, _ := g.GetRawData()
+		switch string(body) {
+		case "panic":
+			panic("controller panic")
+		case "error":
+			g.Set(GinCtxResponseCode, string(model.CodeInternalError))
+			g.String(http.StatusOK, "error")
+			return
+		case "block":
+			close(s.started)
+			<-s.release
+		}
+		g.Set(GinCtxResponseCode, string(model.CodeSuccess))
+		g.String(http.StatusOK, "%s %d", body, calls)
+	})
+	return s
+}
+
+func (s *idempotencyTestServer) do(subject string, key string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("X-Subject", subject)
+	r.Header.Set(utils.HeaderIdempotencyKey, key)
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	s := newIdempotencyTestServer(t)
+	first := s.do("alice", "key-1", "create")
+	if first.Code != http.StatusOK || first.Body.String() != "create 1" {
+		t.Fatalf("unexpected first response: %d %s", first.Code, first.Body.String())
+	}
+	second := s.do("alice", "key-1", "create")
+	if second.Code != http.StatusOK || second.Body.String() != "create 1" {
+		t.Fatalf("unexpected replayed response: %d %s", second.Code, second.Body.String())
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Error("missing replayed header")
+	}
+	// 幂等键按调用者隔离
+	other := s.do("bob", "key-1", "create")
+	if other.Body.String() != "create 2" || len(other.Header().Get(HeaderIdempotentReplayed)) > 0 {
+		t.Errorf("unexpected response for another subject: %s", other.Body.String())
+	}
+	// 不携带幂等键时不处理
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("create"))
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, r)
+	if w.Body.String() != "create 3" {
+		t.Errorf("unexpected response without key: %s", w.Body.String())
+	}
+}
+
+func TestIdempotencyMismatch(t *testing.T) {
+	s := newIdempotencyTestServer(t)
+	if w := s.do("alice", "key-1", "create"); w.Code != http.StatusOK {
+		t.Fatalf("unexpected first response: %d %s", w.Code, w.Body.String())
+	}
+	w := s.do("alice", "key-1", "update")
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), string(model.CodeIdempotencyMismatch)) {
+		t.Errorf("expected mismatch, got %d %s", w.Code, w.Body.String())
+	}
+	w = s.do("alice", strings.Repeat("k", idempotencyKeyMaxLength+1), "create")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request for long key, got %d", w.Code)
+	}
+	if calls := atomic.LoadInt32(&s.calls); calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	s := newIdempotencyTestServer(t)
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- s.do("alice", "key-1", "block")
+	}()
+	<-s.started
+	w := s.do("alice", "key-1", "block")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), string(model.CodeIdempotencyInProgress)) {
+		t.Errorf("expected in progress, got %d %s", w.Code, w.Body.String())
+	}
+	close(s.release)
+	first := <-done
+	if first.Code != http.StatusOK || first.Body.String() != "block 1" {
+		t.Fatalf("unexpected first response: %d %s", first.Code, first.Body.String())
+	}
+	w = s.do("alice", "key-1", "block")
+	if w.Body.String() != "block 1" || w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("expected replayed response, got %s", w.Body.String())
+	}
+}
+
+func TestIdempotencyRelease(t *testing.T) {
+	for _, body := range []string{"panic", "error"} {
+		t.Run(body, func(t *testing.T) {
+			s := newIdempotencyTestServer(t)
+			for i := 0; i < 2; i++ {
+				if w := s.do("alice", "key-1", body); w.Code == http.StatusConflict {
+					t.Fatalf("idempotency key not released: %s", w.Body.String())
+				}
+			}
+			if calls := atomic.LoadInt32(&s.calls); calls != 2 {
+				t.Errorf("expected 2 calls, got %d", calls)
+			}
+		})
+	}
+}
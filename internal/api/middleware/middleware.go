@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/metrics"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -13,6 +15,8 @@ import (
 const (
 	// 请求ID
 	GinCtxRequestID = "X-Request-Id"
+	// 响应码，控制器序列化响应后设置
+	GinCtxResponseCode = "X-Response-Code"
 )
 
 func HeaderExtracter() gin.HandlerFunc {
@@ -62,6 +66,30 @@ func RequestLogger() gin.HandlerFunc {
 	}
 }
 
+// Metrics 按路由与响应码统计请求数量与耗时
+func Metrics() gin.HandlerFunc {
+	return func(g *gin.Context) {
+		start := time.Now()
+		g.Next()
+		metrics.ObserveHTTPRequest(g.FullPath(), g.Request.Method, g.GetString(GinCtxResponseCode), time.Since(start))
+	}
+}
+
 func Gzip() gin.HandlerFunc {
-	return gzip.Gzip(gzip.DefaultCompression)
+	// SSE 需要逐条推送事件，WebSocket 升级后直接使用连接，都不压缩
+	return gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPathsRegexs([]string{`/clusters/[^/]+/events$`, `/nodes/[^/]+/shell$`}))
+}
+
+// BindProject 为路径中没有项目ID的兼容路由设置项目ID
+// 响应中添加 Deprecation 与 Link 头部，指向项目下的新路径
+func BindProject(projectID string) gin.HandlerFunc {
+	return func(g *gin.Context) {
+		g.Params = append(g.Params, gin.Param{Key: "projectId", Value: projectID})
+		path := g.Request.URL.Path
+		if i := strings.Index(path, "/api/v1.0/"); i >= 0 {
+			successor := path[:i] + "/api/v1.0/projects/" + projectID + path[i+len("/api/v1.0"):]
+			g.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		}
+		g.Header("Deprecation", "true")
+	}
 }
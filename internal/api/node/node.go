@@ -0,0 +1,442 @@
+package node
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type AddNodeCtrl struct {
+	model.BaseController[model.AddNodeReq, model.AddNodeResp]
+}
+
+// @Summary     添加节点
+// @Description 通过 SSH 将主机加入集群，可以指定节点池继承角色、标签与污点
+// @Tags        Node
+// @Param       projectId  path     string            true "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId  path     string            true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       AddNodeReq body     model.AddNodeReq  true "请求"
+// @Response    200        {object} model.AddNodeResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodes [post]
+func (ctrl *AddNodeCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 获取集群
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response.BaseResponse, clusterID)
+	if !ok {
+		return
+	}
+	// 检查状态，运行中的集群才可以添加节点
+	if cluster.Status != storage.ClusterStatusRunning {
+		logger.WithField("clusterID", clusterID).Errorf("cluster status %s", cluster.Status)
+		ctrl.Response.Update(model.CodeForbidOperate, "cluster not running")
+		return
+	}
+	// 合并节点池配置
+	host := storage.ClusterHost{
+		IP:       req.IP,
+		Port:     req.Port,
+		Password: req.Password,
+		Role:     req.Role,
+		Labels:   map[string]string{},
+	}
+	if len(req.NodePoolID) > 0 {
+		pool, err := storage.GetNodePoolByResourceID(cluster.ResourceID, req.NodePoolID)
+		if err != nil {
+			logger.WithField("nodePoolID", req.NodePoolID).Errorf("get node pool: %s", err)
+			if err == storage.ErrDoesNotExist {
+				ctrl.Response.Update(model.CodeNotExists, "node pool not found")
+			} else {
+				ctrl.Response.Update(model.CodeInternalError, "get node pool")
+			}
+			return
+		}
+		if len(host.Role) > 0 && host.Role != pool.Role {
+			ctrl.Response.Update(model.CodeParamError, "role mismatch with node pool")
+			return
+		}
+		host.Role = pool.Role
+		for k, v := range pool.Labels.Data {
+			host.Labels[k] = v
+		}
+		host.Taints = append(host.Taints, pool.Taints.Data...)
+	}
+	if len(host.Role) == 0 {
+		ctrl.Response.Update(model.CodeParamError, "role is required without node pool")
+		return
+	}
+	for k, v := range req.Labels {
+		host.Labels[k] = v
+	}
+	host.Taints = append(host.Taints, toStorageTaints(req.Taints)...)
+	if err := utils.CheckLabels(host.Labels); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	if err := checkTaints(req.Taints); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 检查主机是否已在集群中
+	nodes, err := storage.ListClusterNodes(cluster.ResourceID)
+	if err != nil {
+		logger.Errorf("list cluster nodes: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list cluster nodes")
+		return
+	}
+	for _, n := range nodes {
+		if n.IP == host.IP {
+			ctrl.Response.Update(model.CodeAlreadyExists, "duplicated host: "+host.IP)
+			return
+		}
+	}
+	// 生成节点与登录凭据
+	node, credential, err := storage.NewNodeWithCredential(cluster.ResourceID, host)
+	if err != nil {
+		logger.Errorf("new node: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
+		return
+	}
+	node.NodePoolID = req.NodePoolID
+	task, err := newNodeTask(cluster, node, req.RequestID, storage.NodeActionAdd)
+	if err != nil {
+		logger.Errorf("new node task: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "set task config")
+		return
+	}
+	// 在事务中创建节点、提交任务，同时更新集群的资源版本，避免与删除集群等操作并发
+	if err := storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := storage.UpdateCluster(tx, cluster, map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := tx.Create(credential).Error; err != nil {
+			return errors.Wrap(err, "create credential")
+		}
+		if err := tx.Create(node).Error; err != nil {
+			return errors.Wrap(err, "create node")
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return errors.Wrap(err, "create task")
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf("transaction: %s", err)
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithFields(log.Fields{
+		"clusterID": task.ResourceID,
+		"nodeID":    node.ResourceID,
+		"taskID":    task.ID,
+	}).Info("add node task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, task.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+	// 返回响应
+	ctrl.Response.Data = node.ResourceID
+}
+
+type ListNodeCtrl struct {
+	model.BaseController[model.ListNodeReq, model.ListNodeResp]
+}
+
+// @Summary     节点列表
+// @Description 分页获取集群节点列表，支持多个过滤条件与排序
+// @Description 过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔
+// @Description 过滤字段：resourceID、nodePoolID、role、ip、status、createTime，时间使用 unix 时间戳（秒），状态可以使用名称
+// @Description 排序字段：role、ip、status、createTime
+// @Tags        Node
+// @Param       projectId path     string             true  "项目资源ID"                  extensions(x-example=project-default)
+// @Param       clusterId path     string             true  "集群资源ID"                  extensions(x-example=cluster-sedqqz7ka)
+// @Param       pageNo    query    int                true  "分页号，默认为1"                extensions(x-example=1)
+// @Param       pageSize  query    int                true  "分页大小，默认为10"              extensions(x-example=10)
+// @Param       filter    query    []string           false "过滤条件，格式为 field:op:value" collectionFormat(multi) extensions(x-example=role:eq:worker)
+// @Param       sortBy    query    string             false "排序字段"                    extensions(x-example=ip)
+// @Param       sortOrder query    string             false "排序方向，默认为 desc"           Enums(asc, desc)
+// @Response    200       {object} model.ListNodeResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodes [get]
+func (ctrl *ListNodeCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	query, err := storage.NodeListSchema.ParseListQuery(req.Filter, req.SortBy, req.SortOrder)
+	if err != nil {
+		logger.Errorf("parse list query: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	query.Offset = (req.PageNo - 1) * req.PageSize
+	query.Limit = req.PageSize
+	// 获取集群
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response.BaseResponse, clusterID)
+	if !ok {
+		return
+	}
+	items, err := storage.ListNode(cluster.ResourceID, query)
+	if err != nil {
+		logger.Errorf("list node: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list node")
+		return
+	}
+	count, err := storage.CountNode(cluster.ResourceID, query)
+	if err != nil {
+		logger.Errorf("count node: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "count node")
+		return
+	}
+	ctrl.Response.Data = make([]model.NodeSummary, 0)
+	for _, node := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.NodeSummary{
+			ResourceID: node.ResourceID,
+			NodePoolID: node.NodePoolID,
+			Role:       node.Role,
+			IP:         node.IP,
+			Port:       node.SSHPort,
+			Labels:     node.Labels.Data,
+			Taints:     toModelTaints(node.Taints.Data),
+			Status:     node.Status.String(),
+			CreateTime: utils.FormatTime(node.CreatedAt),
+		})
+	}
+	ctrl.Response.TotalCount = count
+}
+
+type DrainNodeCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     驱逐节点
+// @Description 驱逐节点上的容器组并停止调度
+// @Tags        Node
+// @Param       projectId path     string             true "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId path     string             true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       nodeId    path     string             true "节点资源ID" extensions(x-example=node-k2jd8sm1qa)
+// @Response    200       {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodes/{nodeId}/drain [post]
+func (ctrl *DrainNodeCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	// 获取集群与节点
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response, clusterID)
+	if !ok {
+		return
+	}
+	node, ok := getNode(logger, &ctrl.Response, cluster.ResourceID, g.Param("nodeId"))
+	if !ok {
+		return
+	}
+	// 检查状态，稳态集群中运行中的节点才可以驱逐
+	if cluster.Status < storage.ClusterStatusRunning {
+		logger.WithField("clusterID", clusterID).Error("cluster status pending")
+		ctrl.Response.Update(model.CodeForbidOperate, "cluster status pending")
+		return
+	}
+	if node.Status != storage.NodeStatusRunning {
+		logger.WithField("nodeID", node.ResourceID).Errorf("node status %s", node.Status)
+		ctrl.Response.Update(model.CodeForbidOperate, "node not running")
+		return
+	}
+	if err := commitNodeTask(cluster, node, ctrl.Request.RequestID, storage.NodeActionDrain, storage.NodeStatusDraining); err != nil {
+		logger.Errorf("commit node task: %s", err)
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster or node modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithFields(log.Fields{
+		"clusterID": cluster.ResourceID,
+		"nodeID":    node.ResourceID,
+	}).Info("drain node task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, cluster.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+}
+
+type RemoveNodeCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     移除节点
+// @Description 驱逐节点后将节点从集群中移除并清理主机，不允许移除第一个控制面节点
+// @Tags        Node
+// @Param       projectId path     string             true "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId path     string             true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       nodeId    path     string             true "节点资源ID" extensions(x-example=node-k2jd8sm1qa)
+// @Response    200       {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodes/{nodeId} [delete]
+func (ctrl *RemoveNodeCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	// 获取集群与节点
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response, clusterID)
+	if !ok {
+		return
+	}
+	node, ok := getNode(logger, &ctrl.Response, cluster.ResourceID, g.Param("nodeId"))
+	if !ok {
+		return
+	}
+	// 检查状态，稳态集群中稳态的节点才可以移除
+	if cluster.Status < storage.ClusterStatusRunning {
+		logger.WithField("clusterID", clusterID).Error("cluster status pending")
+		ctrl.Response.Update(model.CodeForbidOperate, "cluster status pending")
+		return
+	}
+	if node.Status < storage.NodeStatusRunning {
+		logger.WithField("nodeID", node.ResourceID).Error("node status pending")
+		ctrl.Response.Update(model.CodeForbidOperate, "node status pending")
+		return
+	}
+	// 第一个控制面节点是集群的访问入口，不允许移除
+	nodes, err := storage.ListClusterNodes(cluster.ResourceID)
+	if err != nil {
+		logger.Errorf("list cluster nodes: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list cluster nodes")
+		return
+	}
+	for _, n := range nodes {
+		if n.Role != storage.HostRoleMaster {
+			continue
+		}
+		if n.ResourceID == node.ResourceID {
+			ctrl.Response.Update(model.CodeForbidOperate, "first master node can not be removed")
+			return
+		}
+		break
+	}
+	if err := commitNodeTask(cluster, node, ctrl.Request.RequestID, storage.NodeActionRemove, storage.NodeStatusRemoving); err != nil {
+		logger.Errorf("commit node task: %s", err)
+		if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster or node modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithFields(log.Fields{
+		"clusterID": cluster.ResourceID,
+		"nodeID":    node.ResourceID,
+	}).Info("remove node task committed")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, cluster.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+}
+
+// getCluster 获取路径参数中项目的集群，出错时更新响应
+func getCluster(g *gin.Context, logger log.Logger, resp *model.BaseResponse, clusterID string) (*storage.Cluster, bool) {
+	cluster, err := storage.GetProjectClusterByResourceID(g.Param("projectId"), clusterID)
+	if err != nil {
+		logger.WithField("clusterID", clusterID).Errorf("get cluster: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "cluster not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get cluster")
+		}
+		return nil, false
+	}
+	return cluster, true
+}
+
+// getNode 获取集群节点，出错时更新响应
+func getNode(logger log.Logger, resp *model.BaseResponse, clusterID string, nodeID string) (*storage.Node, bool) {
+	node, err := storage.GetNodeByResourceID(clusterID, nodeID)
+	if err != nil {
+		logger.WithField("nodeID", nodeID).Errorf("get node: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "node not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get node")
+		}
+		return nil, false
+	}
+	return node, true
+}
+
+// newNodeTask 生成节点任务，节点任务与集群任务使用同一个资源ID，保证同一集群的任务串行执行
+func newNodeTask(cluster *storage.Cluster, node *storage.Node, requestID string, action string) (*storage.Task, error) {
+	// 最多重试10次，超过后节点进入异常状态
+	task := &storage.Task{
+		ResourceID: cluster.ResourceID,
+		ProjectID:  cluster.ProjectID,
+		RequestID:  requestID,
+		Status:     storage.TaskStatusEnqueued,
+		RetryType:  storage.TaskRetryTypeFixed,
+		RetryDelay: 10,
+		RetryCount: 0,
+		RetryLimit: 10,
+		Action:     action,
+	}
+	if err := task.SetConfig(&storage.ClusterTaskConfig{NodeID: node.ResourceID}); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// commitNodeTask 在事务中更改节点状态、提交任务，集群与节点的资源版本都必须未变化，否则返回 ErrConflict
+func commitNodeTask(cluster *storage.Cluster, node *storage.Node, requestID string, action string, status storage.NodeStatus) error {
+	task, err := newNodeTask(cluster, node, requestID, action)
+	if err != nil {
+		return errors.Wrap(err, "set task config")
+	}
+	node.Status = status
+	return storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := storage.UpdateCluster(tx, cluster, map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := storage.UpdateNode(tx, node, map[string]interface{}{"status": node.Status}); err != nil {
+			return err
+		}
+		if err := tx.Create(task).Error; err != nil {
+			return errors.Wrap(err, "create task")
+		}
+		return nil
+	})
+}
+
+// checkTaints 检查污点的键与值是否符合 k8s 的格式要求，效果由参数校验限制
+func checkTaints(items []model.Taint) error {
+	for _, t := range items {
+		if errs := validation.IsQualifiedName(t.Key); len(errs) > 0 {
+			return errors.Errorf("invalid taint key %s: %s", t.Key, errs[0])
+		}
+		if errs := validation.IsValidLabelValue(t.Value); len(errs) > 0 {
+			return errors.Errorf("invalid taint value %s: %s", t.Value, errs[0])
+		}
+	}
+	return nil
+}
+
+func toStorageTaints(items []model.Taint) []storage.Taint {
+	taints := make([]storage.Taint, 0, len(items))
+	for _, t := range items {
+		taints = append(taints, storage.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect})
+	}
+	return taints
+}
+
+func toModelTaints(items []storage.Taint) []model.Taint {
+	taints := make([]model.Taint, 0, len(items))
+	for _, t := range items {
+		taints = append(taints, model.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect})
+	}
+	return taints
+}
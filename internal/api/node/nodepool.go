@@ -0,0 +1,107 @@
+package node
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+)
+
+type CreateNodePoolCtrl struct {
+	model.BaseController[model.CreateNodePoolReq, model.CreateNodePoolResp]
+}
+
+// @Summary     创建节点池
+// @Description 创建节点池，添加节点时可以指定节点池继承角色、标签与污点
+// @Tags        Node
+// @Param       projectId         path     string                   true "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId         path     string                   true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Param       CreateNodePoolReq body     model.CreateNodePoolReq  true "请求"
+// @Response    200               {object} model.CreateNodePoolResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodepools [post]
+func (ctrl *CreateNodePoolCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 获取集群
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response.BaseResponse, clusterID)
+	if !ok {
+		return
+	}
+	// 检查参数
+	if err := utils.CheckName(req.Name); err != nil {
+		ctrl.Response.Update(model.CodeParamError, "invalid name: "+err.Error())
+		return
+	}
+	if err := utils.CheckLabels(req.Labels); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	if err := checkTaints(req.Taints); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 生成节点池资源ID
+	resourceID, err := storage.GenerateNodePoolResourceID()
+	if err != nil {
+		logger.Errorf("generate resourceID: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
+		return
+	}
+	pool := &storage.NodePool{
+		ResourceID: resourceID.ResourceID,
+		ClusterID:  cluster.ResourceID,
+		Name:       req.Name,
+		Role:       req.Role,
+		Labels:     datatypes.JSONType[map[string]string]{Data: req.Labels},
+		Taints:     datatypes.JSONType[[]storage.Taint]{Data: toStorageTaints(req.Taints)},
+	}
+	if err := storage.DB().Create(pool).Error; err != nil {
+		logger.Errorf("create node pool: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "create node pool")
+		return
+	}
+	ctrl.Response.Data = pool.ResourceID
+}
+
+type ListNodePoolCtrl struct {
+	model.BaseController[model.ListNodePoolReq, model.ListNodePoolResp]
+}
+
+// @Summary     节点池列表
+// @Description 获取集群的节点池列表
+// @Tags        Node
+// @Param       projectId path     string                 true "项目资源ID" extensions(x-example=project-default)
+// @Param       clusterId path     string                 true "集群资源ID" extensions(x-example=cluster-sedqqz7ka)
+// @Response    200       {object} model.ListNodePoolResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodepools [get]
+func (ctrl *ListNodePoolCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	// 获取集群
+	clusterID := g.Param("clusterId")
+	cluster, ok := getCluster(g, logger, &ctrl.Response.BaseResponse, clusterID)
+	if !ok {
+		return
+	}
+	items, err := storage.ListNodePool(cluster.ResourceID)
+	if err != nil {
+		logger.Errorf("list node pool: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list node pool")
+		return
+	}
+	ctrl.Response.Data = make([]model.NodePoolSummary, 0)
+	for _, pool := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.NodePoolSummary{
+			ResourceID: pool.ResourceID,
+			Name:       pool.Name,
+			Role:       pool.Role,
+			Labels:     pool.Labels.Data,
+			Taints:     toModelTaints(pool.Taints.Data),
+			CreateTime: utils.FormatTime(pool.CreatedAt),
+		})
+	}
+}
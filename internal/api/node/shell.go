@@ -0,0 +1,262 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/api/middleware"
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	shellDefaultCols    = 80
+	shellDefaultRows    = 24
+	shellDefaultTerm    = "xterm-256color"
+	shellConnectTimeout = 10 * time.Second // 连接节点的超时时长
+	shellBufferSize     = 32 * 1024        // 读取终端输出的缓冲区大小
+)
+
+var (
+	// shellIdleTimeout 无输入时断开的时长，为 0 时不断开
+	shellIdleTimeout time.Duration
+	// shellRecordDir 录像目录，为空时不录像
+	shellRecordDir string
+)
+
+// SetupShell 设置节点终端的空闲超时与录像目录
+func SetupShell(cfg *config.Config) {
+	shellIdleTimeout = cfg.Terminal.IdleTimeout
+	shellRecordDir = cfg.Terminal.RecordDir
+}
+
+type NodeShellCtrl struct {
+	model.BaseController[model.NodeShellReq, model.NodeShellResp]
+}
+
+func (ctrl *NodeShellCtrl) Codec() model.Codec {
+	return model.CodecWS
+}
+
+// @Summary     节点终端
+// @Description 通过 WebSocket 以 root 登录节点的交互式终端，浏览器无法设置头部时使用 access_token 参数携带令牌。客户端以 JSON 文本帧发送 model.ShellMessage，type 为 input 时 data 为终端输入，type 为 resize 时调整终端大小；终端输出以二进制帧返回；会话结束时服务端发送 type 为 exit 的消息并关闭连接。超过空闲时长没有输入时断开，配置录像目录时以 asciicast v2 格式录像
+// @Tags        Node
+// @Param       projectId    path     string              true  "项目资源ID"      extensions(x-example=project-default)
+// @Param       clusterId    path     string              true  "集群资源ID"      extensions(x-example=cluster-sedqqz7ka)
+// @Param       nodeId       path     string              true  "节点资源ID"      extensions(x-example=node-k2jd8sm1qa)
+// @Param       cols         query    int                 false "终端列数，默认为 80" extensions(x-example=80)
+// @Param       rows         query    int                 false "终端行数，默认为 24" extensions(x-example=24)
+// @Param       term         query    string              false "终端类型，默认为 xterm-256color"
+// @Param       access_token query    string              false "令牌，只用于 WebSocket 升级请求"
+// @Response    200          {object} model.NodeShellResp "连接失败时的响应，成功时升级为 WebSocket"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/nodes/{nodeId}/shell [get]
+func (ctrl *NodeShellCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 流式控制器跳过了请求解析，使用 JSON 编码解析查询参数
+	if err := model.CodecJSON.BindRequest(g, req); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	if !middleware.IsWebSocketUpgrade(g.Request) {
+		ctrl.Response.Update(model.CodeParamError, "websocket upgrade required")
+		return
+	}
+	if req.Cols == 0 {
+		req.Cols = shellDefaultCols
+	}
+	if req.Rows == 0 {
+		req.Rows = shellDefaultRows
+	}
+	if len(req.Term) == 0 {
+		req.Term = shellDefaultTerm
+	}
+	// 获取集群与节点
+	cluster, ok := getCluster(g, logger, &ctrl.Response.BaseResponse, g.Param("clusterId"))
+	if !ok {
+		return
+	}
+	node, ok := getNode(logger, &ctrl.Response.BaseResponse, cluster.ResourceID, g.Param("nodeId"))
+	if !ok {
+		return
+	}
+	credential, err := storage.GetCredentialByResourceID(node.CredentialID)
+	if err != nil {
+		logger.WithField("nodeID", node.ResourceID).Errorf("get credential: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get credential")
+		return
+	}
+	// 升级前连接节点，连接失败时返回错误响应
+	ctx, cancel := context.WithTimeout(g.Request.Context(), shellConnectTimeout)
+	defer cancel()
+	shell, err := utils.OpenSshShell(ctx, node.IP, node.SSHPort, credential.Password, req.Term, req.Cols, req.Rows)
+	if err != nil {
+		logger.WithField("nodeID", node.ResourceID).Errorf("open shell: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "connect node")
+		return
+	}
+	session := &shellSession{logger: logger.WithField("nodeID", node.ResourceID), shell: shell}
+	defer session.close()
+	if len(shellRecordDir) > 0 {
+		path, err := shellRecordPath(cluster.ResourceID, node.ResourceID)
+		if err == nil {
+			session.recorder, err = utils.NewAsciicastWriter(path, req.Cols, req.Rows, req.Term, middleware.GetIdentity(g)+"@"+node.IP)
+		}
+		if err != nil {
+			logger.WithField("nodeID", node.ResourceID).Errorf("create shell record: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "create shell record")
+			return
+		}
+		session.logger = session.logger.WithField("record", path)
+	}
+	session.logger.Info("shell opened")
+	server := websocket.Server{
+		// 使用令牌认证，不依赖 cookie，允许其他来源的终端页面连接
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   session.serve,
+	}
+	server.ServeHTTP(g.Writer, g.Request)
+}
+
+// shellRecordPath 录像文件路径，文件名使用服务端生成的 UUID，不使用客户端传入的请求ID，路径必须位于录像目录下
+func shellRecordPath(clusterID string, nodeID string) (string, error) {
+	root := filepath.Clean(shellRecordDir)
+	path := filepath.Join(root, clusterID, nodeID, time.Now().Format("20060102150405")+"-"+utils.UUID()+".cast")
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return "", errors.Errorf("record path %s is outside the record dir", path)
+	}
+	return path, nil
+}
+
+// shellSession 在 WebSocket 与节点终端之间转发数据
+type shellSession struct {
+	logger     log.Logger
+	shell      *utils.SshShell
+	recorder   *utils.AsciicastWriter
+	outputDone chan struct{} // 转发终端输出结束后关闭，之后才能关闭录像
+}
+
+func (s *shellSession) serve(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	// 任一方向结束时写入结束原因
+	done := make(chan string, 2)
+	activity := make(chan struct{}, 1)
+	s.outputDone = make(chan struct{})
+	go s.forwardOutput(ws, done)
+	go s.forwardInput(ws, done, activity)
+	var idle <-chan time.Time
+	var timer *time.Timer
+	if shellIdleTimeout > 0 {
+		timer = time.NewTimer(shellIdleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+	var reason string
+loop:
+	for {
+		select {
+		case reason = <-done:
+			break loop
+		case <-activity:
+			if timer != nil {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(shellIdleTimeout)
+			}
+		case <-idle:
+			reason = "idle timeout"
+			break loop
+		}
+	}
+	s.logger.Infof("shell closed: %s", reason)
+	websocket.JSON.Send(ws, &model.ShellMessage{Type: model.ShellMessageExit, Data: reason})
+	ws.Close()
+}
+
+// forwardOutput 将终端输出以二进制帧发送到客户端并录像
+func (s *shellSession) forwardOutput(ws *websocket.Conn, done chan<- string) {
+	defer close(s.outputDone)
+	buf := make([]byte, shellBufferSize)
+	for {
+		n, err := s.shell.Stdout.Read(buf)
+		if n > 0 {
+			if s.recorder != nil {
+				if err := s.recorder.Output(buf[:n]); err != nil {
+					s.logger.Errorf("record shell output: %s", err)
+				}
+			}
+			if _, err := ws.Write(buf[:n]); err != nil {
+				done <- "connection closed"
+				return
+			}
+		}
+		if err != nil {
+			done <- "shell exited"
+			return
+		}
+	}
+}
+
+// forwardInput 处理客户端的输入与调整终端大小的消息，无法解析的消息直接忽略
+func (s *shellSession) forwardInput(ws *websocket.Conn, done chan<- string, activity chan<- struct{}) {
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			done <- "connection closed"
+			return
+		}
+		msg := &model.ShellMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			s.logger.Warnf("unmarshal shell message: %s", err)
+			continue
+		}
+		switch msg.Type {
+		case model.ShellMessageInput:
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+			if _, err := s.shell.Stdin.Write([]byte(msg.Data)); err != nil {
+				done <- "shell exited"
+				return
+			}
+		case model.ShellMessageResize:
+			if msg.Cols <= 0 || msg.Rows <= 0 {
+				continue
+			}
+			if err := s.shell.Resize(msg.Cols, msg.Rows); err != nil {
+				s.logger.Warnf("resize shell: %s", err)
+				continue
+			}
+			if s.recorder != nil {
+				if err := s.recorder.Resize(msg.Cols, msg.Rows); err != nil {
+					s.logger.Errorf("record shell resize: %s", err)
+				}
+			}
+		}
+	}
+}
+
+func (s *shellSession) close() {
+	s.shell.Close()
+	if s.outputDone != nil {
+		<-s.outputDone
+	}
+	if s.recorder != nil {
+		if err := s.recorder.Close(); err != nil {
+			s.logger.Errorf("close shell record: %s", err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package node
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellRecordPath(t *testing.T) {
+	shellRecordDir = t.TempDir()
+	defer func() { shellRecordDir = "" }()
+	tests := []struct {
+		name      string
+		clusterID string
+		nodeID    string
+		err       bool
+	}{
+		{name: "resource ids", clusterID: "cluster-sedqqz7ka", nodeID: "node-sedqqz7ka"},
+		{name: "cluster id escapes record dir", clusterID: "../../../../etc/cron.d", nodeID: "node-a", err: true},
+		{name: "node id escapes record dir", clusterID: "cluster-a", nodeID: "../../..", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := shellRecordPath(tt.clusterID, tt.nodeID)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected error, got %s", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if filepath.Dir(path) != filepath.Join(shellRecordDir, tt.clusterID, tt.nodeID) || !strings.HasSuffix(path, ".cast") {
+				t.Errorf("unexpected record path: %s", path)
+			}
+		})
+	}
+}
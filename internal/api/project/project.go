@@ -0,0 +1,167 @@
+package project
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateProjectCtrl struct {
+	model.BaseController[model.CreateProjectReq, model.CreateProjectResp]
+}
+
+// @Summary     创建项目
+// @Description 创建项目，集群归属于项目，调用者通过限定项目的角色绑定访问项目中的集群
+// @Tags        Project
+// @Param       CreateProjectReq body     model.CreateProjectReq  true "请求"
+// @Response    200              {object} model.CreateProjectResp "响应"
+// @Security    BearerAuth
+// @Router      /projects [post]
+func (ctrl *CreateProjectCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 检查参数
+	if err := utils.CheckName(req.Name); err != nil {
+		ctrl.Response.Update(model.CodeParamError, "invalid name: "+err.Error())
+		return
+	}
+	// 检查是否重名
+	if _, err := storage.GetProjectByName(req.Name); err == nil {
+		ctrl.Response.Update(model.CodeAlreadyExists, "project already exists")
+		return
+	} else if err != storage.ErrDoesNotExist {
+		logger.Errorf("get project: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get project")
+		return
+	}
+	// 生成项目资源ID
+	resourceID, err := storage.GenerateProjectResourceID()
+	if err != nil {
+		logger.Errorf("generate resourceID: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
+		return
+	}
+	project := &storage.Project{
+		ResourceID:   resourceID.ResourceID,
+		Name:         req.Name,
+		Description:  req.Description,
+		ClusterQuota: req.ClusterQuota,
+	}
+	if err := storage.DB().Create(project).Error; err != nil {
+		logger.Errorf("create project: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "create project")
+		return
+	}
+	ctrl.Response.Data = project.ResourceID
+}
+
+type UpdateProjectCtrl struct {
+	model.BaseController[model.UpdateProjectReq, model.BaseResponse]
+}
+
+// @Summary     修改项目
+// @Description 修改项目描述与集群数量配额
+// @Tags        Project
+// @Param       projectId        path     string                 true "项目资源ID" extensions(x-example=project-default)
+// @Param       UpdateProjectReq body     model.UpdateProjectReq true "请求"
+// @Response    200              {object} model.BaseResponse     "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId} [put]
+func (ctrl *UpdateProjectCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	project, ok := getProject(g, logger, &ctrl.Response)
+	if !ok {
+		return
+	}
+	project.Description = req.Description
+	project.ClusterQuota = req.ClusterQuota
+	if err := storage.UpdateProject(project); err != nil {
+		logger.WithField("projectID", project.ResourceID).Errorf("update project: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "update project")
+		return
+	}
+}
+
+type GetProjectCtrl struct {
+	model.BaseController[model.GetProjectReq, model.GetProjectResp]
+}
+
+// @Summary     项目详情
+// @Description 根据项目ID获取项目详细信息
+// @Tags        Project
+// @Param       projectId path     string               true "项目资源ID" extensions(x-example=project-default)
+// @Response    200       {object} model.GetProjectResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId} [get]
+func (ctrl *GetProjectCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	project, ok := getProject(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	item := toProjectItem(project)
+	ctrl.Response.Data = &item
+}
+
+type ListProjectCtrl struct {
+	model.BaseController[model.ListProjectReq, model.ListProjectResp]
+}
+
+// @Summary     项目列表
+// @Description 分页获取项目列表
+// @Tags        Project
+// @Param       pageNo   query    int                   true "分页号，默认为1"   extensions(x-example=1)
+// @Param       pageSize query    int                   true "分页大小，默认为10" extensions(x-example=10)
+// @Response    200      {object} model.ListProjectResp "响应"
+// @Security    BearerAuth
+// @Router      /projects [get]
+func (ctrl *ListProjectCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	items, err := storage.ListProject((req.PageNo-1)*req.PageSize, req.PageSize)
+	if err != nil {
+		logger.Errorf("list project: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list project")
+		return
+	}
+	count, err := storage.CountProject()
+	if err != nil {
+		logger.Errorf("count project: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "count project")
+		return
+	}
+	ctrl.Response.Data = make([]model.ProjectItem, 0)
+	for i := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, toProjectItem(&items[i]))
+	}
+	ctrl.Response.TotalCount = count
+}
+
+// getProject 根据路径参数获取项目，出错时更新响应
+func getProject(g *gin.Context, logger log.Logger, resp *model.BaseResponse) (*storage.Project, bool) {
+	projectID := g.Param("projectId")
+	project, err := storage.GetProjectByResourceID(projectID)
+	if err != nil {
+		logger.WithField("projectID", projectID).Errorf("get project: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "project not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get project")
+		}
+		return nil, false
+	}
+	return project, true
+}
+
+func toProjectItem(project *storage.Project) model.ProjectItem {
+	return model.ProjectItem{
+		ResourceID:   project.ResourceID,
+		Name:         project.Name,
+		Description:  project.Description,
+		ClusterQuota: project.ClusterQuota,
+		CreateTime:   utils.FormatTime(project.CreatedAt),
+	}
+}
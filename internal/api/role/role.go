@@ -0,0 +1,236 @@
+package role
+
+import (
+	"strconv"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+)
+
+type CreateRoleCtrl struct {
+	model.BaseController[model.CreateRoleReq, model.CreateRoleResp]
+}
+
+// @Summary     创建角色
+// @Description 创建自定义角色，角色名称不能与已有角色重复
+// @Tags        Role
+// @Param       CreateRoleReq body     model.CreateRoleReq  true "请求"
+// @Response    200           {object} model.CreateRoleResp "响应"
+// @Security    BearerAuth
+// @Router      /roles [post]
+func (ctrl *CreateRoleCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 检查参数
+	if err := utils.CheckName(req.Name); err != nil {
+		ctrl.Response.Update(model.CodeParamError, "invalid name: "+err.Error())
+		return
+	}
+	if err := checkPermissions(req.Permissions); err != nil {
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 检查是否重名
+	if _, err := storage.GetRoleByName(req.Name); err == nil {
+		ctrl.Response.Update(model.CodeAlreadyExists, "role already exists")
+		return
+	} else if err != storage.ErrDoesNotExist {
+		logger.Errorf("get role: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get role")
+		return
+	}
+	role := &storage.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: datatypes.JSONType[[]storage.Permission]{Data: req.Permissions},
+	}
+	if err := storage.DB().Create(role).Error; err != nil {
+		logger.Errorf("create role: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "create role")
+		return
+	}
+	ctrl.Response.Data = role.Name
+}
+
+type ListRoleCtrl struct {
+	model.BaseController[model.ListRoleReq, model.ListRoleResp]
+}
+
+// @Summary     角色列表
+// @Description 获取全部内置角色与自定义角色
+// @Tags        Role
+// @Response    200 {object} model.ListRoleResp "响应"
+// @Security    BearerAuth
+// @Router      /roles [get]
+func (ctrl *ListRoleCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	items, err := storage.ListRole()
+	if err != nil {
+		logger.Errorf("list role: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list role")
+		return
+	}
+	ctrl.Response.Data = make([]model.RoleItem, 0)
+	for _, role := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.RoleItem{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: role.Permissions.Data,
+			Builtin:     role.Builtin,
+			CreateTime:  utils.FormatTime(role.CreatedAt),
+		})
+	}
+}
+
+type DeleteRoleCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     删除角色
+// @Description 删除自定义角色与该角色的全部绑定，内置角色不允许删除
+// @Tags        Role
+// @Param       roleName path     string             true "角色名称" extensions(x-example=auditor)
+// @Response    200      {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /roles/{roleName} [delete]
+func (ctrl *DeleteRoleCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	roleName := g.Param("roleName")
+	role, err := storage.GetRoleByName(roleName)
+	if err != nil {
+		logger.WithField("role", roleName).Errorf("get role: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "role not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get role")
+		}
+		return
+	}
+	if role.Builtin {
+		ctrl.Response.Update(model.CodeForbidOperate, "builtin role can not be deleted")
+		return
+	}
+	if err := storage.DeleteRole(role); err != nil {
+		logger.WithField("role", roleName).Errorf("delete role: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "delete role")
+		return
+	}
+}
+
+type CreateRoleBindingCtrl struct {
+	model.BaseController[model.CreateRoleBindingReq, model.CreateRoleBindingResp]
+}
+
+// @Summary     创建角色绑定
+// @Description 将角色授予调用者，可以限定角色只在指定项目中生效
+// @Tags        Role
+// @Param       CreateRoleBindingReq body     model.CreateRoleBindingReq  true "请求"
+// @Response    200                  {object} model.CreateRoleBindingResp "响应"
+// @Security    BearerAuth
+// @Router      /rolebindings [post]
+func (ctrl *CreateRoleBindingCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	item, err := storage.CreateRoleBinding(req.Subject, req.Role, req.ProjectID)
+	if err != nil {
+		switch err {
+		case storage.ErrDoesNotExist:
+			ctrl.Response.Update(model.CodeNotExists, "role or project not found")
+		case storage.ErrAlreadyExists:
+			ctrl.Response.Update(model.CodeAlreadyExists, "role binding already exists")
+		default:
+			logger.Errorf("create role binding: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "create role binding")
+		}
+		return
+	}
+	ctrl.Response.Data = item.ID
+}
+
+type ListRoleBindingCtrl struct {
+	model.BaseController[model.ListRoleBindingReq, model.ListRoleBindingResp]
+}
+
+// @Summary     角色绑定列表
+// @Description 获取角色绑定列表，可以按调用者身份过滤
+// @Tags        Role
+// @Param       subject query    string                    false "调用者身份" extensions(x-example=alice)
+// @Response    200     {object} model.ListRoleBindingResp "响应"
+// @Security    BearerAuth
+// @Router      /rolebindings [get]
+func (ctrl *ListRoleBindingCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	items, err := storage.ListRoleBinding(ctrl.Request.Subject)
+	if err != nil {
+		logger.Errorf("list role binding: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list role binding")
+		return
+	}
+	ctrl.Response.Data = make([]model.RoleBindingItem, 0)
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.RoleBindingItem{
+			ID:         item.ID,
+			Subject:    item.Subject,
+			Role:       item.RoleName,
+			ProjectID:  item.ProjectID,
+			CreateTime: utils.FormatTime(item.CreatedAt),
+		})
+	}
+}
+
+type DeleteRoleBindingCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     删除角色绑定
+// @Description 收回调用者的角色
+// @Tags        Role
+// @Param       bindingId path     int                true "角色绑定ID" extensions(x-example=1)
+// @Response    200       {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /rolebindings/{bindingId} [delete]
+func (ctrl *DeleteRoleBindingCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	bindingID, err := strconv.ParseUint(g.Param("bindingId"), 10, 64)
+	if err != nil {
+		ctrl.Response.Update(model.CodeParamError, "invalid binding id")
+		return
+	}
+	item, err := storage.GetRoleBindingByID(bindingID)
+	if err != nil {
+		logger.WithField("bindingID", bindingID).Errorf("get role binding: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "role binding not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get role binding")
+		}
+		return
+	}
+	if err := storage.DB().Delete(item).Error; err != nil {
+		logger.WithField("bindingID", bindingID).Errorf("delete role binding: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "delete role binding")
+		return
+	}
+}
+
+// checkPermissions 检查权限是否均为已定义的权限
+func checkPermissions(permissions []storage.Permission) error {
+	for _, p := range permissions {
+		known := false
+		for _, item := range storage.Permissions {
+			if p == item {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return errors.Errorf("unknown permission: %s", p)
+		}
+	}
+	return nil
+}
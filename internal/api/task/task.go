@@ -0,0 +1,510 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/api/middleware"
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ListTaskCtrl struct {
+	model.BaseController[model.ListTaskReq, model.ListTaskResp]
+}
+
+// @Summary     任务列表
+// @Description 分页获取集群的任务列表，默认最新提交的任务在前，已删除集群的任务通过管理接口查询
+// @Description 过滤条件格式为 field:op:value，操作符支持 eq、ne、in、like、gt、lt、between，in 与 between 的多个值使用逗号分隔
+// @Description 过滤字段：action、status、retryCount、requestID、createTime、updateTime，时间使用 unix 时间戳（秒），状态可以使用名称
+// @Description 排序字段：status、retryCount、createTime、updateTime
+// @Tags        Task
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string             true  "项目资源ID"                             extensions(x-example=project-default)
+// @Param       clusterId path     string             true  "集群资源ID"                             extensions(x-example=cluster-sedqqz7ka)
+// @Param       pageNo    query    int                false "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数" extensions(x-example=1)
+// @Param       pageSize  query    int                true  "分页大小，默认为10"                         extensions(x-example=10)
+// @Param       pageToken query    string             false "分页令牌，使用上一页返回的 nextPageToken 获取下一页"
+// @Param       withTotal query    bool               false "是否返回总数，使用页码分页时总是返回"
+// @Param       filter    query    []string           false "过滤条件，格式为 field:op:value" collectionFormat(multi) extensions(x-example=action:eq:CreateCluster)
+// @Param       sortBy    query    string             false "排序字段"                    extensions(x-example=createTime)
+// @Param       sortOrder query    string             false "排序方向，默认为 desc"           Enums(asc, desc)
+// @Response    200       {object} model.ListTaskResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/clusters/{clusterId}/tasks [get]
+func (ctrl *ListTaskCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	query, err := storage.TaskListSchema.ParseListQuery(req.Filter, req.SortBy, req.SortOrder)
+	if err != nil {
+		logger.Errorf("parse list query: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 分页令牌只能用于过滤与排序条件相同的查询
+	query.Scope = utils.QueryScope(g.Request.URL, model.PageParams...)
+	if err := query.SetPage(req.PageNo, req.PageSize, req.PageToken); err != nil {
+		logger.Errorf("set page: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	projectID := g.Param("projectId")
+	clusterID := g.Param("clusterId")
+	items, nextPageToken, err := storage.ListTask(projectID, clusterID, query)
+	if err != nil {
+		logger.Errorf("list task: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list task")
+		return
+	}
+	// 使用页码分页时总是计算总数，兼容之前的调用方式
+	if req.PageNo > 0 || req.WithTotal {
+		count, err := storage.CountTask(projectID, clusterID, query)
+		if err != nil {
+			logger.Errorf("count task: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "count task")
+			return
+		}
+		ctrl.Response.TotalCount = &count
+	}
+	ctrl.Response.Data = make([]model.TaskSummary, 0)
+	for _, task := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.TaskSummary{
+			ID:         task.ID,
+			ClusterID:  task.ResourceID,
+			Action:     task.Action,
+			Status:     task.Status.String(),
+			RetryCount: task.RetryCount,
+			RetryLimit: task.RetryLimit,
+			RetryAt:    formatRetryAt(&task),
+			CreateTime: utils.FormatTime(task.CreatedAt),
+			UpdateTime: utils.FormatTime(task.UpdatedAt),
+			RequestID:  task.RequestID,
+		})
+	}
+	ctrl.Response.NextPageToken = nextPageToken
+}
+
+type GetTaskCtrl struct {
+	model.BaseController[model.GetTaskReq, model.GetTaskResp]
+}
+
+// @Summary     任务详情
+// @Description 根据任务ID获取任务详细信息
+// @Tags        Task
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string            true "项目资源ID" extensions(x-example=project-default)
+// @Param       taskId    path     int               true "任务ID"   extensions(x-example=1)
+// @Response    200       {object} model.GetTaskResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/tasks/{taskId} [get]
+func (ctrl *GetTaskCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	task, ok := getTask(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	cfg := &storage.ClusterTaskConfig{}
+	if err := task.GetConfig(cfg); err != nil {
+		logger.WithField("taskID", task.ID).Errorf("get task config: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get task config")
+		return
+	}
+	ctrl.Response.Data = &model.TaskDetail{
+		ID:         task.ID,
+		ClusterID:  task.ResourceID,
+		Action:     task.Action,
+		Status:     task.Status.String(),
+		RetryCount: task.RetryCount,
+		RetryLimit: task.RetryLimit,
+		RetryAt:    formatRetryAt(task),
+		NodeID:     cfg.NodeID,
+		Version:    cfg.Version,
+		Step:       cfg.Step,
+		CreateTime: utils.FormatTime(task.CreatedAt),
+		UpdateTime: utils.FormatTime(task.UpdatedAt),
+		RequestID:  task.RequestID,
+	}
+}
+
+type ListTaskLogCtrl struct {
+	model.BaseController[model.ListTaskLogReq, model.ListTaskLogResp]
+}
+
+// @Summary     任务日志
+// @Description 分页获取任务每次执行的日志，最新的日志在前，支持页码与分页令牌两种分页方式
+// @Tags        Task
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string                true  "项目资源ID"                             extensions(x-example=project-default)
+// @Param       taskId    path     int                   true  "任务ID"                               extensions(x-example=1)
+// @Param       pageNo    query    int                   false "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数" extensions(x-example=1)
+// @Param       pageSize  query    int                   true  "分页大小，默认为10"                         extensions(x-example=10)
+// @Param       pageToken query    string                false "分页令牌，使用上一页返回的 nextPageToken 获取下一页"
+// @Param       withTotal query    bool                  false "是否返回总数，使用页码分页时总是返回"
+// @Response    200       {object} model.ListTaskLogResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/tasks/{taskId}/logs [get]
+func (ctrl *ListTaskLogCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	query := &storage.ListQuery{SortOrder: storage.SortOrderDesc}
+	// 分页令牌只能用于过滤与排序条件相同的查询
+	query.Scope = utils.QueryScope(g.Request.URL, model.PageParams...)
+	if err := query.SetPage(req.PageNo, req.PageSize, req.PageToken); err != nil {
+		logger.Errorf("set page: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	task, ok := getTask(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	items, nextPageToken, err := storage.ListTaskLog(task.ID, query)
+	if err != nil {
+		logger.Errorf("list task log: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list task log")
+		return
+	}
+	// 使用页码分页时总是计算总数，兼容之前的调用方式
+	if req.PageNo > 0 || req.WithTotal {
+		count, err := storage.CountTaskLog(task.ID)
+		if err != nil {
+			logger.Errorf("count task log: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "count task log")
+			return
+		}
+		ctrl.Response.TotalCount = &count
+	}
+	ctrl.Response.Data = make([]model.TaskLogItem, 0)
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.TaskLogItem{
+			ID:        item.ID,
+			Reason:    item.Reason,
+			Step:      item.Step,
+			Message:   item.Message,
+			StartTime: utils.FormatTime(item.StartAt),
+			EndTime:   utils.FormatTime(item.EndAt),
+		})
+	}
+	ctrl.Response.NextPageToken = nextPageToken
+}
+
+// getTask 根据路径参数获取项目中的任务，出错时更新响应
+func getTask(g *gin.Context, logger log.Logger, resp *model.BaseResponse) (*storage.Task, bool) {
+	taskID, err := strconv.ParseUint(g.Param("taskId"), 10, 64)
+	if err != nil {
+		resp.Update(model.CodeParamError, "invalid taskId")
+		return nil, false
+	}
+	task, err := storage.GetProjectTaskByID(g.Param("projectId"), taskID)
+	if err != nil {
+		logger.WithField("taskID", taskID).Errorf("get task: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "task not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get task")
+		}
+		return nil, false
+	}
+	return task, true
+}
+
+// formatRetryAt 格式化下次重试时间，未设置时返回空字符串
+func formatRetryAt(task *storage.Task) string {
+	if !task.RetryAt.Valid {
+		return ""
+	}
+	return utils.FormatTime(task.RetryAt.Time)
+}
+
+type CancelTaskCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     取消任务
+// @Description 取消等待执行或正在执行的任务，集群或节点进入异常状态，集群健康时由同步任务恢复为运行中
+// @Tags        Task
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string             true "项目资源ID" extensions(x-example=project-default)
+// @Param       taskId    path     int                true "任务ID"   extensions(x-example=1)
+// @Response    200       {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/tasks/{taskId}/cancel [post]
+func (ctrl *CancelTaskCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	task, ok := getTask(g, logger, &ctrl.Response)
+	if !ok {
+		return
+	}
+	if !task.Pending() {
+		ctrl.Response.Update(model.CodeForbidOperate, "task not pending")
+		return
+	}
+	cluster, node, err := getTaskTarget(task)
+	if err != nil {
+		logger.WithField("taskID", task.ID).Errorf("get task target: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get task target")
+		return
+	}
+	// 中间态的集群与节点进入异常状态
+	if cluster != nil && !isNodeAction(task.Action) && cluster.Status < storage.ClusterStatusRunning {
+		cluster.Status = storage.ClusterStatusError
+	}
+	nodes := []*storage.Node{}
+	if node != nil && node.Status < storage.NodeStatusRunning {
+		node.Status = storage.NodeStatusError
+		nodes = append(nodes, node)
+	}
+	if task.Action == storage.ClusterActionCreate && cluster != nil {
+		joining, err := listNodesByStatus(cluster.ResourceID, storage.NodeStatusJoining)
+		if err != nil {
+			logger.Errorf("list cluster nodes: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "list cluster nodes")
+			return
+		}
+		for _, n := range joining {
+			n.Status = storage.NodeStatusError
+			nodes = append(nodes, n)
+		}
+	}
+	fromStatus := task.Status
+	task.Status = storage.TaskStatusCanceled
+	taskLog := newManualTaskLog(g, ctrl.Request.RequestID, task, "task canceled manually")
+	if err := commitTaskChange(task, fromStatus, cluster, nodes, taskLog); err != nil {
+		logger.WithField("taskID", task.ID).Errorf("commit task change: %s", err)
+		if err == storage.ErrTaskNotPending {
+			ctrl.Response.Update(model.CodeForbidOperate, "task not pending")
+		} else if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithFields(log.Fields{
+		"clusterID": task.ResourceID,
+		"taskID":    task.ID,
+	}).Info("task canceled")
+	if err := storage.PublishClusterEvent(g, task.ResourceID); err != nil {
+		logger.Warnf("publish cluster event: %s", err)
+	}
+}
+
+type RetryTaskCtrl struct {
+	model.BaseController[model.BaseRequest, model.BaseResponse]
+}
+
+// @Summary     重试任务
+// @Description 重新执行集群最近一个已失败或已取消的任务，重置重试次数
+// @Tags        Task
+// @Produce     json,application/x-yaml,application/x-protobuf
+// @Param       projectId path     string             true "项目资源ID" extensions(x-example=project-default)
+// @Param       taskId    path     int                true "任务ID"   extensions(x-example=1)
+// @Response    200       {object} model.BaseResponse "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/tasks/{taskId}/retry [post]
+func (ctrl *RetryTaskCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	task, ok := getTask(g, logger, &ctrl.Response)
+	if !ok {
+		return
+	}
+	if task.Status != storage.TaskStatusFail && task.Status != storage.TaskStatusCanceled {
+		ctrl.Response.Update(model.CodeForbidOperate, "only failed or canceled task can be retried")
+		return
+	}
+	// 只允许重试集群最近的任务，避免覆盖之后的操作
+	latest, err := storage.GetLatestTaskByResourceID(task.ResourceID)
+	if err != nil {
+		logger.WithField("clusterID", task.ResourceID).Errorf("get latest task: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get latest task")
+		return
+	}
+	if latest.ID != task.ID {
+		ctrl.Response.Update(model.CodeForbidOperate, "only the latest task can be retried")
+		return
+	}
+	cluster, node, err := getTaskTarget(task)
+	if err != nil {
+		logger.WithField("taskID", task.ID).Errorf("get task target: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "get task target")
+		return
+	}
+	if cluster == nil {
+		ctrl.Response.Update(model.CodeNotExists, "cluster not found")
+		return
+	}
+	if cluster.Status < storage.ClusterStatusRunning {
+		ctrl.Response.Update(model.CodeForbidOperate, "cluster status pending")
+		return
+	}
+	// 集群与节点恢复为任务对应的中间态
+	nodes := []*storage.Node{}
+	switch task.Action {
+	case storage.ClusterActionCreate:
+		cluster.Status = storage.ClusterStatusCreating
+		failed, err := listNodesByStatus(cluster.ResourceID, storage.NodeStatusError)
+		if err != nil {
+			logger.Errorf("list cluster nodes: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "list cluster nodes")
+			return
+		}
+		for _, n := range failed {
+			n.Status = storage.NodeStatusJoining
+			nodes = append(nodes, n)
+		}
+	case storage.ClusterActionDelete:
+		cluster.Status = storage.ClusterStatusDeleting
+	case storage.ClusterActionUpgrade:
+		cluster.Status = storage.ClusterStatusUpgrading
+	case storage.NodeActionAdd, storage.NodeActionDrain, storage.NodeActionRemove:
+		if node == nil {
+			ctrl.Response.Update(model.CodeNotExists, "node not found")
+			return
+		}
+		if node.Status < storage.NodeStatusRunning {
+			ctrl.Response.Update(model.CodeForbidOperate, "node status pending")
+			return
+		}
+		switch task.Action {
+		case storage.NodeActionAdd:
+			node.Status = storage.NodeStatusJoining
+		case storage.NodeActionDrain:
+			node.Status = storage.NodeStatusDraining
+		case storage.NodeActionRemove:
+			node.Status = storage.NodeStatusRemoving
+		}
+		nodes = append(nodes, node)
+	default:
+		ctrl.Response.Update(model.CodeForbidOperate, "unsupported action: "+task.Action)
+		return
+	}
+	fromStatus := task.Status
+	task.Status = storage.TaskStatusEnqueued
+	task.RetryCount = 0
+	task.RetryAt = sql.NullTime{}
+	taskLog := newManualTaskLog(g, ctrl.Request.RequestID, task, "task retried manually")
+	if err := commitTaskChange(task, fromStatus, cluster, nodes, taskLog); err != nil {
+		logger.WithField("taskID", task.ID).Errorf("commit task change: %s", err)
+		if err == storage.ErrTaskNotPending {
+			ctrl.Response.Update(model.CodeForbidOperate, "task status changed")
+		} else if err == storage.ErrConflict {
+			ctrl.Response.Update(model.CodeConflict, "cluster modified by another request")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "commit request")
+		}
+		return
+	}
+	logger.WithFields(log.Fields{
+		"clusterID": task.ResourceID,
+		"taskID":    task.ID,
+	}).Info("task retried")
+	// 通知 daemon 立即处理任务，通知失败时由定期同步处理
+	if err := storage.NotifyCluster(g, task.ResourceID); err != nil {
+		logger.Warnf("notify cluster: %s", err)
+	}
+}
+
+// getTaskTarget 获取任务操作的集群与节点，集群已删除或节点已移除时返回 nil
+func getTaskTarget(task *storage.Task) (*storage.Cluster, *storage.Node, error) {
+	cluster, err := storage.GetProjectClusterByResourceID(task.ProjectID, task.ResourceID)
+	if err != nil {
+		if err == storage.ErrDoesNotExist {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, "get cluster")
+	}
+	if !isNodeAction(task.Action) {
+		return cluster, nil, nil
+	}
+	cfg := &storage.ClusterTaskConfig{}
+	if err := task.GetConfig(cfg); err != nil {
+		return nil, nil, errors.Wrap(err, "get task config")
+	}
+	node, err := storage.GetNodeByResourceID(cluster.ResourceID, cfg.NodeID)
+	if err != nil {
+		if err == storage.ErrDoesNotExist {
+			return cluster, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, "get node")
+	}
+	return cluster, node, nil
+}
+
+func isNodeAction(action string) bool {
+	switch action {
+	case storage.NodeActionAdd, storage.NodeActionDrain, storage.NodeActionRemove:
+		return true
+	default:
+		return false
+	}
+}
+
+// listNodesByStatus 列出集群中指定状态的节点
+func listNodesByStatus(clusterID string, status storage.NodeStatus) ([]*storage.Node, error) {
+	items, err := storage.ListClusterNodes(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*storage.Node{}
+	for i := range items {
+		if items[i].Status == status {
+			nodes = append(nodes, &items[i])
+		}
+	}
+	return nodes, nil
+}
+
+// newManualTaskLog 记录手动操作任务的日志，包含请求ID与客户端地址
+func newManualTaskLog(g *gin.Context, requestID string, task *storage.Task, message string) *storage.TaskLog {
+	now := time.Now()
+	return &storage.TaskLog{
+		TaskID:  task.ID,
+		Reason:  task.Status.String(),
+		Message: fmt.Sprintf("%s, requestID: %s, identity: %s, clientIP: %s", message, requestID, middleware.GetIdentity(g), g.ClientIP()),
+		StartAt: now,
+		EndAt:   now,
+	}
+}
+
+// commitTaskChange 在事务中更新任务、集群与节点状态并记录任务日志，任务状态已被其他请求修改时返回 ErrTaskNotPending，集群或节点已被修改时返回 ErrConflict
+func commitTaskChange(task *storage.Task, fromStatus storage.TaskStatus, cluster *storage.Cluster, nodes []*storage.Node, taskLog *storage.TaskLog) error {
+	return storage.DB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(task).
+			Where("status = ?", fromStatus).
+			Updates(map[string]interface{}{
+				"retry_count": task.RetryCount,
+				"retry_at":    task.RetryAt,
+				"status":      task.Status,
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update task")
+		}
+		if result.RowsAffected == 0 {
+			return storage.ErrTaskNotPending
+		}
+		if err := storage.CreateStatusEvents(tx, cluster, task); err != nil {
+			return err
+		}
+		if cluster != nil {
+			if err := storage.UpdateCluster(tx, cluster, map[string]interface{}{"status": cluster.Status}); err != nil {
+				return err
+			}
+		}
+		for _, node := range nodes {
+			if err := storage.UpdateNode(tx, node, map[string]interface{}{"status": node.Status}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(taskLog).Error; err != nil {
+			return errors.Wrap(err, "create task log")
+		}
+		return nil
+	})
+}
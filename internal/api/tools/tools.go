@@ -18,8 +18,11 @@ type CheckCIDRCtrl struct {
 // @Summary     CIDR网段检查
 // @Description 检查集群CIDR是否存在网段冲突
 // @Tags        Tools
+// @Accept      json,application/x-yaml,application/x-protobuf
+// @Produce     json,application/x-yaml,application/x-protobuf
 // @Param       CheckCIDRReq body     model.CheckCIDRReq  true "请求"
 // @Response    200          {object} model.CheckCIDRResp "响应"
+// @Security    BearerAuth
 // @Router      /tools/check-cidr [post]
 func (ctrl *CheckCIDRCtrl) Serve(g *gin.Context) {
 	logger := log.GetLogger(g)
@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+)
+
+type CreateWebhookCtrl struct {
+	model.BaseController[model.CreateWebhookReq, model.CreateWebhookResp]
+}
+
+// @Summary     创建 webhook
+// @Description 订阅项目中集群与任务的状态变化事件，事件以 POST 请求投递到指定地址，请求头 X-Webhook-Signature 为 sha256=hex(HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body))，投递失败时按指数退避重试
+// @Tags        Webhook
+// @Param       projectId        path     string                  true "项目资源ID" extensions(x-example=project-default)
+// @Param       CreateWebhookReq body     model.CreateWebhookReq  true "请求"
+// @Response    200              {object} model.CreateWebhookResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/webhooks [post]
+func (ctrl *CreateWebhookCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := &ctrl.Request
+	// 检查参数
+	if err := checkCreateWebhookReq(g, req); err != nil {
+		logger.Errorf("check request: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	// 获取项目
+	projectID := g.Param("projectId")
+	project, err := storage.GetProjectByResourceID(projectID)
+	if err != nil {
+		logger.WithField("projectID", projectID).Errorf("get project: %s", err)
+		if err == storage.ErrDoesNotExist {
+			ctrl.Response.Update(model.CodeNotExists, "project not found")
+		} else {
+			ctrl.Response.Update(model.CodeInternalError, "get project")
+		}
+		return
+	}
+	// 生成 webhook 资源ID
+	resourceID, err := storage.GenerateWebhookResourceID()
+	if err != nil {
+		logger.Errorf("generate resourceID: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "generate resourceID")
+		return
+	}
+	webhook := &storage.Webhook{
+		ResourceID: resourceID.ResourceID,
+		ProjectID:  project.ResourceID,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Events:     datatypes.JSONType[[]storage.WebhookEventType]{Data: req.Events},
+	}
+	if err := storage.DB().Create(webhook).Error; err != nil {
+		logger.Errorf("create webhook: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "create webhook")
+		return
+	}
+	ctrl.Response.Data = webhook.ResourceID
+}
+
+// checkCreateWebhookReq 检查投递地址与事件类型，投递地址只能解析到公网地址，投递时会再次检查
+func checkCreateWebhookReq(ctx context.Context, req *model.CreateWebhookReq) error {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return errors.Wrap(err, "invalid url")
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Hostname()) == 0 {
+		return errors.New("invalid url, only http and https are supported")
+	}
+	if err := utils.CheckPublicHost(ctx, u.Hostname()); err != nil {
+		return errors.Wrap(err, "invalid url")
+	}
+	for _, item := range req.Events {
+		if !slices.Contains(storage.WebhookEventTypes, item) {
+			return errors.Errorf("unsupported event type %s", item)
+		}
+	}
+	// 去除重复的事件类型
+	slices.Sort(req.Events)
+	req.Events = slices.Compact(req.Events)
+	return nil
+}
+
+type ListWebhookCtrl struct {
+	model.BaseController[model.ListWebhookReq, model.ListWebhookResp]
+}
+
+// @Summary     webhook 列表
+// @Description 获取项目的 webhook 列表，不返回签名密钥
+// @Tags        Webhook
+// @Param       projectId path     string                true "项目资源ID" extensions(x-example=project-default)
+// @Response    200       {object} model.ListWebhookResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/webhooks [get]
+func (ctrl *ListWebhookCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	items, err := storage.ListWebhook(g.Param("projectId"))
+	if err != nil {
+		logger.Errorf("list webhook: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list webhook")
+		return
+	}
+	ctrl.Response.Data = make([]model.WebhookSummary, 0, len(items))
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.WebhookSummary{
+			ResourceID: item.ResourceID,
+			URL:        item.URL,
+			Events:     item.Events.Data,
+			CreateTime: utils.FormatTime(item.CreatedAt),
+		})
+	}
+}
+
+type DeleteWebhookCtrl struct {
+	model.BaseController[model.DeleteWebhookReq, model.DeleteWebhookResp]
+}
+
+// @Summary     删除 webhook
+// @Description 删除 webhook 与 webhook 的投递记录，等待投递的事件不再投递
+// @Tags        Webhook
+// @Param       projectId path     string                  true "项目资源ID"       extensions(x-example=project-default)
+// @Param       webhookId path     string                  true "webhook 资源ID" extensions(x-example=webhook-k2jd8sm1qa)
+// @Response    200       {object} model.DeleteWebhookResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/webhooks/{webhookId} [delete]
+func (ctrl *DeleteWebhookCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	webhook, ok := getWebhook(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	if err := storage.DeleteWebhook(webhook); err != nil {
+		logger.Errorf("delete webhook: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "delete webhook")
+		return
+	}
+}
+
+type ListWebhookDeliveryCtrl struct {
+	model.BaseController[model.ListWebhookDeliveryReq, model.ListWebhookDeliveryResp]
+}
+
+// @Summary     webhook 投递记录
+// @Description 分页获取 webhook 的投递记录，最新的记录在前
+// @Tags        Webhook
+// @Param       projectId path     string                        true  "项目资源ID"                             extensions(x-example=project-default)
+// @Param       webhookId path     string                        true  "webhook 资源ID"                       extensions(x-example=webhook-k2jd8sm1qa)
+// @Param       pageNo    query    int                           false "分页号，不能与 pageToken 同时使用，使用页码分页时返回总数" extensions(x-example=1)
+// @Param       pageSize  query    int                           true  "分页大小，默认为10"                         extensions(x-example=10)
+// @Param       pageToken query    string                        false "分页令牌，使用上一页返回的 nextPageToken 获取下一页"
+// @Param       withTotal query    bool                          false "是否返回总数，使用页码分页时总是返回"
+// @Response    200       {object} model.ListWebhookDeliveryResp "响应"
+// @Security    BearerAuth
+// @Router      /projects/{projectId}/webhooks/{webhookId}/deliveries [get]
+func (ctrl *ListWebhookDeliveryCtrl) Serve(g *gin.Context) {
+	logger := log.GetLogger(g)
+	req := ctrl.Request
+	webhook, ok := getWebhook(g, logger, &ctrl.Response.BaseResponse)
+	if !ok {
+		return
+	}
+	query := &storage.ListQuery{SortOrder: storage.SortOrderDesc}
+	// 分页令牌只能用于同一个 webhook 的查询
+	query.Scope = utils.QueryScope(g.Request.URL, model.PageParams...)
+	if err := query.SetPage(req.PageNo, req.PageSize, req.PageToken); err != nil {
+		logger.Errorf("set page: %s", err)
+		ctrl.Response.Update(model.CodeParamError, err.Error())
+		return
+	}
+	items, nextPageToken, err := storage.ListWebhookDelivery(webhook.ResourceID, query)
+	if err != nil {
+		logger.Errorf("list webhook delivery: %s", err)
+		ctrl.Response.Update(model.CodeInternalError, "list webhook delivery")
+		return
+	}
+	// 使用页码分页时总是计算总数
+	if req.PageNo > 0 || req.WithTotal {
+		count, err := storage.CountWebhookDelivery(webhook.ResourceID)
+		if err != nil {
+			logger.Errorf("count webhook delivery: %s", err)
+			ctrl.Response.Update(model.CodeInternalError, "count webhook delivery")
+			return
+		}
+		ctrl.Response.TotalCount = &count
+	}
+	ctrl.Response.Data = make([]model.WebhookDelivery, 0, len(items))
+	for _, item := range items {
+		ctrl.Response.Data = append(ctrl.Response.Data, model.WebhookDelivery{
+			ID:              item.ID,
+			EventID:         item.EventID,
+			EventType:       item.EventType,
+			Status:          item.Status.String(),
+			Attempts:        item.Attempts,
+			NextAttemptTime: utils.FormatTime(item.NextAttemptAt),
+			LastError:       item.LastError,
+			CreateTime:      utils.FormatTime(item.CreatedAt),
+			UpdateTime:      utils.FormatTime(item.UpdatedAt),
+		})
+	}
+	ctrl.Response.NextPageToken = nextPageToken
+}
+
+// getWebhook 获取项目的 webhook，出错时更新响应
+func getWebhook(g *gin.Context, logger log.Logger, resp *model.BaseResponse) (*storage.Webhook, bool) {
+	webhookID := g.Param("webhookId")
+	webhook, err := storage.GetProjectWebhook(g.Param("projectId"), webhookID)
+	if err != nil {
+		logger.WithField("webhookID", webhookID).Errorf("get webhook: %s", err)
+		if err == storage.ErrDoesNotExist {
+			resp.Update(model.CodeNotExists, "webhook not found")
+		} else {
+			resp.Update(model.CodeInternalError, "get webhook")
+		}
+		return nil, false
+	}
+	return webhook, true
+}
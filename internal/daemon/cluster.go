@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
@@ -19,6 +20,9 @@ func clusterExecutorProducer(ctx context.Context) ([]string, error) {
 	return resourceIDs, nil
 }
 
+// conflictRetryDelay 集群被并发修改导致状态保存失败时的重试间隔
+const conflictRetryDelay = time.Second
+
 func clusterExecutorConsumer(ctx context.Context, resourceID string) (time.Duration, error) {
 	// 获取集群
 	cluster, err := storage.GetClusterByResourceID(resourceID)
@@ -35,7 +39,11 @@ func clusterExecutorConsumer(ctx context.Context, resourceID string) (time.Durat
 	}
 	// 任务处理
 	if task != nil {
-		// 任务存在，执行任务，出错时重试
+		// 未到重试时间，延迟到重试时间后执行，重启或切换主节点后同样生效
+		if wait := task.RetryWait(); wait > 0 {
+			return wait, nil
+		}
+		// 任务存在，执行任务，失败时按重试策略在重试时间重新加入队列
 		logger := log.G(ctx).WithFields(log.Fields{
 			"taskID":    task.ID,
 			"clusterID": task.ResourceID,
@@ -43,8 +51,17 @@ func clusterExecutorConsumer(ctx context.Context, resourceID string) (time.Durat
 			"action":    task.Action,
 		})
 		if err := handleClusterTask(log.S(ctx, logger), logger, cluster, task); err != nil {
+			// 集群在执行期间被修改，重新读取集群后保存状态，已完成的步骤不会重复执行
+			if errors.Is(err, storage.ErrConflict) {
+				logger.Warnf("handle cluster task: %s, retry after %s", err, conflictRetryDelay)
+				return conflictRetryDelay, nil
+			}
+			// 任务状态未能保存，由生产者重新加入队列
 			logger.Errorf("handle cluster task: %s", err)
-			return task.NextRetryDuration(), nil
+			return queue.NextDurationNone, nil
+		}
+		if task.Status == storage.TaskStatusRetrying {
+			return task.RetryWait(), nil
 		}
 	} else {
 		// 任务不存在，执行同步
@@ -61,9 +78,289 @@ func clusterExecutorConsumer(ctx context.Context, resourceID string) (time.Durat
 }
 
 func handleClusterTask(ctx context.Context, logger log.Logger, cluster *storage.Cluster, task *storage.Task) error {
+	startAt := time.Now()
+	cfg := &storage.ClusterTaskConfig{}
+	if err := task.GetConfig(cfg); err != nil {
+		return errors.Wrap(err, "get task config")
+	}
+	nodes, err := storage.ListClusterNodes(cluster.ResourceID)
+	if err != nil {
+		return errors.Wrap(err, "list cluster nodes")
+	}
+	// 任务被取消时中断执行
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go watchTaskCanceled(runCtx, logger, cancel, task.ID)
+	// 按步骤执行任务，重试时从上次失败的步骤继续
+	steps, err := buildClusterSteps(cluster, task, cfg, nodes)
+	if err == nil {
+		err = runSteps(runCtx, logger, task, cfg, steps)
+	}
+	taskLog := &storage.TaskLog{
+		TaskID:  task.ID,
+		StartAt: startAt,
+		EndAt:   time.Now(),
+	}
+	var stepErr *stepError
+	if errors.As(err, &stepErr) {
+		taskLog.Step = stepErr.step
+		taskLog.StartAt = stepErr.startAt
+	}
+	var changedNodes []*storage.Node
+	if err == nil {
+		// 执行成功，更新集群、节点与任务状态，节点任务不改变集群状态
+		switch task.Action {
+		case storage.ClusterActionCreate:
+			cluster.Status = storage.ClusterStatusRunning
+		case storage.ClusterActionDelete:
+			cluster.Status = storage.ClusterStatusDeleted
+		case storage.ClusterActionUpgrade:
+			cluster.Status = storage.ClusterStatusRunning
+			cluster.Version = cfg.Version
+		}
+		changedNodes = changeNodeStatus(nodes, task, cfg, true)
+		task.Status = storage.TaskStatusSuccess
+		taskLog.Reason = task.Status.String()
+		taskLog.Message = task.Action + " succeeded"
+	} else {
+		// 执行失败，超过重试次数时任务失败，集群或节点进入异常状态，否则记录重试时间，到达后重新执行
+		retryAfter := task.NextRetryDuration()
+		task.RetryCount++
+		if task.RetryCount >= task.RetryLimit {
+			cluster.Status = failedClusterStatus(ctx, logger, cluster, task)
+			changedNodes = changeNodeStatus(nodes, task, cfg, false)
+			task.Status = storage.TaskStatusFail
+			task.RetryAt = sql.NullTime{}
+		} else {
+			task.Status = storage.TaskStatusRetrying
+			task.RetryAt = sql.NullTime{Time: time.Now().Add(retryAfter), Valid: true}
+		}
+		taskLog.Reason = task.Status.String()
+		taskLog.Message = err.Error()
+	}
+	var updateErr error
+	if task.Status == storage.TaskStatusRetrying {
+		// 重试时集群与节点状态不变，只更新任务
+		updateErr = storage.UpdateTaskStatus(task)
+	} else {
+		updateErr = storage.UpdateClusterAndTaskStatus(cluster, task, changedNodes...)
+	}
+	if updateErr != nil {
+		if updateErr == storage.ErrTaskNotPending {
+			// 任务已被取消，集群与节点状态由取消操作更新
+			logger.Warn("task canceled, skip updating status")
+			return nil
+		}
+		return errors.Wrap(updateErr, "update task status")
+	}
+	if err := storage.CreateTaskLog(taskLog); err != nil {
+		logger.Errorf("create task log: %s", err)
+	}
+	publishClusterEvent(ctx, logger, task.ResourceID)
+	if err != nil {
+		fields := log.Fields{"status": task.Status.String(), "retryCount": task.RetryCount}
+		if task.RetryAt.Valid {
+			fields["retryAt"] = utils.FormatTime(task.RetryAt.Time)
+		}
+		logger.WithFields(fields).Errorf("cluster task failed: %s", err)
+		return nil
+	}
+	logger.WithField("status", cluster.Status.String()).Info("cluster task succeeded")
 	return nil
 }
 
+// taskCancelCheckInterval 检查任务是否被取消的间隔
+const taskCancelCheckInterval = time.Second * 5
+
+// watchTaskCanceled 定期检查任务状态，任务被取消时调用 cancel 中断执行
+func watchTaskCanceled(ctx context.Context, logger log.Logger, cancel context.CancelFunc, taskID uint64) {
+	ticker := time.NewTicker(taskCancelCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			task, err := storage.GetTaskByID(taskID)
+			if err != nil {
+				logger.Warnf("get task: %s", err)
+				continue
+			}
+			if task.Status == storage.TaskStatusCanceled {
+				logger.Info("task canceled, interrupt running")
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// failedClusterStatus 任务失败后集群的状态，升级失败时根据集群健康状态回滚到运行中或异常，节点任务失败不影响集群状态
+func failedClusterStatus(ctx context.Context, logger log.Logger, cluster *storage.Cluster, task *storage.Task) storage.ClusterStatus {
+	switch task.Action {
+	case storage.ClusterActionUpgrade:
+		if err := checkCluster(ctx, cluster); err != nil {
+			logger.Warnf("check cluster after upgrade failed: %s", err)
+			return storage.ClusterStatusError
+		}
+		return storage.ClusterStatusRunning
+	case storage.NodeActionAdd, storage.NodeActionDrain, storage.NodeActionRemove:
+		return cluster.Status
+	default:
+		return storage.ClusterStatusError
+	}
+}
+
+// changeNodeStatus 根据任务结果修改节点状态，返回状态发生变化的节点
+func changeNodeStatus(nodes []storage.Node, task *storage.Task, cfg *storage.ClusterTaskConfig, succeeded bool) []*storage.Node {
+	changed := []*storage.Node{}
+	for i := range nodes {
+		n := &nodes[i]
+		status := n.Status
+		switch task.Action {
+		case storage.ClusterActionCreate:
+			if n.Status != storage.NodeStatusJoining {
+				continue
+			}
+			status = storage.NodeStatusRunning
+			if !succeeded {
+				status = storage.NodeStatusError
+			}
+		case storage.ClusterActionDelete:
+			if succeeded {
+				status = storage.NodeStatusRemoved
+			}
+		case storage.NodeActionAdd, storage.NodeActionDrain, storage.NodeActionRemove:
+			if n.ResourceID != cfg.NodeID {
+				continue
+			}
+			switch {
+			case !succeeded:
+				status = storage.NodeStatusError
+			case task.Action == storage.NodeActionAdd:
+				status = storage.NodeStatusRunning
+			case task.Action == storage.NodeActionDrain:
+				status = storage.NodeStatusDrained
+			case task.Action == storage.NodeActionRemove:
+				status = storage.NodeStatusRemoved
+			}
+		}
+		if status != n.Status {
+			n.Status = status
+			changed = append(changed, n)
+		}
+	}
+	return changed
+}
+
+// checkCluster 调用集群供应器检查集群健康状态
+func checkCluster(ctx context.Context, cluster *storage.Cluster) error {
+	p, err := getProvisioner(cluster.Type)
+	if err != nil {
+		return err
+	}
+	nodes, err := storage.ListClusterNodes(cluster.ResourceID)
+	if err != nil {
+		return errors.Wrap(err, "list cluster nodes")
+	}
+	hosts, err := getClusterHosts(memberNodes(nodes))
+	if err != nil {
+		return errors.Wrap(err, "get cluster hosts")
+	}
+	return p.Check(ctx, cluster, hosts)
+}
+
+// buildClusterSteps 调用集群供应器生成任务步骤
+func buildClusterSteps(cluster *storage.Cluster, task *storage.Task, cfg *storage.ClusterTaskConfig, nodes []storage.Node) ([]Step, error) {
+	p, err := getProvisioner(cluster.Type)
+	if err != nil {
+		return nil, err
+	}
+	switch task.Action {
+	case storage.ClusterActionCreate:
+		hosts, err := getClusterHosts(filterNodes(nodes, storage.NodeStatusJoining))
+		if err != nil {
+			return nil, errors.Wrap(err, "get cluster hosts")
+		}
+		return p.Create(cluster, hosts)
+	case storage.ClusterActionDelete:
+		hosts, err := getClusterHosts(nodes)
+		if err != nil {
+			return nil, errors.Wrap(err, "get cluster hosts")
+		}
+		return p.Delete(cluster, hosts)
+	case storage.ClusterActionUpgrade:
+		hosts, err := getClusterHosts(memberNodes(nodes))
+		if err != nil {
+			return nil, errors.Wrap(err, "get cluster hosts")
+		}
+		return p.Upgrade(cluster, hosts, cfg.Version)
+	case storage.NodeActionAdd, storage.NodeActionDrain, storage.NodeActionRemove:
+		return buildNodeSteps(p, cluster, task, cfg, nodes)
+	default:
+		return nil, errors.Errorf("unsupported action: %s", task.Action)
+	}
+}
+
+// buildNodeSteps 调用集群供应器生成节点任务步骤
+func buildNodeSteps(p Provisioner, cluster *storage.Cluster, task *storage.Task, cfg *storage.ClusterTaskConfig, nodes []storage.Node) ([]Step, error) {
+	hosts, err := getClusterHosts(memberNodes(nodes))
+	if err != nil {
+		return nil, errors.Wrap(err, "get cluster hosts")
+	}
+	targets := []storage.Node{}
+	for _, n := range nodes {
+		if n.ResourceID == cfg.NodeID {
+			targets = append(targets, n)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, errors.Errorf("node not found: %s", cfg.NodeID)
+	}
+	nodeHosts, err := getClusterHosts(targets)
+	if err != nil {
+		return nil, errors.Wrap(err, "get node host")
+	}
+	switch task.Action {
+	case storage.NodeActionAdd:
+		return p.JoinNode(cluster, hosts, nodeHosts[0])
+	case storage.NodeActionDrain:
+		return p.DrainNode(cluster, hosts, nodeHosts[0])
+	default:
+		return p.RemoveNode(cluster, hosts, nodeHosts[0])
+	}
+}
+
 func handleClusterSynchronization(ctx context.Context, logger log.Logger, cluster *storage.Cluster) error {
+	// 只同步稳态集群，运行中的集群检查失败时转为异常，异常集群检查通过时恢复为运行中
+	if cluster.Status != storage.ClusterStatusRunning && cluster.Status != storage.ClusterStatusError {
+		return nil
+	}
+	status := storage.ClusterStatusRunning
+	if err := checkCluster(ctx, cluster); err != nil {
+		logger.Warnf("check cluster: %s", err)
+		status = storage.ClusterStatusError
+	}
+	if status == cluster.Status {
+		return nil
+	}
+	logger.Infof("cluster status changed: %s -> %s", cluster.Status, status)
+	cluster.Status = status
+	if err := storage.UpdateClusterStatus(cluster); err != nil {
+		if err == storage.ErrConflict {
+			// 集群在检查期间被修改，例如提交了删除任务，放弃本次同步
+			logger.Warn("cluster modified during sync, skip updating status")
+			return nil
+		}
+		return errors.Wrap(err, "update cluster status")
+	}
+	publishClusterEvent(ctx, logger, cluster.ResourceID)
 	return nil
 }
+
+// publishClusterEvent 通知事件流集群有变化，失败时由事件流的定期检查兜底
+func publishClusterEvent(ctx context.Context, logger log.Logger, resourceID string) {
+	if err := storage.PublishClusterEvent(ctx, resourceID); err != nil {
+		logger.Warnf("publish cluster event: %s", err)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/leaderelection"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/metrics"
 	"gitbub.com/wbuntu/gin-template/internal/pkg/queue"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/rest"
 )
@@ -17,12 +19,21 @@ type Server struct {
 	cancel               context.CancelFunc
 	logger               log.Logger
 	enableLeaderElection bool
+	resyncInterval       time.Duration
+	retention            time.Duration
 }
 
 func (s *Server) Setup(ctx context.Context, cfg *config.Config) error {
 	s.ctx, s.cancel = context.WithCancel(ctx)
 	s.logger = log.WithField("module", "daemon")
 	s.enableLeaderElection = cfg.General.EnableLeaderElection
+	s.resyncInterval = cfg.Daemon.ResyncInterval
+	s.retention = cfg.Daemon.Retention
+	if err := setupProvisioners(cfg.Daemon.Provisioner); err != nil {
+		return errors.Wrap(err, "setup provisioners")
+	}
+	// 任务与集群数量指标
+	metrics.MustRegister(newStorageCollector(s.logger.WithField("job", "metrics")))
 	if s.enableLeaderElection {
 		// 使用kube-apiserver做选举
 		cfg, err := rest.InClusterConfig()
@@ -60,20 +71,28 @@ func (s *Server) Shutdown() error {
 func (s *Server) onStartedLeading(ctx context.Context) {
 	// 使用传入的ctx启动工作队列，在选举失败时会自动cancel
 	s.logger.WithField("job", "leader_election").Info("start leading")
-	// 创建一个延迟对接并启动
+	metrics.SetLeader(true)
+	// 创建一个延迟对接并启动，生产者定期全量同步，作为通知丢失时的兜底
 	delayQueue := queue.NewDeleyQueue(
 		log.S(ctx, s.logger.WithField("job", "cluster_executor")),
 		"cluster_executor",
-		time.Second*10,
+		s.resyncInterval,
 		32,
 		clusterExecutorProducer,
 		clusterExecutorConsumer,
 	)
 	go delayQueue.Run()
+	// 接收集群变更通知，立即加入队列
+	go storage.WatchCluster(log.S(ctx, s.logger.WithField("job", "cluster_notify")), delayQueue.Add)
+	// 分发并投递 webhook 事件
+	go runWebhookDispatcher(log.S(ctx, s.logger.WithField("job", "webhook")))
+	// 定期清理过期数据
+	go runPurge(log.S(ctx, s.logger.WithField("job", "purge")), s.retention)
 }
 
 func (s *Server) onStoppedLeading() {
 	s.logger.WithField("job", "leader_election").Info("stop leading")
+	metrics.SetLeader(false)
 }
 
 func (s *Server) onNewLeader(identity string) {
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// storageCollector 在采集时从数据库统计任务与集群数量
+type storageCollector struct {
+	logger      log.Logger
+	taskDesc    *prometheus.Desc
+	clusterDesc *prometheus.Desc
+}
+
+func newStorageCollector(logger log.Logger) *storageCollector {
+	return &storageCollector{
+		logger: logger,
+		taskDesc: prometheus.NewDesc(
+			"gin_template_tasks",
+			"Number of tasks by action and status.",
+			[]string{"action", "status"}, nil,
+		),
+		clusterDesc: prometheus.NewDesc(
+			"gin_template_clusters",
+			"Number of clusters by status.",
+			[]string{"status"}, nil,
+		),
+	}
+}
+
+func (c *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.taskDesc
+	ch <- c.clusterDesc
+}
+
+func (c *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	tasks, err := storage.CountTaskGroupByActionAndStatus()
+	if err != nil {
+		c.logger.Errorf("count tasks: %s", err)
+	}
+	for _, item := range tasks {
+		ch <- prometheus.MustNewConstMetric(c.taskDesc, prometheus.GaugeValue, float64(item.Count), item.Action, item.Status.String())
+	}
+	clusters, err := storage.CountClusterGroupByStatus()
+	if err != nil {
+		c.logger.Errorf("count clusters: %s", err)
+	}
+	for _, item := range clusters {
+		ch <- prometheus.MustNewConstMetric(c.clusterDesc, prometheus.GaugeValue, float64(item.Count), item.Status.String())
+	}
+}
@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// Provisioner 集群供应器，每种 k8s 类型对应一个实现，集群操作被拆分为按顺序执行的步骤
+type Provisioner interface {
+	// Create 在主机上部署集群
+	Create(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error)
+	// Delete 清理主机上的集群
+	Delete(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error)
+	// Upgrade 滚动升级集群，先升级控制面节点，再逐个升级工作节点
+	Upgrade(cluster *storage.Cluster, hosts []storage.ClusterHost, version string) ([]Step, error)
+	// JoinNode 将节点加入集群，hosts 为集群中已有的主机
+	JoinNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error)
+	// DrainNode 驱逐节点上的容器组并停止调度
+	DrainNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error)
+	// RemoveNode 驱逐节点后将节点从集群中移除并清理主机
+	RemoveNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error)
+	// Check 检查集群是否健康
+	Check(ctx context.Context, cluster *storage.Cluster, hosts []storage.ClusterHost) error
+}
+
+const (
+	ProvisionerSSH  = "ssh"
+	ProvisionerFake = "fake"
+)
+
+// provisioners 按 k8s 类型注册的集群供应器
+var provisioners = map[storage.K8sType]Provisioner{}
+
+// setupProvisioners 根据配置注册集群供应器
+func setupProvisioners(name string) error {
+	switch name {
+	case ProvisionerSSH:
+		provisioners[storage.K8sTypeK8s] = &kubeadmProvisioner{}
+		provisioners[storage.K8sTypeK3s] = &k3sProvisioner{}
+	case ProvisionerFake:
+		p := newFakeProvisioner()
+		provisioners[storage.K8sTypeK8s] = p
+		provisioners[storage.K8sTypeK3s] = p
+	default:
+		return errors.Errorf("unsupported provisioner: %s", name)
+	}
+	return nil
+}
+
+func getProvisioner(t storage.K8sType) (Provisioner, error) {
+	p, ok := provisioners[t]
+	if !ok {
+		return nil, errors.Errorf("unsupported k8s type: %s", t)
+	}
+	return p, nil
+}
+
+// getClusterHosts 组合节点与凭据得到集群主机，保持节点的顺序
+func getClusterHosts(nodes []storage.Node) ([]storage.ClusterHost, error) {
+	credentialIDs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		credentialIDs = append(credentialIDs, n.CredentialID)
+	}
+	credentials, err := storage.ListCredentialByResourceIDs(credentialIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "list credentials")
+	}
+	passwords := make(map[string]string, len(credentials))
+	for _, c := range credentials {
+		passwords[c.ResourceID] = c.Password
+	}
+	hosts := make([]storage.ClusterHost, 0, len(nodes))
+	for _, n := range nodes {
+		password, ok := passwords[n.CredentialID]
+		if !ok {
+			return nil, errors.Errorf("credential not found for node %s", n.ResourceID)
+		}
+		hosts = append(hosts, storage.ClusterHost{
+			NodeID:   n.ResourceID,
+			IP:       n.IP,
+			Port:     n.SSHPort,
+			Password: password,
+			Role:     n.Role,
+			Labels:   n.Labels.Data,
+			Taints:   n.Taints.Data,
+		})
+	}
+	return hosts, nil
+}
+
+// filterNodes 按状态过滤节点
+func filterNodes(nodes []storage.Node, statuses ...storage.NodeStatus) []storage.Node {
+	items := []storage.Node{}
+	for _, n := range nodes {
+		for _, s := range statuses {
+			if n.Status == s {
+				items = append(items, n)
+				break
+			}
+		}
+	}
+	return items
+}
+
+// memberNodes 已经加入集群的节点
+func memberNodes(nodes []storage.Node) []storage.Node {
+	return filterNodes(nodes,
+		storage.NodeStatusRunning,
+		storage.NodeStatusDraining,
+		storage.NodeStatusDrained,
+		storage.NodeStatusRemoving,
+	)
+}
+
+// splitHosts 按角色拆分主机，第一个控制面节点用于初始化集群
+func splitHosts(hosts []storage.ClusterHost) (masters []storage.ClusterHost, workers []storage.ClusterHost, err error) {
+	for _, h := range hosts {
+		switch h.Role {
+		case storage.HostRoleMaster:
+			masters = append(masters, h)
+		case storage.HostRoleWorker:
+			workers = append(workers, h)
+		default:
+			return nil, nil, errors.Errorf("unsupported host role: %s: %s", h.IP, h.Role)
+		}
+	}
+	if len(masters) == 0 {
+		return nil, nil, errors.New("no master host")
+	}
+	return masters, workers, nil
+}
+
+// runOnHost 通过 SSH 在主机上依次执行命令，返回最后一条命令的输出
+// 命令中可能包含加入令牌与证书密钥，日志与错误中只记录主机与命令的序号，错误会保存到任务日志中
+func runOnHost(ctx context.Context, host storage.ClusterHost, commands ...string) (string, error) {
+	var output string
+	for i, command := range commands {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+		log.G(ctx).WithField("host", host.IP).Debugf("run command %d/%d", i+1, len(commands))
+		out, err := utils.ExeSshCmdContext(ctx, host.IP, host.Port, host.Password, command)
+		if err != nil {
+			return "", errors.Wrapf(err, "host %s: command %d/%d", host.IP, i+1, len(commands))
+		}
+		output = out
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// getNodeName 获取主机在集群中的节点名称
+func getNodeName(ctx context.Context, host storage.ClusterHost) (string, error) {
+	nodeName, err := runOnHost(ctx, host, "hostname")
+	if err != nil {
+		return "", errors.Wrap(err, "get node name")
+	}
+	return nodeName, nil
+}
+
+// labelAndTaintCommands 为节点设置标签与污点的命令，kubectl 为可执行的 kubectl 命令前缀，每个参数都需要转义
+func labelAndTaintCommands(kubectl string, nodeName string, host storage.ClusterHost) []string {
+	commands := []string{}
+	if len(host.Labels) > 0 {
+		keys := make([]string, 0, len(host.Labels))
+		for k := range host.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		labels := make([]string, 0, len(keys))
+		for _, k := range keys {
+			labels = append(labels, utils.ShellQuote(fmt.Sprintf("%s=%s", k, host.Labels[k])))
+		}
+		commands = append(commands, fmt.Sprintf("%s label node %s --overwrite %s", kubectl, utils.ShellQuote(nodeName), strings.Join(labels, " ")))
+	}
+	if len(host.Taints) > 0 {
+		taints := make([]string, 0, len(host.Taints))
+		for _, t := range host.Taints {
+			if t.Value != "" {
+				taints = append(taints, utils.ShellQuote(fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)))
+			} else {
+				taints = append(taints, utils.ShellQuote(fmt.Sprintf("%s:%s", t.Key, t.Effect)))
+			}
+		}
+		commands = append(commands, fmt.Sprintf("%s taint node %s --overwrite %s", kubectl, utils.ShellQuote(nodeName), strings.Join(taints, " ")))
+	}
+	return commands
+}
+
+// runOnHosts 在多台主机上依次执行相同的命令
+func runOnHosts(ctx context.Context, hosts []storage.ClusterHost, commands ...string) error {
+	for _, h := range hosts {
+		if _, err := runOnHost(ctx, h, commands...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// fakeStepDelay 模拟每个步骤的耗时
+const fakeStepDelay = time.Second
+
+// fakeProvisioner 在内存中模拟集群的生命周期，不连接任何主机，用于本地调试与 e2e 测试
+type fakeProvisioner struct {
+	mu sync.Mutex
+	// 集群资源ID -> 是否存在
+	clusters map[string]bool
+}
+
+func newFakeProvisioner() *fakeProvisioner {
+	return &fakeProvisioner{
+		clusters: map[string]bool{},
+	}
+}
+
+func (p *fakeProvisioner) Create(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	if _, _, err := splitHosts(hosts); err != nil {
+		return nil, err
+	}
+	return []Step{
+		newStep("prepare hosts", p.sleep),
+		newStep("init control plane", p.sleep),
+		newStep("join nodes", func(ctx context.Context) error {
+			if err := p.sleep(ctx); err != nil {
+				return err
+			}
+			p.setCluster(cluster.ResourceID, true)
+			return nil
+		}),
+	}, nil
+}
+
+func (p *fakeProvisioner) Delete(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	return []Step{
+		newStep("reset hosts", func(ctx context.Context) error {
+			if err := p.sleep(ctx); err != nil {
+				return err
+			}
+			p.setCluster(cluster.ResourceID, false)
+			return nil
+		}),
+	}, nil
+}
+
+func (p *fakeProvisioner) Upgrade(cluster *storage.Cluster, hosts []storage.ClusterHost, version string) ([]Step, error) {
+	steps := []Step{}
+	for _, h := range hosts {
+		steps = append(steps, newStep("upgrade "+h.IP, p.sleep))
+	}
+	return steps, nil
+}
+
+func (p *fakeProvisioner) Check(ctx context.Context, cluster *storage.Cluster, hosts []storage.ClusterHost) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// 进程重启后内存状态丢失，未记录的集群视为仍然存在
+	if exists, ok := p.clusters[cluster.ResourceID]; ok && !exists {
+		return errors.Errorf("cluster not found: %s", cluster.ResourceID)
+	}
+	return nil
+}
+
+func (p *fakeProvisioner) JoinNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	return []Step{
+		newStep("prepare node", p.sleep),
+		newStep("join node", p.sleep),
+	}, nil
+}
+
+func (p *fakeProvisioner) DrainNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	return []Step{newStep("drain node", p.sleep)}, nil
+}
+
+func (p *fakeProvisioner) RemoveNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	return []Step{
+		newStep("delete node", p.sleep),
+		newStep("reset node", p.sleep),
+	}, nil
+}
+
+func (p *fakeProvisioner) setCluster(resourceID string, exists bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clusters[resourceID] = exists
+}
+
+func (p *fakeProvisioner) sleep(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(fakeStepDelay):
+		return nil
+	}
+}
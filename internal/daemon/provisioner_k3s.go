@@ -0,0 +1,238 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	k3sInstallURL = "https://get.k3s.io"
+	k3sTokenPath  = "/var/lib/rancher/k3s/server/node-token"
+)
+
+// k3sProvisioner 使用 k3s 安装脚本部署 k3s 集群
+type k3sProvisioner struct{}
+
+func (p *k3sProvisioner) Create(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	masters, workers, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	// k3s 内置 containerd
+	if cluster.Runtime != "containerd" {
+		return nil, errors.Errorf("unsupported runtime for k3s: %s", cluster.Runtime)
+	}
+	version := k3sVersion(cluster.Version)
+	first := masters[0]
+	steps := []Step{
+		// 1. 使用第一个控制面节点初始化集群，安装脚本可以重复执行
+		commandStep("init server", []storage.ClusterHost{first}, k3sServerCommand(version, "", "")),
+	}
+	// 2. 加入其他控制面节点
+	if len(masters) > 1 {
+		steps = append(steps, newStep("join servers", func(ctx context.Context) error {
+			token, err := k3sToken(ctx, first)
+			if err != nil {
+				return err
+			}
+			return runOnHosts(ctx, masters[1:], k3sServerCommand(version, token, first.IP))
+		}))
+	}
+	// 3. 加入工作节点
+	if len(workers) > 0 {
+		steps = append(steps, newStep("join agents", func(ctx context.Context) error {
+			token, err := k3sToken(ctx, first)
+			if err != nil {
+				return err
+			}
+			return runOnHosts(ctx, workers, k3sAgentCommand(version, token, first.IP))
+		}))
+	}
+	return steps, nil
+}
+
+func (p *k3sProvisioner) Delete(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	masters, workers, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return []Step{
+		commandStep("uninstall agents", workers, k3sUninstallCommand(storage.HostRoleWorker)),
+		commandStep("uninstall servers", masters, k3sUninstallCommand(storage.HostRoleMaster)),
+	}, nil
+}
+
+func (p *k3sProvisioner) Upgrade(cluster *storage.Cluster, hosts []storage.ClusterHost, version string) ([]Step, error) {
+	masters, workers, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	// 使用目标版本重新执行安装脚本，参数与部署时保持一致
+	target := k3sVersion(version)
+	first := masters[0]
+	// 1. 逐个升级控制面
+	steps := []Step{
+		commandStep("upgrade server "+first.IP, []storage.ClusterHost{first}, k3sServerCommand(target, "", "")),
+	}
+	for _, h := range masters[1:] {
+		h := h
+		steps = append(steps, newStep("upgrade server "+h.IP, func(ctx context.Context) error {
+			token, err := k3sToken(ctx, first)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, h, k3sServerCommand(target, token, first.IP))
+			return err
+		}))
+	}
+	// 2. 逐个升级工作节点
+	for _, h := range workers {
+		h := h
+		steps = append(steps, newStep("upgrade agent "+h.IP, func(ctx context.Context) error {
+			token, err := k3sToken(ctx, first)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, h, k3sAgentCommand(target, token, first.IP))
+			return err
+		}))
+	}
+	return steps, nil
+}
+
+func (p *k3sProvisioner) Check(ctx context.Context, cluster *storage.Cluster, hosts []storage.ClusterHost) error {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return err
+	}
+	output, err := runOnHost(ctx, masters[0], "k3s kubectl get --raw=/readyz")
+	if err != nil {
+		return errors.Wrap(err, "check readyz")
+	}
+	if output != "ok" {
+		return errors.Errorf("apiserver not ready: %s", output)
+	}
+	return nil
+}
+
+func (p *k3sProvisioner) JoinNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	first := masters[0]
+	version := k3sVersion(cluster.Version)
+	return []Step{
+		// 1. 加入集群
+		newStep("join node", func(ctx context.Context) error {
+			token, err := k3sToken(ctx, first)
+			if err != nil {
+				return err
+			}
+			command := k3sAgentCommand(version, token, first.IP)
+			if node.Role == storage.HostRoleMaster {
+				command = k3sServerCommand(version, token, first.IP)
+			}
+			_, err = runOnHost(ctx, node, command)
+			return err
+		}),
+		// 2. 设置节点标签与污点
+		newStep("label and taint node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, first, labelAndTaintCommands("k3s kubectl", nodeName, node)...)
+			return err
+		}),
+	}, nil
+}
+
+func (p *k3sProvisioner) DrainNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return []Step{
+		newStep("drain node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, masters[0], fmt.Sprintf("k3s kubectl drain %s --ignore-daemonsets --delete-emptydir-data", utils.ShellQuote(nodeName)))
+			return err
+		}),
+	}, nil
+}
+
+func (p *k3sProvisioner) RemoveNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return []Step{
+		// 1. 驱逐并删除节点，节点已删除时忽略
+		newStep("delete node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			name := utils.ShellQuote(nodeName)
+			_, err = runOnHost(ctx, masters[0],
+				fmt.Sprintf("! k3s kubectl get node %s >/dev/null 2>&1 || k3s kubectl drain %s --ignore-daemonsets --delete-emptydir-data", name, name),
+				fmt.Sprintf("k3s kubectl delete node %s --ignore-not-found", name),
+			)
+			return err
+		}),
+		// 2. 执行卸载脚本清理主机
+		commandStep("uninstall node", []storage.ClusterHost{node}, k3sUninstallCommand(node.Role)),
+	}, nil
+}
+
+// k3sToken 读取第一个控制面节点上的加入令牌
+func k3sToken(ctx context.Context, first storage.ClusterHost) (string, error) {
+	token, err := runOnHost(ctx, first, "cat "+utils.ShellQuote(k3sTokenPath))
+	if err != nil {
+		return "", errors.Wrap(err, "get node token")
+	}
+	return token, nil
+}
+
+// k3sServerCommand 生成控制面节点的安装命令，未指定 server 时初始化集群
+func k3sServerCommand(version string, token string, server string) string {
+	if server == "" {
+		return fmt.Sprintf(
+			"curl -sfL %s | INSTALL_K3S_VERSION=%s sh -s - server --cluster-init",
+			utils.ShellQuote(k3sInstallURL), utils.ShellQuote(version),
+		)
+	}
+	return fmt.Sprintf(
+		"curl -sfL %s | INSTALL_K3S_VERSION=%s K3S_TOKEN=%s sh -s - server --server %s",
+		utils.ShellQuote(k3sInstallURL), utils.ShellQuote(version), utils.ShellQuote(token), utils.ShellQuote("https://"+server+":6443"),
+	)
+}
+
+// k3sAgentCommand 生成工作节点的安装命令
+func k3sAgentCommand(version string, token string, server string) string {
+	return fmt.Sprintf(
+		"curl -sfL %s | INSTALL_K3S_VERSION=%s K3S_URL=%s K3S_TOKEN=%s sh -",
+		utils.ShellQuote(k3sInstallURL), utils.ShellQuote(version), utils.ShellQuote("https://"+server+":6443"), utils.ShellQuote(token),
+	)
+}
+
+// k3sUninstallCommand 生成卸载命令，卸载脚本不存在时跳过
+func k3sUninstallCommand(role storage.HostRole) string {
+	if role == storage.HostRoleMaster {
+		return "[ ! -x /usr/local/bin/k3s-uninstall.sh ] || /usr/local/bin/k3s-uninstall.sh"
+	}
+	return "[ ! -x /usr/local/bin/k3s-agent-uninstall.sh ] || /usr/local/bin/k3s-agent-uninstall.sh"
+}
+
+// k3sVersion 将 1.22.5 转换为 k3s 的发布版本 v1.22.5+k3s1
+func k3sVersion(version string) string {
+	return fmt.Sprintf("v%s+k3s1", version)
+}
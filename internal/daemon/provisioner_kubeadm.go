@@ -0,0 +1,306 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	kubeadmPodCIDR    = "10.244.0.0/16"
+	kubeadmKubeconfig = "/etc/kubernetes/admin.conf"
+	kubeadmFlannelURL = "https://github.com/flannel-io/flannel/releases/latest/download/kube-flannel.yml"
+)
+
+// kubeadmProvisioner 使用 kubeadm 部署 k8s 集群
+type kubeadmProvisioner struct{}
+
+func (p *kubeadmProvisioner) Create(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	masters, workers, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	runtimeCommands, err := kubeadmRuntimeCommands(cluster.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	first := masters[0]
+	socket := kubeadmCRISocket(cluster.Runtime)
+	steps := []Step{
+		// 1. 初始化主机
+		commandStep("prepare hosts", hosts, kubeadmPrepareCommands()...),
+		// 2. 安装容器运行时
+		commandStep("install runtime", hosts, runtimeCommands...),
+		// 3. 安装 kubeadm、kubelet 与 kubectl
+		commandStep("install kubeadm", hosts, kubeadmInstallCommands(cluster.Version)...),
+		// 4. 初始化控制面，重试前先清理上次失败的残留
+		commandStep("init control plane", []storage.ClusterHost{first},
+			fmt.Sprintf("kubeadm reset -f --cri-socket %s", utils.ShellQuote(socket)),
+			fmt.Sprintf(
+				"kubeadm init --kubernetes-version %s --pod-network-cidr %s --control-plane-endpoint %s --cri-socket %s --upload-certs",
+				utils.ShellQuote("v"+cluster.Version), utils.ShellQuote(kubeadmPodCIDR), utils.ShellQuote(first.IP+":6443"), utils.ShellQuote(socket),
+			),
+		),
+	}
+	// 5. 加入其他节点
+	if len(masters) > 1 {
+		steps = append(steps, newStep("join masters", func(ctx context.Context) error {
+			command, err := kubeadmJoinCommand(ctx, first, storage.HostRoleMaster, socket)
+			if err != nil {
+				return err
+			}
+			return runOnHosts(ctx, masters[1:], command)
+		}))
+	}
+	if len(workers) > 0 {
+		steps = append(steps, newStep("join workers", func(ctx context.Context) error {
+			command, err := kubeadmJoinCommand(ctx, first, storage.HostRoleWorker, socket)
+			if err != nil {
+				return err
+			}
+			return runOnHosts(ctx, workers, command)
+		}))
+	}
+	// 6. 安装网络插件
+	steps = append(steps, commandStep("install cni", []storage.ClusterHost{first},
+		fmt.Sprintf("%s apply -f %s", kubeadmKubectl(), utils.ShellQuote(kubeadmFlannelURL)),
+	))
+	return steps, nil
+}
+
+func (p *kubeadmProvisioner) Delete(cluster *storage.Cluster, hosts []storage.ClusterHost) ([]Step, error) {
+	socket := kubeadmCRISocket(cluster.Runtime)
+	return []Step{
+		commandStep("reset hosts", hosts,
+			fmt.Sprintf("kubeadm reset -f --cri-socket %s", utils.ShellQuote(socket)),
+			"rm -rf /etc/cni/net.d /var/lib/etcd $HOME/.kube",
+		),
+	}, nil
+}
+
+func (p *kubeadmProvisioner) Upgrade(cluster *storage.Cluster, hosts []storage.ClusterHost, version string) ([]Step, error) {
+	masters, workers, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	pkgVersion := version + "-00"
+	installKubeadm := fmt.Sprintf("apt-mark unhold kubeadm && apt-get update && apt-get install -y --allow-downgrades %s && apt-mark hold kubeadm", utils.ShellQuote("kubeadm="+pkgVersion))
+	installKubelet := fmt.Sprintf(
+		"apt-mark unhold kubelet kubectl && apt-get install -y --allow-downgrades %s %s && apt-mark hold kubelet kubectl",
+		utils.ShellQuote("kubelet="+pkgVersion), utils.ShellQuote("kubectl="+pkgVersion),
+	)
+	restartKubelet := "systemctl daemon-reload && systemctl restart kubelet"
+	// 1. 升级控制面，第一个控制面节点执行 upgrade apply，其余节点执行 upgrade node
+	first := masters[0]
+	steps := []Step{
+		commandStep("upgrade control plane "+first.IP, []storage.ClusterHost{first}, installKubeadm, fmt.Sprintf("kubeadm upgrade apply -y %s", utils.ShellQuote("v"+version))),
+	}
+	for _, h := range masters[1:] {
+		steps = append(steps, commandStep("upgrade control plane "+h.IP, []storage.ClusterHost{h}, installKubeadm, "kubeadm upgrade node"))
+	}
+	for _, h := range masters {
+		h := h
+		steps = append(steps, newStep("upgrade kubelet "+h.IP, func(ctx context.Context) error {
+			return kubeadmUpgradeKubelet(ctx, first, h, installKubelet, restartKubelet)
+		}))
+	}
+	// 2. 逐个升级工作节点
+	for _, h := range workers {
+		h := h
+		steps = append(steps, newStep("upgrade worker "+h.IP, func(ctx context.Context) error {
+			if _, err := runOnHost(ctx, h, installKubeadm, "kubeadm upgrade node"); err != nil {
+				return err
+			}
+			return kubeadmUpgradeKubelet(ctx, first, h, installKubelet, restartKubelet)
+		}))
+	}
+	return steps, nil
+}
+
+// kubeadmUpgradeKubelet 驱逐节点后升级 kubelet，完成后恢复调度
+func kubeadmUpgradeKubelet(ctx context.Context, master storage.ClusterHost, host storage.ClusterHost, commands ...string) error {
+	nodeName, err := getNodeName(ctx, host)
+	if err != nil {
+		return err
+	}
+	kubectl := kubeadmKubectl()
+	if _, err := runOnHost(ctx, master, fmt.Sprintf("%s drain %s --ignore-daemonsets --delete-emptydir-data", kubectl, utils.ShellQuote(nodeName))); err != nil {
+		return errors.Wrapf(err, "drain node %s", nodeName)
+	}
+	if _, err := runOnHost(ctx, host, commands...); err != nil {
+		return err
+	}
+	if _, err := runOnHost(ctx, master, fmt.Sprintf("%s uncordon %s", kubectl, utils.ShellQuote(nodeName))); err != nil {
+		return errors.Wrapf(err, "uncordon node %s", nodeName)
+	}
+	return nil
+}
+
+// kubeadmJoinCommand 在第一个控制面节点上生成加入集群的命令，已加入集群的主机跳过执行
+// kubeadm 输出的加入命令包含多个参数，原样拼接，其他参数需要转义
+func kubeadmJoinCommand(ctx context.Context, first storage.ClusterHost, role storage.HostRole, socket string) (string, error) {
+	joinCommand, err := runOnHost(ctx, first, "kubeadm token create --print-join-command")
+	if err != nil {
+		return "", errors.Wrap(err, "create join command")
+	}
+	command := fmt.Sprintf("%s --cri-socket %s", joinCommand, utils.ShellQuote(socket))
+	if role == storage.HostRoleMaster {
+		certificateKey, err := runOnHost(ctx, first, "kubeadm init phase upload-certs --upload-certs | tail -1")
+		if err != nil {
+			return "", errors.Wrap(err, "upload certs")
+		}
+		command = fmt.Sprintf("%s --control-plane --certificate-key %s --cri-socket %s", joinCommand, utils.ShellQuote(certificateKey), utils.ShellQuote(socket))
+	}
+	return fmt.Sprintf("[ -f /etc/kubernetes/kubelet.conf ] || %s", command), nil
+}
+
+func (p *kubeadmProvisioner) Check(ctx context.Context, cluster *storage.Cluster, hosts []storage.ClusterHost) error {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return err
+	}
+	output, err := runOnHost(ctx, masters[0], kubeadmKubectl()+" get --raw=/readyz")
+	if err != nil {
+		return errors.Wrap(err, "check readyz")
+	}
+	if output != "ok" {
+		return errors.Errorf("apiserver not ready: %s", output)
+	}
+	return nil
+}
+
+func (p *kubeadmProvisioner) JoinNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	runtimeCommands, err := kubeadmRuntimeCommands(cluster.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	first := masters[0]
+	nodeHosts := []storage.ClusterHost{node}
+	return []Step{
+		// 1. 初始化主机并安装运行时与 kubeadm
+		commandStep("prepare node", nodeHosts, kubeadmPrepareCommands()...),
+		commandStep("install runtime", nodeHosts, runtimeCommands...),
+		commandStep("install kubeadm", nodeHosts, kubeadmInstallCommands(cluster.Version)...),
+		// 2. 加入集群
+		newStep("join node", func(ctx context.Context) error {
+			command, err := kubeadmJoinCommand(ctx, first, node.Role, kubeadmCRISocket(cluster.Runtime))
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, node, command)
+			return err
+		}),
+		// 3. 设置节点标签与污点
+		newStep("label and taint node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, first, labelAndTaintCommands(kubeadmKubectl(), nodeName, node)...)
+			return err
+		}),
+	}, nil
+}
+
+func (p *kubeadmProvisioner) DrainNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return []Step{
+		newStep("drain node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			_, err = runOnHost(ctx, masters[0], fmt.Sprintf("%s drain %s --ignore-daemonsets --delete-emptydir-data", kubeadmKubectl(), utils.ShellQuote(nodeName)))
+			return err
+		}),
+	}, nil
+}
+
+func (p *kubeadmProvisioner) RemoveNode(cluster *storage.Cluster, hosts []storage.ClusterHost, node storage.ClusterHost) ([]Step, error) {
+	masters, _, err := splitHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return []Step{
+		// 1. 驱逐并删除节点，节点已删除时忽略
+		newStep("delete node", func(ctx context.Context) error {
+			nodeName, err := getNodeName(ctx, node)
+			if err != nil {
+				return err
+			}
+			kubectl := kubeadmKubectl()
+			name := utils.ShellQuote(nodeName)
+			_, err = runOnHost(ctx, masters[0],
+				fmt.Sprintf("! %s get node %s >/dev/null 2>&1 || %s drain %s --ignore-daemonsets --delete-emptydir-data", kubectl, name, kubectl, name),
+				fmt.Sprintf("%s delete node %s --ignore-not-found", kubectl, name),
+			)
+			return err
+		}),
+		// 2. 清理主机，控制面节点执行 reset 时会从 etcd 集群中移除自身
+		commandStep("reset node", []storage.ClusterHost{node},
+			fmt.Sprintf("kubeadm reset -f --cri-socket %s", utils.ShellQuote(kubeadmCRISocket(cluster.Runtime))),
+			"rm -rf /etc/cni/net.d /var/lib/etcd $HOME/.kube",
+		),
+	}, nil
+}
+
+func kubeadmKubectl() string {
+	return fmt.Sprintf("kubectl --kubeconfig %s", utils.ShellQuote(kubeadmKubeconfig))
+}
+
+func kubeadmPrepareCommands() []string {
+	return []string{
+		"swapoff -a && sed -i '/ swap / s/^/#/' /etc/fstab",
+		"printf 'overlay\\nbr_netfilter\\n' > /etc/modules-load.d/k8s.conf && modprobe overlay && modprobe br_netfilter",
+		"printf 'net.bridge.bridge-nf-call-iptables=1\\nnet.bridge.bridge-nf-call-ip6tables=1\\nnet.ipv4.ip_forward=1\\n' > /etc/sysctl.d/k8s.conf && sysctl --system",
+	}
+}
+
+func kubeadmRuntimeCommands(runtime string) ([]string, error) {
+	switch runtime {
+	case "containerd":
+		return []string{
+			"apt-get update && apt-get install -y containerd",
+			"mkdir -p /etc/containerd && containerd config default | sed 's/SystemdCgroup = false/SystemdCgroup = true/' > /etc/containerd/config.toml",
+			"systemctl enable --now containerd && systemctl restart containerd",
+		}, nil
+	case "cri-o":
+		return []string{
+			"apt-get update && apt-get install -y cri-o cri-o-runc",
+			"systemctl enable --now crio",
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported runtime: %s", runtime)
+	}
+}
+
+func kubeadmInstallCommands(version string) []string {
+	pkgVersion := version + "-00"
+	return []string{
+		"apt-get update && apt-get install -y apt-transport-https ca-certificates curl",
+		fmt.Sprintf(
+			"apt-get install -y --allow-downgrades %s %s %s",
+			utils.ShellQuote("kubelet="+pkgVersion), utils.ShellQuote("kubeadm="+pkgVersion), utils.ShellQuote("kubectl="+pkgVersion),
+		),
+		"apt-mark hold kubelet kubeadm kubectl",
+		"systemctl enable --now kubelet",
+	}
+}
+
+func kubeadmCRISocket(runtime string) string {
+	if strings.EqualFold(runtime, "cri-o") {
+		return "unix:///var/run/crio/crio.sock"
+	}
+	return "unix:///run/containerd/containerd.sock"
+}
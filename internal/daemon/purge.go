@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+// purgeInterval 清理过期数据的间隔
+const purgeInterval = time.Hour
+
+// runPurge 定期清理过期的数据，retention 大于 0 时同时清理超过保留时长的已删除集群与 webhook 投递记录，阻塞直到 ctx 结束
+func runPurge(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purge(ctx, retention)
+		}
+	}
+}
+
+func purge(ctx context.Context, retention time.Duration) {
+	logger := log.G(ctx)
+	count, err := storage.DeleteExpiredIdempotencyRecords()
+	if err != nil {
+		logger.Errorf("delete expired idempotency records: %s", err)
+	} else if count > 0 {
+		logger.Infof("delete %d expired idempotency records", count)
+	}
+	if retention <= 0 {
+		return
+	}
+	// 清理超过保留时长的 webhook 投递记录与事件
+	count, err = storage.DeleteExpiredWebhookEvents(time.Now().Add(-retention))
+	if err != nil {
+		logger.Errorf("delete expired webhook events: %s", err)
+	} else if count > 0 {
+		logger.Infof("delete %d expired webhook events", count)
+	}
+	// 分批清理，直到没有超过保留时长的集群
+	before := time.Now().Add(-retention)
+	total := 0
+	for ctx.Err() == nil {
+		count, err := storage.PurgeDeletedClusters(before)
+		total += count
+		if err != nil {
+			logger.Errorf("purge deleted clusters: %s", err)
+			break
+		}
+		if count == 0 {
+			break
+		}
+	}
+	if total > 0 {
+		logger.Infof("purge %d deleted clusters", total)
+	}
+}
@@ -0,0 +1,190 @@
+package daemon
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	webhookInterval       = 5 * time.Second  // 分发事件与投递的间隔
+	webhookBatchSize      = 100              // 单次分发的事件与投递数量
+	webhookMaxAttempts    = 8                // 最大投递次数，超过后标记为投递失败
+	webhookRetryWait      = 30 * time.Second // 第一次重试的等待时长，之后每次翻倍
+	webhookMaxRetryWait   = time.Hour        // 重试等待时长的上限
+	webhookMaxErrorSize   = 1024             // 记录的错误信息长度上限
+	webhookRequestTimeout = 10 * time.Second // 单次投递的超时时长
+)
+
+// 投递请求的头部
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// webhookClient 投递事件使用的 HTTPClient，请求路径为完整的 URL，只允许连接公网地址且不跟随重定向
+var webhookClient = func() *utils.HTTPClient {
+	client := utils.NewHTTPClient("")
+	client.SetRequestTimeout(webhookRequestTimeout)
+	client.DenyPrivateNetwork()
+	return client
+}()
+
+// webhookPayload 投递的事件内容
+type webhookPayload struct {
+	ID         string                   `json:"id"`         // 投递ID，重试时保持不变
+	Type       storage.WebhookEventType `json:"type"`       // 事件类型
+	ProjectID  string                   `json:"projectId"`  // 项目资源ID
+	CreateTime string                   `json:"createTime"` // 事件发生时间
+	Data       storage.WebhookEventData `json:"data"`       // 事件数据
+}
+
+// webhookRequest 投递请求，签名基于序列化后的请求体，因此直接返回预先序列化的内容
+type webhookRequest struct {
+	body    []byte
+	headers map[string]string
+}
+
+func (r *webhookRequest) MarshalJSON() ([]byte, error) {
+	return r.body, nil
+}
+
+func (r *webhookRequest) GetHeaders() map[string]string {
+	return r.headers
+}
+
+// newWebhookRequest 生成投递请求，签名为 sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func newWebhookRequest(webhook *storage.Webhook, delivery *storage.WebhookDelivery, event *storage.WebhookEvent) (*webhookRequest, error) {
+	id := strconv.FormatUint(delivery.ID, 10)
+	body, err := json.Marshal(&webhookPayload{
+		ID:         id,
+		Type:       event.Type,
+		ProjectID:  event.ProjectID,
+		CreateTime: utils.FormatTime(event.CreatedAt),
+		Data:       event.Data.Data,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal payload")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return &webhookRequest{
+		body: body,
+		headers: map[string]string{
+			HeaderWebhookEvent:         string(event.Type),
+			HeaderWebhookDelivery:      id,
+			HeaderWebhookTimestamp:     timestamp,
+			HeaderWebhookSignature:     "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+			utils.HeaderIdempotencyKey: "webhook-delivery-" + id,
+		},
+	}, nil
+}
+
+// runWebhookDispatcher 定期为新的事件生成投递记录并投递到期的记录，阻塞直到 ctx 结束
+func runWebhookDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(webhookInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dispatchWebhookEvents(ctx)
+			deliverWebhooks(ctx)
+		}
+	}
+}
+
+func dispatchWebhookEvents(ctx context.Context) {
+	logger := log.G(ctx)
+	for ctx.Err() == nil {
+		count, err := storage.DispatchWebhookEvents(webhookBatchSize)
+		if err != nil {
+			logger.Errorf("dispatch webhook events: %s", err)
+			return
+		}
+		if count < webhookBatchSize {
+			return
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context) {
+	logger := log.G(ctx)
+	items, err := storage.ListDueWebhookDeliveries(webhookBatchSize)
+	if err != nil {
+		logger.Errorf("list due webhook deliveries: %s", err)
+		return
+	}
+	for i := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		item := &items[i]
+		err := deliverWebhook(ctx, item)
+		item.Attempts++
+		switch {
+		case err == nil:
+			item.Status = storage.WebhookDeliveryStatusSucceeded
+			item.LastError = ""
+		case item.Attempts >= webhookMaxAttempts || errors.Is(err, storage.ErrDoesNotExist):
+			item.Status = storage.WebhookDeliveryStatusFailed
+			item.LastError = truncateError(err)
+		default:
+			// 指数退避，等待时长达到上限后不再增加
+			wait := webhookRetryWait << (item.Attempts - 1)
+			if wait > webhookMaxRetryWait || wait <= 0 {
+				wait = webhookMaxRetryWait
+			}
+			item.NextAttemptAt = time.Now().Add(wait)
+			item.LastError = truncateError(err)
+		}
+		if err != nil {
+			logger.Warnf("deliver webhook %s delivery %d attempts %d: %s", item.WebhookID, item.ID, item.Attempts, err)
+		}
+		if err := storage.UpdateWebhookDelivery(item); err != nil {
+			logger.Errorf("update webhook delivery %d: %s", item.ID, err)
+		}
+	}
+}
+
+func deliverWebhook(ctx context.Context, delivery *storage.WebhookDelivery) error {
+	webhook, err := storage.GetWebhookByResourceID(delivery.WebhookID)
+	if err != nil {
+		return errors.Wrap(err, "get webhook")
+	}
+	event, err := storage.GetWebhookEventByID(delivery.EventID)
+	if err != nil {
+		return errors.Wrap(err, "get webhook event")
+	}
+	request, err := newWebhookRequest(webhook, delivery, event)
+	if err != nil {
+		return err
+	}
+	// 只检查 2xx 状态码，不解析响应内容，错误会返回给调用方，不记录响应内容
+	ctx = context.WithValue(ctx, utils.AcceptSuccessStatusKey, true)
+	ctx = context.WithValue(ctx, utils.SkipUnmarshalKey, true)
+	ctx = context.WithValue(ctx, utils.OmitErrorBodyKey, true)
+	return webhookClient.POST(ctx, webhook.URL, request, &utils.BaseResponse{})
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > webhookMaxErrorSize {
+		msg = msg[:webhookMaxErrorSize]
+	}
+	return msg
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// Step 任务步骤，任务重试时从失败的步骤继续执行，因此步骤需要可以重复执行
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// newStep 创建任务步骤
+func newStep(name string, run func(ctx context.Context) error) Step {
+	return Step{Name: name, Run: run}
+}
+
+// commandStep 在多台主机上依次执行相同命令的步骤
+func commandStep(name string, hosts []storage.ClusterHost, commands ...string) Step {
+	return newStep(name, func(ctx context.Context) error {
+		return runOnHosts(ctx, hosts, commands...)
+	})
+}
+
+// stepError 步骤执行失败的错误，记录失败的步骤与起始时间
+type stepError struct {
+	step    string
+	startAt time.Time
+	err     error
+}
+
+func (e *stepError) Error() string {
+	return fmt.Sprintf("step %s: %s", e.step, e.err)
+}
+
+func (e *stepError) Unwrap() error {
+	return e.err
+}
+
+// runSteps 从检查点开始依次执行步骤，每个步骤成功后在事务中保存检查点并记录日志
+func runSteps(ctx context.Context, logger log.Logger, task *storage.Task, cfg *storage.ClusterTaskConfig, steps []Step) error {
+	start := cfg.Step
+	if start > len(steps) {
+		start = len(steps)
+	}
+	if start > 0 {
+		logger.Infof("resume from step %d/%d", start+1, len(steps))
+	}
+	for i := start; i < len(steps); i++ {
+		step := steps[i]
+		startAt := time.Now()
+		logger.WithField("step", step.Name).Infof("run step %d/%d", i+1, len(steps))
+		if err := step.Run(ctx); err != nil {
+			return &stepError{step: step.Name, startAt: startAt, err: err}
+		}
+		cfg.Step = i + 1
+		if err := task.SetConfig(cfg); err != nil {
+			return errors.Wrap(err, "set task config")
+		}
+		taskLog := &storage.TaskLog{
+			TaskID:  task.ID,
+			Reason:  storage.TaskLogReasonStepSucceeded,
+			Step:    step.Name,
+			Message: fmt.Sprintf("step %d/%d succeeded", i+1, len(steps)),
+			StartAt: startAt,
+			EndAt:   time.Now(),
+		}
+		if err := storage.SaveTaskCheckpoint(task, taskLog); err != nil {
+			return errors.Wrap(err, "save task checkpoint")
+		}
+		publishClusterEvent(ctx, logger, task.ResourceID)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+// countingSteps 生成记录执行次数的步骤，failures 为步骤失败的剩余次数
+func countingSteps(names []string, runs map[string]int, failures map[string]int) []Step {
+	steps := make([]Step, 0, len(names))
+	for _, name := range names {
+		name := name
+		steps = append(steps, newStep(name, func(ctx context.Context) error {
+			runs[name]++
+			if failures[name] > 0 {
+				failures[name]--
+				return errors.New(name + " failed")
+			}
+			return nil
+		}))
+	}
+	return steps
+}
+
+// taskLogSteps 任务日志记录的步骤名称
+func taskLogSteps(t *testing.T, taskID uint64) []string {
+	t.Helper()
+	items := []string{}
+	if err := storage.DB().Model(&storage.TaskLog{}).Where("task_id = ?", taskID).Order("id asc").Pluck("step", &items).Error; err != nil {
+		t.Fatalf("list task logs: %s", err)
+	}
+	return items
+}
+
+func TestRunStepsResumeFromCheckpoint(t *testing.T) {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	task := &storage.Task{
+		ResourceID: "cluster-a",
+		ProjectID:  storage.DefaultProjectID,
+		Status:     storage.TaskStatusEnqueued,
+		Action:     storage.ClusterActionCreate,
+		RetryLimit: 3,
+	}
+	cfg := &storage.ClusterTaskConfig{Version: "1.28.2"}
+	if err := task.SetConfig(cfg); err != nil {
+		t.Fatalf("set task config: %s", err)
+	}
+	if err := storage.DB().Create(task).Error; err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	names := []string{"prepare", "install", "join"}
+	runs := map[string]int{}
+	failures := map[string]int{"install": 1}
+	logger := log.WithField("test", t.Name())
+
+	// 第二个步骤失败，只保存第一个步骤的检查点
+	err := runSteps(context.Background(), logger, task, cfg, countingSteps(names, runs, failures))
+	var stepErr *stepError
+	if !errors.As(err, &stepErr) || stepErr.step != "install" {
+		t.Fatalf("expected install step error, got %v", err)
+	}
+	saved, err := storage.GetTaskByID(task.ID)
+	if err != nil {
+		t.Fatalf("get task: %s", err)
+	}
+	savedCfg := &storage.ClusterTaskConfig{}
+	if err := saved.GetConfig(savedCfg); err != nil {
+		t.Fatalf("get task config: %s", err)
+	}
+	if savedCfg.Step != 1 || savedCfg.Version != cfg.Version {
+		t.Fatalf("unexpected checkpoint: %+v", savedCfg)
+	}
+	if steps := taskLogSteps(t, task.ID); len(steps) != 1 || steps[0] != "prepare" {
+		t.Fatalf("unexpected task logs: %v", steps)
+	}
+
+	// 重试时从数据库读取检查点，从失败的步骤继续执行
+	err = runSteps(context.Background(), logger, saved, savedCfg, countingSteps(names, runs, failures))
+	if err != nil {
+		t.Fatalf("resume steps: %s", err)
+	}
+	if runs["prepare"] != 1 || runs["install"] != 2 || runs["join"] != 1 {
+		t.Errorf("unexpected step runs: %v", runs)
+	}
+	if savedCfg.Step != len(names) {
+		t.Errorf("expected step %d, got %d", len(names), savedCfg.Step)
+	}
+	if steps := taskLogSteps(t, task.ID); len(steps) != 3 || steps[2] != "join" {
+		t.Errorf("unexpected task logs: %v", steps)
+	}
+
+	// 检查点超过步骤数时不再执行
+	runs = map[string]int{}
+	if err := runSteps(context.Background(), logger, saved, &storage.ClusterTaskConfig{Step: 10}, countingSteps(names, runs, nil)); err != nil {
+		t.Fatalf("run completed steps: %s", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("expected no step runs, got %v", runs)
+	}
+}
+
+func TestRunStepsCanceledTask(t *testing.T) {
+	testutil.SetupDB(t, storage.DB, storage.Setup, storage.Migrate)
+	task := &storage.Task{
+		ResourceID: "cluster-a",
+		ProjectID:  storage.DefaultProjectID,
+		Status:     storage.TaskStatusCanceled,
+		Action:     storage.ClusterActionCreate,
+	}
+	if err := storage.DB().Create(task).Error; err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	runs := map[string]int{}
+	err := runSteps(context.Background(), log.WithField("test", t.Name()), task, &storage.ClusterTaskConfig{}, countingSteps([]string{"prepare", "install"}, runs, nil))
+	// 任务被取消后保存检查点失败，不再执行之后的步骤
+	if !errors.Is(err, storage.ErrTaskNotPending) {
+		t.Fatalf("expected ErrTaskNotPending, got %v", err)
+	}
+	if runs["prepare"] != 1 || runs["install"] != 0 {
+		t.Errorf("unexpected step runs: %v", runs)
+	}
+	if steps := taskLogSteps(t, task.ID); len(steps) != 0 {
+		t.Errorf("expected no task logs, got %v", steps)
+	}
+}
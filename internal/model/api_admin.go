@@ -0,0 +1,58 @@
+package model
+
+import "gitbub.com/wbuntu/gin-template/internal/storage"
+
+type ListDeletedClusterReq struct {
+	BaseRequest
+	ProjectID string `json:"projectId" form:"projectId"`               // 项目资源ID，为空时列出全部项目
+	PageNo    int    `json:"pageNo" form:"pageNo" binding:"gte=1"`     // 分页页码
+	PageSize  int    `json:"pageSize" form:"pageSize" binding:"gte=1"` // 分页大小
+}
+
+type ListDeletedClusterResp struct {
+	BaseResponse
+	Data       []DeletedCluster `json:"data"`                    // 已删除的集群列表
+	TotalCount int              `json:"totalCount" example:"10"` // 已删除的集群总数
+}
+
+type DeletedCluster struct {
+	Name       string          `json:"name" example:"imortal-cluster-name"`      // 名称
+	ResourceID string          `json:"resourceID" example:"cluster-sedqqz7ka"`   // 集群ID
+	ProjectID  string          `json:"projectId" example:"project-default"`      // 项目资源ID
+	Type       storage.K8sType `json:"k8sType" example:"k8s"`                    // 类型：k8s、k3s
+	Version    string          `json:"version" example:"1.22.5"`                 // 版本
+	CreateTime string          `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+	DeleteTime string          `json:"deleteTime" example:"2006-01-02 15:04:05"` // 删除时间，超过保留时长后彻底删除
+}
+
+type RestoreClusterReq struct {
+	BaseRequest
+}
+
+type RestoreClusterResp struct {
+	BaseResponse
+	Data string `json:"data" example:"cluster-sedqqz7ka"` // 集群ID
+}
+
+type ListDeletedTaskReq struct {
+	BaseRequest
+	ClusterID string `json:"clusterId" form:"clusterId"`               // 集群ID，为空时列出全部已删除集群的任务
+	PageNo    int    `json:"pageNo" form:"pageNo" binding:"gte=1"`     // 分页页码
+	PageSize  int    `json:"pageSize" form:"pageSize" binding:"gte=1"` // 分页大小
+}
+
+type ListDeletedTaskResp struct {
+	BaseResponse
+	Data       []DeletedTask `json:"data"`                    // 已删除的任务列表
+	TotalCount int           `json:"totalCount" example:"10"` // 已删除的任务总数
+}
+
+type DeletedTask struct {
+	ID         uint64 `json:"id" example:"1"`                           // 任务ID
+	ClusterID  string `json:"clusterId" example:"cluster-sedqqz7ka"`    // 集群ID
+	ProjectID  string `json:"projectId" example:"project-default"`      // 项目资源ID
+	Action     string `json:"action" example:"DeleteCluster"`           // 任务操作
+	Status     string `json:"status" example:"Success"`                 // 任务状态
+	CreateTime string `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+	DeleteTime string `json:"deleteTime" example:"2006-01-02 15:04:05"` // 删除时间，超过保留时长后彻底删除
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+type ListAuditEventReq struct {
+	BaseRequest
+	Actor     string `json:"actor" form:"actor"`                                            // 调用者身份
+	StartTime int64  `json:"startTime" form:"startTime" binding:"omitempty,gte=0"`          // 起始时间，unix 时间戳（秒），包含起始时间
+	EndTime   int64  `json:"endTime" form:"endTime" binding:"omitempty,gtefield=StartTime"` // 结束时间，unix 时间戳（秒），包含结束时间
+	PageNo    int    `json:"pageNo" form:"pageNo" binding:"omitempty,gte=1"`                // 分页页码，不能与 pageToken 同时使用
+	PageSize  int    `json:"pageSize" form:"pageSize" binding:"gte=1"`                      // 分页大小
+	PageToken string `json:"pageToken" form:"pageToken"`                                    // 分页令牌，使用上一页返回的 nextPageToken 获取下一页
+	WithTotal bool   `json:"withTotal" form:"withTotal"`                                    // 是否计算总数，使用 pageNo 分页时总是计算
+}
+
+type ListAuditEventResp struct {
+	BaseResponse
+	Data          []AuditEventItem `json:"data"`                              // 审计事件列表，最新的事件在前
+	TotalCount    *int             `json:"totalCount,omitempty" example:"10"` // 事件总数，不计算总数时为空
+	NextPageToken string           `json:"nextPageToken,omitempty"`           // 下一页的分页令牌，没有下一页时为空
+}
+
+type AuditEventItem struct {
+	ID           uint64          `json:"id" example:"1"`                                                               // 事件ID
+	RequestID    string          `json:"requestId" example:"6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"`                     // 请求ID
+	Actor        string          `json:"actor" example:"alice"`                                                        // 调用者身份
+	Method       string          `json:"method" example:"DELETE"`                                                      // 请求方法
+	Route        string          `json:"route" example:"/api/v1.0/projects/:projectId/clusters/:clusterId"`            // 路由
+	Path         string          `json:"path" example:"/api/v1.0/projects/project-default/clusters/cluster-sedqqz7ka"` // 请求路径
+	ProjectID    string          `json:"projectId,omitempty" example:"project-default"`                                // 项目资源ID
+	ResourceID   string          `json:"resourceId,omitempty" example:"cluster-sedqqz7ka"`                             // 操作的资源ID，优先使用路径中的资源ID，创建类请求使用响应中的资源ID
+	RequestBody  json.RawMessage `json:"requestBody,omitempty" swaggertype:"object"`                                   // 脱敏后的请求体，请求体不是 JSON 或超过长度限制时为空
+	StatusCode   int             `json:"statusCode" example:"200"`                                                     // HTTP状态码
+	ResponseCode string          `json:"responseCode" example:"Success"`                                               // 响应码
+	Latency      int64           `json:"latency" example:"12"`                                                         // 耗时（毫秒）
+	ClientIP     string          `json:"clientIp" example:"192.168.1.10"`                                              // 客户端IP
+	CreateTime   string          `json:"createTime" example:"2006-01-02 15:04:05"`                                     // 创建时间
+}
+
+// NewAuditEventItem 将审计事件转换为接口与文件中使用的格式
+func NewAuditEventItem(item *storage.AuditEvent) AuditEventItem {
+	return AuditEventItem{
+		ID:           item.ID,
+		RequestID:    item.RequestID,
+		Actor:        item.Actor,
+		Method:       item.Method,
+		Route:        item.Route,
+		Path:         item.Path,
+		ProjectID:    item.ProjectID,
+		ResourceID:   item.ResourceID,
+		RequestBody:  json.RawMessage(item.RequestBody),
+		StatusCode:   item.StatusCode,
+		ResponseCode: item.ResponseCode,
+		Latency:      item.Latency,
+		ClientIP:     item.ClientIP,
+		CreateTime:   utils.FormatTime(item.CreatedAt),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/pkg/errors"
@@ -13,8 +14,11 @@ import (
 type Route struct {
 	Method     string
 	Path       string
-	Middleware []gin.HandlerFunc // 中间件
-	Factory    func() Controller // 工厂函数代替反射，生成控制器的效率更高
+	Permission storage.Permission // 访问需要的权限，为空时只需要通过认证
+	Middleware []gin.HandlerFunc  // 中间件
+	Codecs     []Codec            // 允许的内容编码，第一个为默认编码，为空时使用 DefaultCodecs
+	Factory    func() Controller  // 工厂函数代替反射，生成控制器的效率更高
+	ProjectID  string             // 绑定的项目ID，用于路径中没有项目ID的兼容路由
 }
 
 type Codec string
@@ -24,11 +28,12 @@ const (
 	CodecProto Codec = "proto"
 	CodecYaml  Codec = "yaml"
 	CodecWS    Codec = "ws"
+	CodecSSE   Codec = "sse"
 )
 
 func (c Codec) Streaming() bool {
 	switch c {
-	case CodecWS:
+	case CodecWS, CodecSSE:
 		return true
 	default:
 		return false
@@ -36,13 +41,17 @@ func (c Codec) Streaming() bool {
 }
 
 func (c Codec) BindRequest(g *gin.Context, req Request) error {
+	// 查询参数与空请求体统一按 JSON 的规则解析
+	if !hasRequestBody(g.Request) {
+		return CodecJSON.bindJSON(g, req)
+	}
 	switch c {
 	case CodecJSON:
 		return c.bindJSON(g, req)
 	case CodecProto:
-		return g.ShouldBindWith(req, binding.ProtoBuf)
+		return bindProto(g, req)
 	case CodecYaml:
-		return g.ShouldBindYAML(req)
+		return bindYAML(g, req)
 	default:
 		return errors.Errorf("unsupported codec: %s", c)
 	}
@@ -55,6 +64,13 @@ func (c Codec) bindJSON(g *gin.Context, req Request) error {
 	// 其他：从 Query 中解析 request ，参数值禁止嵌套，不允许使用数组、字典和结构体
 	switch g.Request.Method {
 	case http.MethodPost, http.MethodPatch, http.MethodPut:
+		// 请求体为空时只做校验，例如不需要参数的操作类接口
+		if g.Request.ContentLength == 0 {
+			if binding.Validator != nil {
+				return binding.Validator.ValidateStruct(req)
+			}
+			return nil
+		}
 		return g.ShouldBindJSON(req)
 	case http.MethodGet, http.MethodDelete, http.MethodOptions, http.MethodHead:
 		// 移植 binding.Query 的逻辑，支持 json 的 tag 解析
@@ -69,22 +85,33 @@ func (c Codec) bindJSON(g *gin.Context, req Request) error {
 	return nil
 }
 
-func (c Codec) RenderResponse(g *gin.Context, resp Response) {
+func (c Codec) RenderResponse(g *gin.Context, status int, resp Response) {
+	var data []byte
+	var err error
 	switch c {
 	case CodecJSON:
-		g.JSON(http.StatusOK, resp)
+		g.JSON(status, resp)
+		return
 	case CodecProto:
-		g.ProtoBuf(http.StatusOK, resp)
+		data, err = marshalProto(resp)
 	case CodecYaml:
-		g.YAML(http.StatusOK, resp)
+		data, err = marshalYAML(resp)
 	default:
-		log.G(g).Errorf("unsupported codec: %s", c)
+		err = errors.Errorf("unsupported codec: %s", c)
 	}
+	if err != nil {
+		log.G(g).Errorf("render response: %s", err)
+		r := response(CodeInternalError, "render response")
+		r.RequestID = resp.GetRequestID()
+		g.JSON(http.StatusInternalServerError, r)
+		return
+	}
+	g.Data(status, c.ContentType(), data)
 }
 
 type Controller interface {
 	Init()                 // 初始化
-	Codec() Codec          // 内容编码
+	Codec() Codec          // 内容编码，流式编码由控制器直接处理请求，其他编码根据请求头部协商
 	Serve(*gin.Context)    // 控制器逻辑
 	GetRequest() Request   // 获取请求
 	GetResponse() Response // 获取响应
@@ -128,10 +155,14 @@ type Request interface {
 
 type Response interface {
 	Init()
+	GetCode() string
 	GetRequestID() string
 	SetRequestID(string)
 }
 
+// PageParams 分页相关的查询参数，分页令牌绑定查询范围时忽略这些参数，允许翻页时调整分页大小
+var PageParams = []string{"pageNo", "pageSize", "pageToken", "withTotal"}
+
 type BaseRequest struct {
 	RequestID string `json:"-"` // 请求ID
 }
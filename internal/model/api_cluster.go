@@ -1,16 +1,28 @@
 package model
 
 import (
+	"encoding/json"
+
 	"gitbub.com/wbuntu/gin-template/internal/storage"
 )
 
 type CreateClusterReq struct {
 	BaseRequest
-	Name        string          `json:"name" example:"imortal-cluster-name"`                               // 名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
-	Description string          `json:"description" example:"amazing-cluster-description"`                 // 描述，支持 0~255 位字符
-	Type        storage.K8sType `json:"k8sType" example:"k8s" binding:"required,oneof=k8s k3s"`            // 类型：k8s、k3s
-	Version     string          `json:"version" example:"1.22.5" binding:"required"`                       // 版本
-	Runtime     string          `json:"runtime" example:"cri-o" binding:"required,oneof=cri-o containerd"` // 容器运行时
+	Name        string            `json:"name" example:"imortal-cluster-name"`                               // 名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
+	Description string            `json:"description" example:"amazing-cluster-description"`                 // 描述，支持 0~255 位字符
+	Type        storage.K8sType   `json:"k8sType" example:"k8s" binding:"required,oneof=k8s k3s"`            // 类型：k8s、k3s
+	Version     string            `json:"version" example:"1.22.5" binding:"required"`                       // 版本，格式为 Major.Minor.Patch，允许以 v 开头
+	Runtime     string            `json:"runtime" example:"cri-o" binding:"required,oneof=cri-o containerd"` // 容器运行时
+	Hosts       []ClusterHost     `json:"hosts" binding:"required,min=1,dive"`                               // 集群主机，至少包含一个控制面节点
+	Labels      map[string]string `json:"labels"`                                                            // 标签，格式与 k8s 标签相同，可以在列表中通过 labelSelector 选择集群
+	Annotations map[string]string `json:"annotations"`                                                       // 注解，保存不用于选择集群的附加信息
+}
+
+type ClusterHost struct {
+	IP       string           `json:"ip" example:"192.168.1.10" binding:"required,ip"`              // IP地址
+	Port     int              `json:"port" example:"22" binding:"required,gte=1,lte=65535"`         // SSH端口
+	Password string           `json:"password" example:"password" binding:"required"`               // root密码
+	Role     storage.HostRole `json:"role" example:"master" binding:"required,oneof=master worker"` // 角色：master、worker
 }
 
 type CreateClusterResp struct {
@@ -18,29 +30,65 @@ type CreateClusterResp struct {
 	Data string `json:"data" example:"cluster-sedqqz7kavbh"` // 集群资源ID
 }
 
+type UpgradeClusterReq struct {
+	BaseRequest
+	Version string `json:"version" example:"1.23.17" binding:"required"` // 目标版本，不允许降级或跳过次版本
+}
+
+// PatchClusterReq 使用 JSON Merge Patch 修改集群，保留请求体中的原始字段，用于区分未修改的字段与设置为 null 的字段
+type PatchClusterReq struct {
+	BaseRequest
+	Fields map[string]json.RawMessage `json:"-"` // 请求体中的字段
+}
+
+func (r *PatchClusterReq) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Fields)
+}
+
+func (r *PatchClusterReq) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Fields)
+}
+
+// ClusterPatch 集群可修改的字段，只用于生成接口文档
+type ClusterPatch struct {
+	Name        string            `json:"name" example:"imortal-cluster-name"`               // 名称，不允许为 null，规则与创建集群相同
+	Description string            `json:"description" example:"amazing-cluster-description"` // 描述，支持 0~255 位字符，null 表示清空
+	Labels      map[string]string `json:"labels"`                                            // 标签，null 表示删除全部标签，标签值为 null 表示删除该标签
+	Annotations map[string]string `json:"annotations"`                                       // 注解，null 表示删除全部注解，注解值为 null 表示删除该注解
+}
+
 type ListClusterReq struct {
 	BaseRequest
-	PageNo      int    `json:"pageNo" form:"pageNo" binding:"gte=1"`     // 分页页码
-	PageSize    int    `json:"pageSize" form:"pageSize" binding:"gte=1"` // 分页大小
-	FilterKey   string `json:"filterKey" form:"filterKey"`               // 过滤字段
-	FilterValue string `json:"filterValue" form:"filterValue"`           // 过滤字段的值
+	PageNo        int      `json:"pageNo" form:"pageNo" binding:"omitempty,gte=1"`                // 分页页码，不能与 pageToken 同时使用
+	PageSize      int      `json:"pageSize" form:"pageSize" binding:"gte=1"`                      // 分页大小
+	PageToken     string   `json:"pageToken" form:"pageToken"`                                    // 分页令牌，使用上一页返回的 nextPageToken 获取下一页
+	WithTotal     bool     `json:"withTotal" form:"withTotal"`                                    // 是否计算总数，使用 pageNo 分页时总是计算
+	Filter        []string `json:"filter" form:"filter"`                                          // 过滤条件，格式为 field:op:value，可以传入多个，使用 AND 组合
+	SortBy        string   `json:"sortBy" form:"sortBy"`                                          // 排序字段，默认按创建顺序
+	SortOrder     string   `json:"sortOrder" form:"sortOrder" binding:"omitempty,oneof=asc desc"` // 排序方向：asc、desc，默认为 desc
+	FilterKey     string   `json:"filterKey" form:"filterKey"`                                    // 过滤字段，已废弃，使用 filter 代替
+	FilterValue   string   `json:"filterValue" form:"filterValue"`                                // 过滤字段的值，已废弃，使用 filter 代替
+	LabelSelector string   `json:"labelSelector" form:"labelSelector"`                            // 标签选择器，语法与 k8s 相同，例如 env=prod,tier!=edge,team in (a,b)
 }
 
 type ListClusterResp struct {
 	BaseResponse
-	Data       []ClusterSummary `json:"data"`                     // 集群概要列表
-	TotalCount int              `json:"totalCount" example:"100"` // 集群总数
+	Data          []ClusterSummary `json:"data"`                               // 集群概要列表
+	TotalCount    *int             `json:"totalCount,omitempty" example:"100"` // 集群总数，不计算总数时为空
+	NextPageToken string           `json:"nextPageToken,omitempty"`            // 下一页的分页令牌，没有下一页时为空
 }
 
 type ClusterSummary struct {
-	Name        string          `json:"name" example:"imortal-cluster-name"`               // 名称
-	Description string          `json:"description" example:"amazing-cluster-description"` // 描述
-	ResourceID  string          `json:"resourceID" example:"cluster-sedqqz7ka"`            // 集群ID
-	Type        storage.K8sType `json:"k8sType" example:"k8s"`                             // 类型：k8s、k3s
-	Version     string          `json:"version" example:"1.22.5"`                          // 版本
-	Runtime     string          `json:"runtime" example:"cri-o"`                           // 容器运行时
-	Status      string          `json:"status" exmaple:"Creating"`                         // 集群状态
-	CreateTime  string          `json:"createTime" example:"2006-01-02 15:04:05"`          // 创建时间
+	Name            string            `json:"name" example:"imortal-cluster-name"`               // 名称
+	Description     string            `json:"description" example:"amazing-cluster-description"` // 描述
+	ResourceID      string            `json:"resourceID" example:"cluster-sedqqz7ka"`            // 集群ID
+	Type            storage.K8sType   `json:"k8sType" example:"k8s"`                             // 类型：k8s、k3s
+	Version         string            `json:"version" example:"1.22.5"`                          // 版本
+	Runtime         string            `json:"runtime" example:"cri-o"`                           // 容器运行时
+	Status          string            `json:"status" exmaple:"Creating"`                         // 集群状态
+	ResourceVersion uint64            `json:"resourceVersion" example:"3"`                       // 资源版本，每次更新加一，修改集群时可以通过 If-Match 头部携带
+	Labels          map[string]string `json:"labels"`                                            // 标签
+	CreateTime      string            `json:"createTime" example:"2006-01-02 15:04:05"`          // 创建时间
 }
 
 type GetClusterReq struct {
@@ -53,12 +101,38 @@ type GetClusterResp struct {
 }
 
 type ClusterDetail struct {
-	Name        string          `json:"name" example:"imortal-cluster-name"`               // 名称
-	Description string          `json:"description" example:"amazing-cluster-description"` // 描述
-	ResourceID  string          `json:"resourceID" example:"cluster-sedqqz7ka"`            // 集群ID
-	Type        storage.K8sType `json:"k8sType" example:"k8s"`                             // 类型：k8s、k3s
-	Version     string          `json:"version" example:"1.22.5"`                          // 版本
-	Runtime     string          `json:"runtime" example:"cri-o"`                           // 容器运行时
-	Status      string          `json:"status" exmaple:"Creating"`                         // 集群状态
-	CreateTime  string          `json:"createTime" example:"2006-01-02 15:04:05"`          // 创建时间
+	Name            string            `json:"name" example:"imortal-cluster-name"`               // 名称
+	Description     string            `json:"description" example:"amazing-cluster-description"` // 描述
+	ResourceID      string            `json:"resourceID" example:"cluster-sedqqz7ka"`            // 集群ID
+	ProjectID       string            `json:"projectId" example:"project-default"`               // 项目ID
+	Type            storage.K8sType   `json:"k8sType" example:"k8s"`                             // 类型：k8s、k3s
+	Version         string            `json:"version" example:"1.22.5"`                          // 版本
+	Runtime         string            `json:"runtime" example:"cri-o"`                           // 容器运行时
+	Status          string            `json:"status" exmaple:"Creating"`                         // 集群状态
+	ResourceVersion uint64            `json:"resourceVersion" example:"3"`                       // 资源版本，每次更新加一，修改集群时可以通过 If-Match 头部携带
+	Labels          map[string]string `json:"labels"`                                            // 标签
+	Annotations     map[string]string `json:"annotations"`                                       // 注解
+	CreateTime      string            `json:"createTime" example:"2006-01-02 15:04:05"`          // 创建时间
+}
+
+type ClusterEventsReq struct {
+	BaseRequest
+}
+
+type ClusterEventsResp struct {
+	BaseResponse
+}
+
+// ClusterStatusEvent SSE 中 cluster 事件的数据
+type ClusterStatusEvent struct {
+	ResourceID      string `json:"resourceID" example:"cluster-sedqqz7ka"`   // 集群ID
+	Status          string `json:"status" example:"Running"`                 // 集群状态
+	ResourceVersion uint64 `json:"resourceVersion" example:"3"`              // 资源版本
+	UpdateTime      string `json:"updateTime" example:"2006-01-02 15:04:05"` // 更新时间
+}
+
+// TaskLogEvent SSE 中 log 事件的数据
+type TaskLogEvent struct {
+	TaskID uint64 `json:"taskId" example:"1"` // 任务ID
+	TaskLogItem
 }
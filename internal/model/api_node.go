@@ -0,0 +1,111 @@
+package model
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+type AddNodeReq struct {
+	BaseRequest
+	NodePoolID string            `json:"nodePoolId" example:"nodepool-x2kq8sj3ma"`                      // 节点池资源ID，节点继承节点池的角色、标签与污点
+	IP         string            `json:"ip" example:"192.168.1.11" binding:"required,ip"`               // IP地址
+	Port       int               `json:"port" example:"22" binding:"required,gte=1,lte=65535"`          // SSH端口
+	Password   string            `json:"password" example:"password" binding:"required"`                // root密码
+	Role       storage.HostRole  `json:"role" example:"worker" binding:"omitempty,oneof=master worker"` // 角色：master、worker，未指定节点池时必填
+	Labels     map[string]string `json:"labels"`                                                        // 节点标签，与节点池标签合并，同名时以节点为准
+	Taints     []Taint           `json:"taints" binding:"omitempty,dive"`                               // 节点污点，追加到节点池污点之后
+}
+
+type Taint struct {
+	Key    string `json:"key" example:"dedicated" binding:"required"`                                                 // 键
+	Value  string `json:"value" example:"gpu"`                                                                        // 值
+	Effect string `json:"effect" example:"NoSchedule" binding:"required,oneof=NoSchedule PreferNoSchedule NoExecute"` // 效果：NoSchedule、PreferNoSchedule、NoExecute
+}
+
+type AddNodeResp struct {
+	BaseResponse
+	Data string `json:"data" example:"node-k2jd8sm1qa"` // 节点资源ID
+}
+
+type ListNodeReq struct {
+	BaseRequest
+	PageNo    int      `json:"pageNo" form:"pageNo" binding:"gte=1"`                          // 分页页码
+	PageSize  int      `json:"pageSize" form:"pageSize" binding:"gte=1"`                      // 分页大小
+	Filter    []string `json:"filter" form:"filter"`                                          // 过滤条件，格式为 field:op:value，可以传入多个，使用 AND 组合
+	SortBy    string   `json:"sortBy" form:"sortBy"`                                          // 排序字段，默认按加入顺序
+	SortOrder string   `json:"sortOrder" form:"sortOrder" binding:"omitempty,oneof=asc desc"` // 排序方向：asc、desc，默认为 desc
+}
+
+type ListNodeResp struct {
+	BaseResponse
+	Data       []NodeSummary `json:"data"`                    // 节点概要列表
+	TotalCount int           `json:"totalCount" example:"10"` // 节点总数
+}
+
+type NodeSummary struct {
+	ResourceID string            `json:"resourceID" example:"node-k2jd8sm1qa"`     // 节点ID
+	NodePoolID string            `json:"nodePoolId" example:"nodepool-x2kq8sj3ma"` // 节点池ID
+	Role       storage.HostRole  `json:"role" example:"worker"`                    // 角色：master、worker
+	IP         string            `json:"ip" example:"192.168.1.11"`                // IP地址
+	Port       int               `json:"port" example:"22"`                        // SSH端口
+	Labels     map[string]string `json:"labels"`                                   // 节点标签
+	Taints     []Taint           `json:"taints"`                                   // 节点污点
+	Status     string            `json:"status" example:"Running"`                 // 节点状态
+	CreateTime string            `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+}
+
+type CreateNodePoolReq struct {
+	BaseRequest
+	Name   string            `json:"name" example:"gpu-pool" binding:"required"`                   // 名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
+	Role   storage.HostRole  `json:"role" example:"worker" binding:"required,oneof=master worker"` // 角色：master、worker
+	Labels map[string]string `json:"labels"`                                                       // 节点标签
+	Taints []Taint           `json:"taints" binding:"omitempty,dive"`                              // 节点污点
+}
+
+type CreateNodePoolResp struct {
+	BaseResponse
+	Data string `json:"data" example:"nodepool-x2kq8sj3ma"` // 节点池资源ID
+}
+
+type ListNodePoolReq struct {
+	BaseRequest
+}
+
+type ListNodePoolResp struct {
+	BaseResponse
+	Data []NodePoolSummary `json:"data"` // 节点池列表
+}
+
+type NodePoolSummary struct {
+	ResourceID string            `json:"resourceID" example:"nodepool-x2kq8sj3ma"` // 节点池ID
+	Name       string            `json:"name" example:"gpu-pool"`                  // 名称
+	Role       storage.HostRole  `json:"role" example:"worker"`                    // 角色：master、worker
+	Labels     map[string]string `json:"labels"`                                   // 节点标签
+	Taints     []Taint           `json:"taints"`                                   // 节点污点
+	CreateTime string            `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+}
+
+type NodeShellReq struct {
+	BaseRequest
+	Cols int    `json:"cols" form:"cols" example:"80" binding:"omitempty,gte=1,lte=1000"`                // 终端列数，默认为 80
+	Rows int    `json:"rows" form:"rows" example:"24" binding:"omitempty,gte=1,lte=1000"`                // 终端行数，默认为 24
+	Term string `json:"term" form:"term" example:"xterm-256color" binding:"omitempty,max=64,printascii"` // 终端类型，默认为 xterm-256color
+}
+
+type NodeShellResp struct {
+	BaseResponse
+}
+
+// ShellMessage 终端的控制消息，客户端以 JSON 文本帧发送输入与终端大小，终端输出以二进制帧返回，会话结束时服务端发送 exit 消息
+type ShellMessage struct {
+	Type string `json:"type" example:"input"`        // 类型：input、resize、exit
+	Data string `json:"data,omitempty" example:"ls"` // input 为终端输入，exit 为会话结束的原因
+	Cols int    `json:"cols,omitempty" example:"80"` // resize 的终端列数
+	Rows int    `json:"rows,omitempty" example:"24"` // resize 的终端行数
+}
+
+// 终端控制消息类型
+const (
+	ShellMessageInput  = "input"  // 终端输入
+	ShellMessageResize = "resize" // 调整终端大小
+	ShellMessageExit   = "exit"   // 会话结束
+)
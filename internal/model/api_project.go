@@ -0,0 +1,48 @@
+package model
+
+type CreateProjectReq struct {
+	BaseRequest
+	Name         string `json:"name" example:"team-a" binding:"required"`  // 名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
+	Description  string `json:"description" example:"clusters of team a"`  // 描述
+	ClusterQuota int    `json:"clusterQuota" example:"10" binding:"gte=0"` // 集群数量配额，0 表示不限制
+}
+
+type CreateProjectResp struct {
+	BaseResponse
+	Data string `json:"data" example:"project-k2jd8sm1qa"` // 项目资源ID
+}
+
+type UpdateProjectReq struct {
+	BaseRequest
+	Description  string `json:"description" example:"clusters of team a"`  // 描述
+	ClusterQuota int    `json:"clusterQuota" example:"20" binding:"gte=0"` // 集群数量配额，0 表示不限制，小于已有集群数量时只限制新建集群
+}
+
+type ListProjectReq struct {
+	BaseRequest
+	PageNo   int `json:"pageNo" form:"pageNo" binding:"gte=1"`     // 分页页码
+	PageSize int `json:"pageSize" form:"pageSize" binding:"gte=1"` // 分页大小
+}
+
+type ListProjectResp struct {
+	BaseResponse
+	Data       []ProjectItem `json:"data"`                    // 项目列表
+	TotalCount int           `json:"totalCount" example:"10"` // 项目总数
+}
+
+type GetProjectReq struct {
+	BaseRequest
+}
+
+type GetProjectResp struct {
+	BaseResponse
+	Data *ProjectItem `json:"data"` // 项目详情
+}
+
+type ProjectItem struct {
+	ResourceID   string `json:"resourceID" example:"project-k2jd8sm1qa"`  // 项目ID
+	Name         string `json:"name" example:"team-a"`                    // 名称
+	Description  string `json:"description" example:"clusters of team a"` // 描述
+	ClusterQuota int    `json:"clusterQuota" example:"10"`                // 集群数量配额，0 表示不限制
+	CreateTime   string `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+type CreateRoleReq struct {
+	BaseRequest
+	Name        string               `json:"name" example:"auditor" binding:"required"`                // 名称，支持 1～127 位字符，必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
+	Description string               `json:"description" example:"read only access to tasks"`          // 描述
+	Permissions []storage.Permission `json:"permissions" example:"task:read" binding:"required,min=1"` // 权限列表：*、cluster:read、cluster:create、cluster:update、cluster:delete、task:read、task:update、role:read、role:update、project:read、project:update
+}
+
+type CreateRoleResp struct {
+	BaseResponse
+	Data string `json:"data" example:"auditor"` // 角色名称
+}
+
+type ListRoleReq struct {
+	BaseRequest
+}
+
+type ListRoleResp struct {
+	BaseResponse
+	Data []RoleItem `json:"data"` // 角色列表
+}
+
+type RoleItem struct {
+	Name        string               `json:"name" example:"operator"`                                                         // 名称
+	Description string               `json:"description" example:"manage clusters, nodes and tasks except deleting clusters"` // 描述
+	Permissions []storage.Permission `json:"permissions" example:"cluster:read"`                                              // 权限列表
+	Builtin     bool                 `json:"builtin" example:"true"`                                                          // 是否内置，内置角色不允许删除
+	CreateTime  string               `json:"createTime" example:"2006-01-02 15:04:05"`                                        // 创建时间
+}
+
+type CreateRoleBindingReq struct {
+	BaseRequest
+	Subject   string `json:"subject" example:"alice" binding:"required"` // 调用者身份，API 令牌的 subject 或 JWT 的 sub 声明
+	Role      string `json:"role" example:"operator" binding:"required"` // 角色名称
+	ProjectID string `json:"projectId" example:"project-default"`        // 项目资源ID，为空时角色在全部项目中生效，限定项目的角色绑定不授予 role:read、role:update、project:update 权限
+}
+
+type CreateRoleBindingResp struct {
+	BaseResponse
+	Data uint64 `json:"data" example:"1"` // 角色绑定ID
+}
+
+type ListRoleBindingReq struct {
+	BaseRequest
+	Subject string `json:"subject" form:"subject"` // 调用者身份，为空时列出全部角色绑定
+}
+
+type ListRoleBindingResp struct {
+	BaseResponse
+	Data []RoleBindingItem `json:"data"` // 角色绑定列表
+}
+
+type RoleBindingItem struct {
+	ID         uint64 `json:"id" example:"1"`                           // 角色绑定ID
+	Subject    string `json:"subject" example:"alice"`                  // 调用者身份
+	Role       string `json:"role" example:"operator"`                  // 角色名称
+	ProjectID  string `json:"projectId" example:"project-default"`      // 项目资源ID，为空时角色在全部项目中生效
+	CreateTime string `json:"createTime" example:"2006-01-02 15:04:05"` // 创建时间
+}
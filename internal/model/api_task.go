@@ -0,0 +1,81 @@
+package model
+
+type ListTaskReq struct {
+	BaseRequest
+	PageNo    int      `json:"pageNo" form:"pageNo" binding:"omitempty,gte=1"`                // 分页页码，不能与 pageToken 同时使用
+	PageSize  int      `json:"pageSize" form:"pageSize" binding:"gte=1"`                      // 分页大小
+	PageToken string   `json:"pageToken" form:"pageToken"`                                    // 分页令牌，使用上一页返回的 nextPageToken 获取下一页
+	WithTotal bool     `json:"withTotal" form:"withTotal"`                                    // 是否计算总数，使用 pageNo 分页时总是计算
+	Filter    []string `json:"filter" form:"filter"`                                          // 过滤条件，格式为 field:op:value，可以传入多个，使用 AND 组合
+	SortBy    string   `json:"sortBy" form:"sortBy"`                                          // 排序字段，默认按提交顺序
+	SortOrder string   `json:"sortOrder" form:"sortOrder" binding:"omitempty,oneof=asc desc"` // 排序方向：asc、desc，默认为 desc
+}
+
+type ListTaskResp struct {
+	BaseResponse
+	Data          []TaskSummary `json:"data"`                              // 任务概要列表
+	TotalCount    *int          `json:"totalCount,omitempty" example:"10"` // 任务总数，不计算总数时为空
+	NextPageToken string        `json:"nextPageToken,omitempty"`           // 下一页的分页令牌，没有下一页时为空
+}
+
+type TaskSummary struct {
+	ID         uint64 `json:"id" example:"1"`                                           // 任务ID
+	ClusterID  string `json:"clusterId" example:"cluster-sedqqz7ka"`                    // 集群ID
+	Action     string `json:"action" example:"CreateCluster"`                           // 任务操作
+	Status     string `json:"status" example:"Retrying"`                                // 任务状态
+	RetryCount uint16 `json:"retryCount" example:"3"`                                   // 已重试次数
+	RetryLimit uint16 `json:"retryLimit" example:"150"`                                 // 重试次数限制
+	RetryAt    string `json:"retryAt" example:"2006-01-02 15:04:05"`                    // 下次重试时间，未重试时为空
+	CreateTime string `json:"createTime" example:"2006-01-02 15:04:05"`                 // 创建时间
+	UpdateTime string `json:"updateTime" example:"2006-01-02 15:04:05"`                 // 更新时间
+	RequestID  string `json:"requestId" example:"6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"` // 提交任务的请求ID
+}
+
+type GetTaskReq struct {
+	BaseRequest
+}
+
+type GetTaskResp struct {
+	BaseResponse
+	Data *TaskDetail `json:"data"` // 任务详情
+}
+
+type TaskDetail struct {
+	ID         uint64 `json:"id" example:"1"`                                           // 任务ID
+	ClusterID  string `json:"clusterId" example:"cluster-sedqqz7ka"`                    // 集群ID
+	Action     string `json:"action" example:"CreateCluster"`                           // 任务操作
+	Status     string `json:"status" example:"Retrying"`                                // 任务状态
+	RetryCount uint16 `json:"retryCount" example:"3"`                                   // 已重试次数
+	RetryLimit uint16 `json:"retryLimit" example:"150"`                                 // 重试次数限制
+	RetryAt    string `json:"retryAt" example:"2006-01-02 15:04:05"`                    // 下次重试时间，未重试时为空
+	NodeID     string `json:"nodeId" example:"node-k2jd8sm1qa"`                         // 节点任务操作的节点ID
+	Version    string `json:"version" example:"1.23.17"`                                // 升级任务的目标版本
+	Step       int    `json:"step" example:"2"`                                         // 已完成的步骤数，重试时从下一个步骤继续执行
+	CreateTime string `json:"createTime" example:"2006-01-02 15:04:05"`                 // 创建时间
+	UpdateTime string `json:"updateTime" example:"2006-01-02 15:04:05"`                 // 更新时间
+	RequestID  string `json:"requestId" example:"6893b1e9-da8f-4c6c-a161-eba4b81ea5b3"` // 提交任务的请求ID
+}
+
+type ListTaskLogReq struct {
+	BaseRequest
+	PageNo    int    `json:"pageNo" form:"pageNo" binding:"omitempty,gte=1"` // 分页页码，不能与 pageToken 同时使用
+	PageSize  int    `json:"pageSize" form:"pageSize" binding:"gte=1"`       // 分页大小
+	PageToken string `json:"pageToken" form:"pageToken"`                     // 分页令牌，使用上一页返回的 nextPageToken 获取下一页
+	WithTotal bool   `json:"withTotal" form:"withTotal"`                     // 是否计算总数，使用 pageNo 分页时总是计算
+}
+
+type ListTaskLogResp struct {
+	BaseResponse
+	Data          []TaskLogItem `json:"data"`                              // 任务日志列表，最新的日志在前
+	TotalCount    *int          `json:"totalCount,omitempty" example:"10"` // 日志总数，不计算总数时为空
+	NextPageToken string        `json:"nextPageToken,omitempty"`           // 下一页的分页令牌，没有下一页时为空
+}
+
+type TaskLogItem struct {
+	ID        uint64 `json:"id" example:"1"`                                             // 日志ID
+	Reason    string `json:"reason" example:"Retrying"`                                  // 原因
+	Step      string `json:"step" example:"init control plane"`                          // 步骤名称，任务状态变化的日志中为失败的步骤
+	Message   string `json:"message" example:"host 192.168.1.10: dial tcp: i/o timeout"` // 信息
+	StartTime string `json:"startTime" example:"2006-01-02 15:04:05"`                    // 起始时间
+	EndTime   string `json:"endTime" example:"2006-01-02 15:04:05"`                      // 结束时间
+}
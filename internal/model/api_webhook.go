@@ -0,0 +1,68 @@
+package model
+
+import (
+	"gitbub.com/wbuntu/gin-template/internal/storage"
+)
+
+type CreateWebhookReq struct {
+	BaseRequest
+	URL    string                     `json:"url" example:"https://example.com/hooks/cluster" binding:"required,url"`              // 投递地址，只支持 http 与 https，不允许解析到回环、内网、链路本地等非公网地址，不跟随重定向
+	Secret string                     `json:"secret" example:"0123456789abcdef" binding:"required,min=16,max=255"`                 // 签名密钥，至少 16 位，创建后不再返回
+	Events []storage.WebhookEventType `json:"events" example:"cluster.running,task.failed" binding:"required,min=1,dive,required"` // 订阅的事件类型：cluster.running、cluster.error、cluster.deleted、task.succeeded、task.failed、task.canceled
+}
+
+type CreateWebhookResp struct {
+	BaseResponse
+	Data string `json:"data" example:"webhook-k2jd8sm1qa"` // webhook 资源ID
+}
+
+type ListWebhookReq struct {
+	BaseRequest
+}
+
+type ListWebhookResp struct {
+	BaseResponse
+	Data []WebhookSummary `json:"data"` // webhook 列表
+}
+
+type WebhookSummary struct {
+	ResourceID string                     `json:"resourceID" example:"webhook-k2jd8sm1qa"`         // webhook 资源ID
+	URL        string                     `json:"url" example:"https://example.com/hooks/cluster"` // 投递地址
+	Events     []storage.WebhookEventType `json:"events" example:"cluster.running,task.failed"`    // 订阅的事件类型
+	CreateTime string                     `json:"createTime" example:"2006-01-02 15:04:05"`        // 创建时间
+}
+
+type DeleteWebhookReq struct {
+	BaseRequest
+}
+
+type DeleteWebhookResp struct {
+	BaseResponse
+}
+
+type ListWebhookDeliveryReq struct {
+	BaseRequest
+	PageNo    int    `json:"pageNo" form:"pageNo" binding:"omitempty,gte=1"` // 分页页码，不能与 pageToken 同时使用
+	PageSize  int    `json:"pageSize" form:"pageSize" binding:"gte=1"`       // 分页大小
+	PageToken string `json:"pageToken" form:"pageToken"`                     // 分页令牌，使用上一页返回的 nextPageToken 获取下一页
+	WithTotal bool   `json:"withTotal" form:"withTotal"`                     // 是否计算总数，使用 pageNo 分页时总是计算
+}
+
+type ListWebhookDeliveryResp struct {
+	BaseResponse
+	Data          []WebhookDelivery `json:"data"`                              // 投递记录列表，最新的记录在前
+	TotalCount    *int              `json:"totalCount,omitempty" example:"10"` // 投递记录总数，不计算总数时为空
+	NextPageToken string            `json:"nextPageToken,omitempty"`           // 下一页的分页令牌，没有下一页时为空
+}
+
+type WebhookDelivery struct {
+	ID              uint64                   `json:"id" example:"1"`                                // 投递ID，与请求头 X-Webhook-Delivery 相同
+	EventID         uint64                   `json:"eventId" example:"1"`                           // 事件ID
+	EventType       storage.WebhookEventType `json:"eventType" example:"cluster.running"`           // 事件类型
+	Status          string                   `json:"status" example:"Succeeded"`                    // 投递状态：Pending、Succeeded、Failed
+	Attempts        uint16                   `json:"attempts" example:"1"`                          // 已投递次数
+	NextAttemptTime string                   `json:"nextAttemptTime" example:"2006-01-02 15:04:05"` // 下次投递时间，等待投递时有效
+	LastError       string                   `json:"lastError,omitempty"`                           // 最近一次投递的错误
+	CreateTime      string                   `json:"createTime" example:"2006-01-02 15:04:05"`      // 创建时间
+	UpdateTime      string                   `json:"updateTime" example:"2006-01-02 15:04:05"`      // 更新时间
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultCodecs 路由未指定编码时允许的编码，YAML 与 JSON 使用相同的字段名，所有请求与响应都支持
+var DefaultCodecs = []Codec{CodecJSON, CodecYaml}
+
+// 编码对应的 MIME 类型，第一个用于响应，其他类型只用于识别请求
+var codecMIMETypes = map[Codec][]string{
+	CodecJSON:  {binding.MIMEJSON, "application/merge-patch+json"},
+	CodecYaml:  {binding.MIMEYAML, "application/yaml", "text/yaml"},
+	CodecProto: {binding.MIMEPROTOBUF, "application/protobuf"},
+}
+
+// ContentType 响应的 Content-Type
+func (c Codec) ContentType() string {
+	switch c {
+	case CodecJSON, CodecYaml:
+		return codecMIMETypes[c][0] + "; charset=utf-8"
+	case CodecProto:
+		return codecMIMETypes[c][0]
+	default:
+		return ""
+	}
+}
+
+// NegotiateRequestCodec 根据 Content-Type 头部在允许的编码中选择请求体的编码，没有请求体或未设置时使用默认编码
+func NegotiateRequestCodec(g *gin.Context, allowed []Codec) (Codec, bool) {
+	contentType := strings.ToLower(g.ContentType())
+	if !hasRequestBody(g.Request) || len(contentType) == 0 {
+		return allowed[0], true
+	}
+	for _, c := range allowed {
+		for _, item := range codecMIMETypes[c] {
+			if item == contentType {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// NegotiateResponseCodec 根据 Accept 头部在允许的编码中选择响应的编码，未设置或接受任意类型时使用默认编码
+func NegotiateResponseCodec(g *gin.Context, allowed []Codec) (Codec, bool) {
+	offered := make([]string, 0, len(allowed)*2)
+	for _, c := range allowed {
+		offered = append(offered, codecMIMETypes[c]...)
+	}
+	format := g.NegotiateFormat(offered...)
+	for _, c := range allowed {
+		for _, item := range codecMIMETypes[c] {
+			if item == format {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// CheckCodecs 校验路由允许的编码，允许 protobuf 时请求与响应都需要有对应的 protobuf 消息
+func (r *Route) CheckCodecs() error {
+	for _, c := range r.Codecs {
+		if _, ok := codecMIMETypes[c]; !ok {
+			return errors.Errorf("unsupported codec: %s", c)
+		}
+		if c != CodecProto {
+			continue
+		}
+		ctrl := r.Factory()
+		ctrl.Init()
+		if _, ok := newProtoMessage(ctrl.GetRequest()); !ok {
+			return errors.Errorf("no protobuf message for %T", ctrl.GetRequest())
+		}
+		if _, ok := newProtoMessage(ctrl.GetResponse()); !ok {
+			return errors.Errorf("no protobuf message for %T", ctrl.GetResponse())
+		}
+	}
+	return nil
+}
+
+// hasRequestBody 请求是否携带请求体，只有 POST、PATCH、PUT 请求从请求体中解析参数
+func hasRequestBody(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPatch, http.MethodPut:
+		return r.ContentLength != 0
+	default:
+		return false
+	}
+}
+
+// bindYAML 将 YAML 转换为 JSON 后解析，与 JSON 使用相同的字段名
+func bindYAML(g *gin.Context, req Request) error {
+	body, err := io.ReadAll(g.Request.Body)
+	if err != nil {
+		return errors.Wrap(err, "read body")
+	}
+	data, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, req)
+}
+
+func marshalYAML(resp Response) ([]byte, error) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// protoMessages 请求与响应对应的 protobuf 消息，消息字段的 JSON 名称与 json tag 相同，通过 protojson 转换
+var protoMessages = map[reflect.Type]func() proto.Message{
+	reflect.TypeOf(BaseRequest{}):       func() proto.Message { return new(pb.BaseRequest) },
+	reflect.TypeOf(BaseResponse{}):      func() proto.Message { return new(pb.BaseResponse) },
+	reflect.TypeOf(CreateClusterReq{}):  func() proto.Message { return new(pb.CreateClusterReq) },
+	reflect.TypeOf(CreateClusterResp{}): func() proto.Message { return new(pb.CreateClusterResp) },
+	reflect.TypeOf(UpgradeClusterReq{}): func() proto.Message { return new(pb.UpgradeClusterReq) },
+	reflect.TypeOf(ListClusterReq{}):    func() proto.Message { return new(pb.ListClusterReq) },
+	reflect.TypeOf(ListClusterResp{}):   func() proto.Message { return new(pb.ListClusterResp) },
+	reflect.TypeOf(GetClusterReq{}):     func() proto.Message { return new(pb.GetClusterReq) },
+	reflect.TypeOf(GetClusterResp{}):    func() proto.Message { return new(pb.GetClusterResp) },
+	reflect.TypeOf(ListTaskReq{}):       func() proto.Message { return new(pb.ListTaskReq) },
+	reflect.TypeOf(ListTaskResp{}):      func() proto.Message { return new(pb.ListTaskResp) },
+	reflect.TypeOf(GetTaskReq{}):        func() proto.Message { return new(pb.GetTaskReq) },
+	reflect.TypeOf(GetTaskResp{}):       func() proto.Message { return new(pb.GetTaskResp) },
+	reflect.TypeOf(ListTaskLogReq{}):    func() proto.Message { return new(pb.ListTaskLogReq) },
+	reflect.TypeOf(ListTaskLogResp{}):   func() proto.Message { return new(pb.ListTaskLogResp) },
+	reflect.TypeOf(CheckCIDRReq{}):      func() proto.Message { return new(pb.CheckCIDRReq) },
+	reflect.TypeOf(CheckCIDRResp{}):     func() proto.Message { return new(pb.CheckCIDRResp) },
+}
+
+func newProtoMessage(v interface{}) (proto.Message, bool) {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fn, ok := protoMessages[t]
+	if !ok {
+		return nil, false
+	}
+	return fn(), true
+}
+
+// bindProto 将 protobuf 消息转换为 JSON 后解析，与 JSON 使用相同的校验规则
+func bindProto(g *gin.Context, req Request) error {
+	msg, ok := newProtoMessage(req)
+	if !ok {
+		return errors.Errorf("protobuf not supported for %T", req)
+	}
+	body, err := io.ReadAll(g.Request.Body)
+	if err != nil {
+		return errors.Wrap(err, "read body")
+	}
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return err
+	}
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, req)
+}
+
+// marshalProto 将响应转换为 JSON 后写入 protobuf 消息，忽略消息中没有的字段
+func marshalProto(resp Response) ([]byte, error) {
+	msg, ok := newProtoMessage(resp)
+	if !ok {
+		return nil, errors.Errorf("protobuf not supported for %T", resp)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(msg)
+}
+
+func decodeJSON(data []byte, req Request) error {
+	if err := json.Unmarshal(data, req); err != nil {
+		return err
+	}
+	if binding.Validator != nil {
+		return binding.Validator.ValidateStruct(req)
+	}
+	return nil
+}
@@ -21,6 +21,22 @@ func (c Code) Message() string {
 		msg = "资源已存在"
 	case CodeForbidOperate:
 		msg = "禁止操作"
+	case CodeUnauthorized:
+		msg = "未认证"
+	case CodeForbidden:
+		msg = "无权限"
+	case CodeQuotaExceeded:
+		msg = "超出配额"
+	case CodeConflict:
+		msg = "资源冲突"
+	case CodeIdempotencyMismatch:
+		msg = "幂等键冲突"
+	case CodeIdempotencyInProgress:
+		msg = "请求处理中"
+	case CodeNotAcceptable:
+		msg = "不支持的响应格式"
+	case CodeUnsupportedMediaType:
+		msg = "不支持的请求格式"
 	default:
 		msg = "Unknown"
 	}
@@ -36,12 +52,20 @@ func response(code Code, messages ...string) BaseResponse {
 }
 
 const (
-	CodeSuccess       = Code("Success")       // 调用成功
-	CodeInternalError = Code("InternalError") // 内部错误
-	CodeParamError    = Code("ParamError")    // 参数错误
-	CodeNotExists     = Code("NotExists")     // 资源不存在
-	CodeAlreadyExists = Code("AlreadyExists") // 资源已存在
-	CodeForbidOperate = Code("ForbidOperate") // 禁止操作
+	CodeSuccess               = Code("Success")               // 调用成功
+	CodeInternalError         = Code("InternalError")         // 内部错误
+	CodeParamError            = Code("ParamError")            // 参数错误
+	CodeNotExists             = Code("NotExists")             // 资源不存在
+	CodeAlreadyExists         = Code("AlreadyExists")         // 资源已存在
+	CodeForbidOperate         = Code("ForbidOperate")         // 禁止操作
+	CodeUnauthorized          = Code("Unauthorized")          // 未认证
+	CodeForbidden             = Code("Forbidden")             // 无权限
+	CodeQuotaExceeded         = Code("QuotaExceeded")         // 超出配额
+	CodeConflict              = Code("Conflict")              // 资源冲突
+	CodeIdempotencyMismatch   = Code("IdempotencyMismatch")   // 幂等键冲突
+	CodeIdempotencyInProgress = Code("IdempotencyInProgress") // 请求处理中
+	CodeNotAcceptable         = Code("NotAcceptable")         // 不支持的响应格式
+	CodeUnsupportedMediaType  = Code("UnsupportedMediaType")  // 不支持的请求格式
 )
 
 var (
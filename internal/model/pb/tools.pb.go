@@ -0,0 +1,268 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: gintemplate/v1/tools.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CheckCIDRReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ipv6            bool   `protobuf:"varint,1,opt,name=ipv6,proto3" json:"ipv6,omitempty"`                                               // ipv6开关
+	PodCidr         string `protobuf:"bytes,2,opt,name=pod_cidr,json=podCidr,proto3" json:"pod_cidr,omitempty"`                           // 容器组网段
+	ServiceCidr     string `protobuf:"bytes,3,opt,name=service_cidr,json=serviceCidr,proto3" json:"service_cidr,omitempty"`               // 服务网段
+	PodCidrIpv6     string `protobuf:"bytes,4,opt,name=pod_cidr_ipv6,json=podCidrIpv6,proto3" json:"pod_cidr_ipv6,omitempty"`             // ipv6容器组网段，ipv6为true时有效
+	ServiceCidrIpv6 string `protobuf:"bytes,5,opt,name=service_cidr_ipv6,json=serviceCidrIpv6,proto3" json:"service_cidr_ipv6,omitempty"` // ipv6服务网段，ipv6为true时有效
+}
+
+func (x *CheckCIDRReq) Reset() {
+	*x = CheckCIDRReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gintemplate_v1_tools_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckCIDRReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCIDRReq) ProtoMessage() {}
+
+func (x *CheckCIDRReq) ProtoReflect() protoreflect.Message {
+	mi := &file_gintemplate_v1_tools_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCIDRReq.ProtoReflect.Descriptor instead.
+func (*CheckCIDRReq) Descriptor() ([]byte, []int) {
+	return file_gintemplate_v1_tools_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckCIDRReq) GetIpv6() bool {
+	if x != nil {
+		return x.Ipv6
+	}
+	return false
+}
+
+func (x *CheckCIDRReq) GetPodCidr() string {
+	if x != nil {
+		return x.PodCidr
+	}
+	return ""
+}
+
+func (x *CheckCIDRReq) GetServiceCidr() string {
+	if x != nil {
+		return x.ServiceCidr
+	}
+	return ""
+}
+
+func (x *CheckCIDRReq) GetPodCidrIpv6() string {
+	if x != nil {
+		return x.PodCidrIpv6
+	}
+	return ""
+}
+
+func (x *CheckCIDRReq) GetServiceCidrIpv6() string {
+	if x != nil {
+		return x.ServiceCidrIpv6
+	}
+	return ""
+}
+
+type CheckCIDRResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code      string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`                            // 响应码
+	Message   string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`                      // 响应消息
+	RequestId string `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // 请求ID
+}
+
+func (x *CheckCIDRResp) Reset() {
+	*x = CheckCIDRResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gintemplate_v1_tools_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckCIDRResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckCIDRResp) ProtoMessage() {}
+
+func (x *CheckCIDRResp) ProtoReflect() protoreflect.Message {
+	mi := &file_gintemplate_v1_tools_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckCIDRResp.ProtoReflect.Descriptor instead.
+func (*CheckCIDRResp) Descriptor() ([]byte, []int) {
+	return file_gintemplate_v1_tools_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckCIDRResp) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CheckCIDRResp) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CheckCIDRResp) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+var File_gintemplate_v1_tools_proto protoreflect.FileDescriptor
+
+var file_gintemplate_v1_tools_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x67, 0x69, 0x6e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x2f, 0x76, 0x31,
+	0x2f, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x67, 0x69,
+	0x6e, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xb0, 0x01, 0x0a,
+	0x0c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x49, 0x44, 0x52, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a,
+	0x04, 0x69, 0x70, 0x76, 0x36, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x69, 0x70, 0x76,
+	0x36, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x6f, 0x64, 0x5f, 0x63, 0x69, 0x64, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x6f, 0x64, 0x43, 0x69, 0x64, 0x72, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x69, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x69, 0x64, 0x72, 0x12,
+	0x22, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x5f, 0x63, 0x69, 0x64, 0x72, 0x5f, 0x69, 0x70, 0x76, 0x36,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x6f, 0x64, 0x43, 0x69, 0x64, 0x72, 0x49,
+	0x70, 0x76, 0x36, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63,
+	0x69, 0x64, 0x72, 0x5f, 0x69, 0x70, 0x76, 0x36, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x43, 0x69, 0x64, 0x72, 0x49, 0x70, 0x76, 0x36, 0x22,
+	0x5c, 0x0a, 0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x49, 0x44, 0x52, 0x52, 0x65, 0x73, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x42, 0x35, 0x5a,
+	0x33, 0x67, 0x69, 0x74, 0x62, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x77, 0x62, 0x75, 0x6e,
+	0x74, 0x75, 0x2f, 0x67, 0x69, 0x6e, 0x2d, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x2f,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x70,
+	0x62, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gintemplate_v1_tools_proto_rawDescOnce sync.Once
+	file_gintemplate_v1_tools_proto_rawDescData = file_gintemplate_v1_tools_proto_rawDesc
+)
+
+func file_gintemplate_v1_tools_proto_rawDescGZIP() []byte {
+	file_gintemplate_v1_tools_proto_rawDescOnce.Do(func() {
+		file_gintemplate_v1_tools_proto_rawDescData = protoimpl.X.CompressGZIP(file_gintemplate_v1_tools_proto_rawDescData)
+	})
+	return file_gintemplate_v1_tools_proto_rawDescData
+}
+
+var file_gintemplate_v1_tools_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_gintemplate_v1_tools_proto_goTypes = []interface{}{
+	(*CheckCIDRReq)(nil),  // 0: gintemplate.v1.CheckCIDRReq
+	(*CheckCIDRResp)(nil), // 1: gintemplate.v1.CheckCIDRResp
+}
+var file_gintemplate_v1_tools_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_gintemplate_v1_tools_proto_init() }
+func file_gintemplate_v1_tools_proto_init() {
+	if File_gintemplate_v1_tools_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gintemplate_v1_tools_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckCIDRReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gintemplate_v1_tools_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckCIDRResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gintemplate_v1_tools_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gintemplate_v1_tools_proto_goTypes,
+		DependencyIndexes: file_gintemplate_v1_tools_proto_depIdxs,
+		MessageInfos:      file_gintemplate_v1_tools_proto_msgTypes,
+	}.Build()
+	File_gintemplate_v1_tools_proto = out.File
+	file_gintemplate_v1_tools_proto_rawDesc = nil
+	file_gintemplate_v1_tools_proto_goTypes = nil
+	file_gintemplate_v1_tools_proto_depIdxs = nil
+}
@@ -18,7 +18,31 @@ type Config struct {
 		TLSAddr string `mapstructure:"tls_addr"`
 		TLSCrt  string `mapstructure:"tls_crt"`
 		TLSKey  string `mapstructure:"tls_key"`
+		// 幂等键的有效期
+		IdempotencyTTL time.Duration `mapstructure:"idempotency_ttl"`
+		// 分页令牌的签名密钥
+		PageTokenSecret string `mapstructure:"page_token_secret"`
+		// gRPC 服务的监听地址，为空时不启动
+		GRPCAddr string `mapstructure:"grpc_addr"`
 	} `mapstructure:"api"`
+	Auth struct {
+		Enable       bool   `mapstructure:"enable"`
+		JWTAlgorithm string `mapstructure:"jwt_algorithm"`
+		JWTSecret    string `mapstructure:"jwt_secret"`
+		JWTPublicKey string `mapstructure:"jwt_public_key"`
+		JWTIssuer    string `mapstructure:"jwt_issuer"`
+		JWTAudience  string `mapstructure:"jwt_audience"`
+	} `mapstructure:"auth"`
+	Audit struct {
+		Enable bool   `mapstructure:"enable"`
+		File   string `mapstructure:"file"`
+	} `mapstructure:"audit"`
+	Terminal struct {
+		// 无输入时断开的时长
+		IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+		// 录像目录，为空时不录像
+		RecordDir string `mapstructure:"record_dir"`
+	} `mapstructure:"terminal"`
 	DB struct {
 		Type           string        `mapstructure:"type"`
 		DSN            string        `mapstructure:"dsn"`
@@ -26,6 +50,8 @@ type Config struct {
 		MaxActiveConns int           `mapstructure:"max_active_conns"`
 		ConnLifetime   time.Duration `mapstructure:"conn_lifetime"`
 		ConnIdletime   time.Duration `mapstructure:"conn_idletime"`
+		// 加密主机密码的密钥
+		CredentialKey string `mapstructure:"credential_key"`
 	} `mapstructure:"db"`
 	KVDB struct {
 		Type           string        `mapstructure:"type"`
@@ -35,4 +61,10 @@ type Config struct {
 		ConnLifetime   time.Duration `mapstructure:"conn_lifetime"`
 		ConnIdletime   time.Duration `mapstructure:"conn_idletime"`
 	} `mapstructure:"kv_db"`
+	Daemon struct {
+		Provisioner    string        `mapstructure:"provisioner"`
+		ResyncInterval time.Duration `mapstructure:"resync_interval"`
+		// 已删除集群与任务的保留时长
+		Retention time.Duration `mapstructure:"retention"`
+	} `mapstructure:"daemon"`
 }
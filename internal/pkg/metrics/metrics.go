@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// namespace 指标名称前缀
+const namespace = "gin_template"
+
+// registry 独立的指标注册表，避免引入依赖库注册到默认注册表中的指标
+var registry = prometheus.NewRegistry()
+
+var (
+	// HTTP 请求数量，按路由、方法与响应码统计
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route, method and response code.",
+	}, []string{"route", "method", "code"})
+	// HTTP 请求耗时，按路由、方法与响应码统计
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds by route, method and response code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+	// 当前实例是否为主节点
+	isLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "is_leader",
+		Help:      "Whether this instance is the leader running the daemon queues.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		isLeader,
+	)
+	registerWorkqueueMetrics()
+	// 工作队列在创建时从 provider 获取指标，需要在创建队列前设置
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// Handler 返回 Prometheus 指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// MustRegister 注册自定义指标，重复注册时 panic
+func MustRegister(cs ...prometheus.Collector) {
+	registry.MustRegister(cs...)
+}
+
+// ObserveHTTPRequest 记录一次 HTTP 请求
+func ObserveHTTPRequest(route string, method string, code string, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(route, method, code).Inc()
+	httpRequestDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// SetLeader 设置当前实例是否为主节点
+func SetLeader(leading bool) {
+	if leading {
+		isLeader.Set(1)
+	} else {
+		isLeader.Set(0)
+	}
+}
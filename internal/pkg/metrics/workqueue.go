@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// 工作队列指标，按队列名称统计，指标定义参考 k8s.io/component-base/metrics/prometheus/workqueue
+var (
+	workqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of workqueue.",
+	}, []string{"name"})
+	workqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "adds_total",
+		Help:      "Total number of adds handled by workqueue.",
+	}, []string{"name"})
+	workqueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "queue_duration_seconds",
+		Help:      "How long in seconds an item stays in workqueue before being requested.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workqueueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "work_duration_seconds",
+		Help:      "How long in seconds processing an item from workqueue takes.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"name"})
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "unfinished_work_seconds",
+		Help:      "How many seconds of work has been done that is in progress and hasn't been observed by work_duration.",
+	}, []string{"name"})
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "longest_running_processor_seconds",
+		Help:      "How many seconds has the longest running processor for workqueue been running.",
+	}, []string{"name"})
+	workqueueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Total number of delayed re-adds handled by workqueue.",
+	}, []string{"name"})
+)
+
+func registerWorkqueueMetrics() {
+	registry.MustRegister(
+		workqueueDepth,
+		workqueueAdds,
+		workqueueLatency,
+		workqueueWorkDuration,
+		workqueueUnfinishedWork,
+		workqueueLongestRunningProcessor,
+		workqueueRetries,
+	)
+}
+
+// workqueueMetricsProvider 实现 workqueue.MetricsProvider，将工作队列指标导出到 Prometheus
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
@@ -10,7 +10,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 )
 
-// NextDurationNone 不执行重试
+// NextDurationNone 不重新加入队列，由生产者在下次触发时重新加入
 const NextDurationNone = time.Duration(-1)
 
 func NewDeleyQueue(ctx context.Context, name string, producerInterval time.Duration, consumerCount int, producer func(context.Context) ([]string, error), consumer func(context.Context, string) (time.Duration, error)) *DelayQueue {
@@ -39,7 +39,7 @@ type DelayQueue struct {
 	queue workqueue.DelayingInterface
 	// 生产者
 	producer func(context.Context) ([]string, error)
-	// 消费者
+	// 消费者，返回下次执行的延迟，NextDurationNone 表示不重新加入队列
 	consumer func(context.Context, string) (time.Duration, error)
 }
 
@@ -68,6 +68,11 @@ func (q *DelayQueue) Run() {
 	<-q.ctx.Done()
 }
 
+// Add 立即将 key 加入队列，已在队列中的 key 不会重复加入
+func (q *DelayQueue) Add(key string) {
+	q.queue.Add(key)
+}
+
 func (q *DelayQueue) worker() {
 	for q.processNextWorkItem() {
 	}
@@ -82,10 +87,10 @@ func (q *DelayQueue) processNextWorkItem() bool {
 	nextDuration, err := q.consumer(q.ctx, key.(string))
 	if err != nil {
 		log.G(q.ctx).WithField("key", key.(string)).Errorf("consumer failed: %s", err)
-		// 只处理需要重试的任务
-		if nextDuration != NextDurationNone {
-			q.queue.AddAfter(key, nextDuration)
-		}
+	}
+	// 消费者返回下次执行的延迟时，延迟后重新加入队列，与是否出错无关
+	if nextDuration != NextDurationNone {
+		q.queue.AddAfter(key, nextDuration)
 	}
 	return true
 }
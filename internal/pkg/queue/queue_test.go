@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -38,3 +39,34 @@ func TestQueue(t *testing.T) {
 	t.Logf("stop running queue: pending keys count: %d", queue.queue.Len())
 	cancel()
 }
+
+func TestQueueRequeue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var produced, consumed int32
+	queue := NewDeleyQueue(
+		ctx,
+		"test-requeue",
+		time.Millisecond*100,
+		1,
+		func(ctx context.Context) ([]string, error) {
+			// 只生产一次，之后的执行依赖消费者返回的延迟
+			if atomic.AddInt32(&produced, 1) > 1 {
+				return nil, nil
+			}
+			return []string{"key"}, nil
+		},
+		func(ctx context.Context, s string) (time.Duration, error) {
+			// 第一次执行返回延迟，未返回错误也需要重新加入队列
+			if atomic.AddInt32(&consumed, 1) == 1 {
+				return time.Millisecond * 200, nil
+			}
+			return NextDurationNone, nil
+		},
+	)
+	go queue.Run()
+	time.Sleep(time.Second * 1)
+	if count := atomic.LoadInt32(&consumed); count != 2 {
+		t.Fatalf("consumed count: %d, expected: 2", count)
+	}
+}
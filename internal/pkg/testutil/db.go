@@ -0,0 +1,36 @@
+package testutil
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gorm.io/gorm"
+)
+
+// SetupDB 在临时目录中创建 sqlite 数据库，依次调用 setups 初始化存储并执行迁移，测试结束时关闭 db 返回的连接
+// storage 包内的测试无法导入依赖 storage 的包，因此由调用方传入 storage.DB、storage.Setup 与 storage.Migrate
+func SetupDB(t testing.TB, db func() *gorm.DB, setups ...func(ctx context.Context, cfg *config.Config) error) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.General.LogFormat = "text"
+	cfg.General.EnableDB = true
+	cfg.DB.Type = "sqlite"
+	cfg.DB.DSN = filepath.Join(t.TempDir(), "sqlite.db")
+	cfg.DB.MaxActiveConns = 1
+	if err := log.Setup(cfg); err != nil {
+		t.Fatalf("setup log: %s", err)
+	}
+	for _, setup := range setups {
+		if err := setup(context.Background(), cfg); err != nil {
+			t.Fatalf("setup storage: %s", err)
+		}
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db().DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
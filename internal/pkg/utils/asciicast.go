@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// AsciicastWriter 以 asciicast v2 格式记录终端输出，可以使用 asciinema play 回放
+type AsciicastWriter struct {
+	mu      sync.Mutex
+	file    *os.File
+	start   time.Time
+	pending []byte // 末尾不完整的 UTF-8 字符，与下一次输出合并
+}
+
+// asciicastHeader asciicast v2 的首行
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewAsciicastWriter 创建录像文件并写入首行，目录不存在时自动创建
+func NewAsciicastWriter(path string, cols, rows int, term string, title string) (*AsciicastWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errors.Wrap(err, "create record dir")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "create record file")
+	}
+	w := &AsciicastWriter{file: file, start: time.Now()}
+	header, err := json.Marshal(&asciicastHeader{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: w.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": term},
+	})
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrap(err, "marshal header")
+	}
+	if _, err := file.Write(append(header, '\n')); err != nil {
+		file.Close()
+		return nil, errors.Wrap(err, "write header")
+	}
+	return w, nil
+}
+
+// Output 记录终端输出
+func (w *AsciicastWriter) Output(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data = append(w.pending, data...)
+	n := len(data)
+	// 输出可能在多字节字符中间截断，不完整的字符留到下一次输出
+	for i := 1; i <= utf8.UTFMax && i <= n; i++ {
+		if utf8.RuneStart(data[n-i]) {
+			if !utf8.FullRune(data[n-i:]) {
+				n -= i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[n:]...)
+	if n == 0 {
+		return nil
+	}
+	return w.writeEvent("o", string(data[:n]))
+}
+
+// Resize 记录终端大小变化
+func (w *AsciicastWriter) Resize(cols, rows int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writeEvent("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// writeEvent 写入一行事件：[距离开始的秒数, 类型, 数据]
+func (w *AsciicastWriter) writeEvent(code string, data string) error {
+	line, err := json.Marshal([]interface{}{time.Since(w.start).Seconds(), code, data})
+	if err != nil {
+		return errors.Wrap(err, "marshal event")
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "write event")
+	}
+	return nil
+}
+
+// Close 写入剩余的输出并关闭文件
+func (w *AsciicastWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		w.writeEvent("o", string(w.pending))
+		w.pending = nil
+	}
+	return w.file.Close()
+}
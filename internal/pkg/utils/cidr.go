@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"net"
 
 	"github.com/pkg/errors"
@@ -156,3 +157,48 @@ func CheckVPCCIDR(vpcCIDR string, podCIDR string, serviceCIDR string) error {
 	}
 	return nil
 }
+
+// IsPrivate 等方法未覆盖的保留网段：本网络、运营商级 NAT、IETF 协议分配、基准测试与保留地址
+var reservedIPNets = func() []*net.IPNet {
+	items := []*net.IPNet{}
+	for _, cidr := range []string{"0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4"} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		items = append(items, ipNet)
+	}
+	return items
+}()
+
+// IsPublicIP 检查IP是否为公网地址，回环、私有、链路本地、组播、未指定与保留地址都不是公网地址
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, ipNet := range reservedIPNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPublicHost 解析主机名，要求解析得到的所有地址都是公网地址
+func CheckPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !IsPublicIP(ip) {
+			return errors.Errorf("address %s is not allowed", host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return errors.Wrapf(err, "resolve host %s", host)
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return errors.Errorf("host %s resolves to address %s which is not allowed", host, addr.IP)
+		}
+	}
+	return nil
+}
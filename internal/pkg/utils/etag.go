@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FormatETag 将资源版本格式化为强校验的 ETag，例如 "3"
+func FormatETag(version uint64) string {
+	return strconv.Quote(strconv.FormatUint(version, 10))
+}
+
+// MatchETag 检查 If-Match 头部是否匹配资源版本，头部为空或为 * 时匹配任意版本，支持逗号分隔的多个 ETag，弱校验的 ETag 不匹配
+func MatchETag(ifMatch string, version uint64) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if len(ifMatch) == 0 || ifMatch == "*" {
+		return true
+	}
+	etag := FormatETag(version)
+	for _, item := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(item) == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
@@ -28,6 +29,10 @@ const (
 	CustomResolveKey      HTTPContextKey = "CustomResolveKey"
 	SkipUnmarshalKey      HTTPContextKey = "SkipUnmarshalKey"
 	ResponseStatusCodeKey HTTPContextKey = "ResponseStatusCodeKey"
+	// AcceptSuccessStatusKey 接受任意 2xx 响应状态码，用于投递到不同实现的外部接口
+	AcceptSuccessStatusKey HTTPContextKey = "AcceptSuccessStatusKey"
+	// OmitErrorBodyKey 响应状态码不符合预期时，错误中只包含状态行，不包含响应内容
+	OmitErrorBodyKey HTTPContextKey = "OmitErrorBodyKey"
 )
 
 // NewHTTPClient 根据提供的参数构建专用的HTTPClient
@@ -95,6 +100,24 @@ func (c *HTTPClient) SetRequestTimeout(timeout time.Duration) {
 	c.timeout = timeout
 }
 
+// DenyPrivateNetwork 只允许连接公网地址，不跟随重定向，用于请求用户提供的地址
+// 在建立连接时检查解析后的地址，避免通过 DNS 重新绑定访问内网
+func (c *HTTPClient) DenyPrivateNetwork() {
+	c.dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return errors.Wrap(err, "split host and port")
+		}
+		if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+			return errors.Errorf("address %s is not allowed", host)
+		}
+		return nil
+	}
+	c.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+}
+
 // AddRequestHeader 添加请求头
 func (c *HTTPClient) AddRequestHeader(k, v string) {
 	c.headers[k] = v
@@ -244,6 +267,13 @@ func (c *HTTPClient) do(ctx context.Context, method string, path string, request
 	case http.MethodPost, http.MethodPut, http.MethodPatch:
 		req.Header.Set("Content-Type", "application/json")
 	}
+	// 修改类请求自动重试时使用相同的幂等键，避免重复执行
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if len(req.Header.Get(HeaderIdempotencyKey)) == 0 {
+			req.Header.Set(HeaderIdempotencyKey, UUID())
+		}
+	}
 	req.Header.Set("Accept", "application/json")
 	if c.debug {
 		log.Infof("request %s: \n\n%s %s\n\n", reqUUID, method, requestURL)
@@ -293,9 +323,13 @@ func (c *HTTPClient) do(ctx context.Context, method string, path string, request
 	} else if c.responseStatusCodeFn != nil {
 		expectedStatusCode = c.responseStatusCodeFn(reqCtx, req)
 	}
-	if resp.StatusCode != expectedStatusCode {
+	accepted := resp.StatusCode == expectedStatusCode
+	if acceptSuccess := reqCtx.Value(AcceptSuccessStatusKey); acceptSuccess != nil {
+		accepted = resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
+	}
+	if !accepted {
 		errStr := fmt.Sprintf("%s: %s: status: %s:", req.Method, req.URL, resp.Status)
-		if len(respData) > 0 {
+		if omitBody := reqCtx.Value(OmitErrorBodyKey); len(respData) > 0 && omitBody == nil {
 			errStr += fmt.Sprintf(" msg: %s", string(respData))
 		}
 		return errors.New(errStr)
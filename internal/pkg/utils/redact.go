@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue 脱敏后的字段值
+const RedactedValue = "******"
+
+// 敏感字段名包含的关键字，匹配时忽略大小写
+var sensitiveKeywords = []string{"password", "passwd", "secret", "token", "privatekey", "credential", "authorization"}
+
+// RedactJSON 将 JSON 中字段名包含敏感关键字的值替换为 RedactedValue，递归处理嵌套的对象与数组
+func RedactJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(v))
+}
+
+func redactValue(v interface{}) interface{} {
+	switch item := v.(type) {
+	case map[string]interface{}:
+		for k, value := range item {
+			if isSensitiveKey(k) {
+				item[k] = RedactedValue
+			} else {
+				item[k] = redactValue(value)
+			}
+		}
+	case []interface{}:
+		for i := range item {
+			item[i] = redactValue(item[i])
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, keyword := range sensitiveKeywords {
+		if strings.Contains(k, keyword) {
+			return true
+		}
+	}
+	return false
+}
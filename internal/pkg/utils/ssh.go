@@ -2,8 +2,10 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"golang.org/x/crypto/ssh"
+	"io"
 	"net"
 	"strconv"
 	"strings"
@@ -11,6 +13,60 @@ import (
 
 // ExeSshCmd runs command inside a VM, via SSH, and returns the command output.
 func ExeSshCmd(ip string, port int, password, command string) (string, error) {
+	return ExeSshCmdContext(context.Background(), ip, port, password, command)
+}
+
+// ExeSshCmdContext runs command like ExeSshCmd, the connection is closed and
+// the command is interrupted when ctx is done.
+func ExeSshCmdContext(ctx context.Context, ip string, port int, password, command string) (string, error) {
+	connection, err := DialSsh(ctx, ip, port, password)
+	if err != nil {
+		return "", err
+	}
+	defer connection.Close()
+
+	// close the connection when ctx is done, which interrupts the running command
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			connection.Close()
+		case <-stop:
+		}
+	}()
+
+	session, err := connection.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("ssh: failed to create session, error: %s", err.Error())
+	}
+	defer session.Close()
+
+	var b bytes.Buffer
+	session.Stdout = &b
+	if err := session.Run(command); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		// the command line may contain tokens or keys, it is not included in the error
+		return "", fmt.Errorf("ssh: failed to run command, error: %s", err.Error())
+	}
+
+	output := strings.Trim(b.String(), "\n")
+
+	return output, nil
+}
+
+// ShellQuote quotes s as a single word for a POSIX shell, every value that
+// comes from a request or a remote host must be quoted before it is
+// interpolated into a command.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// DialSsh connects to a VM as root with password, the handshake is
+// interrupted when ctx is done. The caller should close the client.
+func DialSsh(ctx context.Context, ip string, port int, password string) (*ssh.Client, error) {
 	sshConfig := &ssh.ClientConfig{
 		User: "root",
 		Auth: []ssh.AuthMethod{
@@ -23,24 +79,102 @@ func ExeSshCmd(ip string, port int, password, command string) (string, error) {
 
 	hostAddress := strings.Join([]string{ip, strconv.Itoa(port)}, ":")
 
-	connection, err := ssh.Dial("tcp", hostAddress, sshConfig)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", hostAddress)
 	if err != nil {
-		return "", fmt.Errorf("ssh: failed to dial IP %s, error: %s", hostAddress, err.Error())
+		return nil, fmt.Errorf("ssh: failed to dial IP %s, error: %s", hostAddress, err.Error())
 	}
 
-	session, err := connection.NewSession()
+	// close the connection when ctx is done during the handshake
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, hostAddress, sshConfig)
 	if err != nil {
-		return "", fmt.Errorf("ssh: failed to create session, error: %s", err.Error())
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ssh: failed to dial IP %s, error: %s", hostAddress, err.Error())
 	}
-	defer session.Close()
+	if ctx.Err() != nil {
+		c.Close()
+		return nil, ctx.Err()
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
 
-	var b bytes.Buffer
-	session.Stdout = &b
-	if err := session.Run(command); err != nil {
-		return "", fmt.Errorf("ssh: failed to run command `%s`, error: %s", command, err.Error())
+// SshShell is an interactive login shell with a PTY, stderr is merged into
+// stdout by the PTY.
+type SshShell struct {
+	client  *ssh.Client
+	session *ssh.Session
+	Stdin   io.WriteCloser
+	Stdout  io.Reader
+}
+
+// OpenSshShell connects to a VM and starts a login shell with a PTY of the
+// given size.
+func OpenSshShell(ctx context.Context, ip string, port int, password string, term string, cols, rows int) (*SshShell, error) {
+	client, err := DialSsh(ctx, ip, port, password)
+	if err != nil {
+		return nil, err
+	}
+	shell := &SshShell{client: client}
+	if err := shell.start(term, cols, rows); err != nil {
+		shell.Close()
+		return nil, err
 	}
+	return shell, nil
+}
 
-	output := strings.Trim(b.String(), "\n")
+func (s *SshShell) start(term string, cols, rows int) error {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("ssh: failed to create session, error: %s", err.Error())
+	}
+	s.session = session
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty(term, rows, cols, modes); err != nil {
+		return fmt.Errorf("ssh: failed to request pty, error: %s", err.Error())
+	}
+	if s.Stdin, err = session.StdinPipe(); err != nil {
+		return fmt.Errorf("ssh: failed to open stdin, error: %s", err.Error())
+	}
+	if s.Stdout, err = session.StdoutPipe(); err != nil {
+		return fmt.Errorf("ssh: failed to open stdout, error: %s", err.Error())
+	}
+	if err := session.Shell(); err != nil {
+		return fmt.Errorf("ssh: failed to start shell, error: %s", err.Error())
+	}
+	return nil
+}
 
-	return output, nil
+// Resize changes the PTY size.
+func (s *SshShell) Resize(cols, rows int) error {
+	return s.session.WindowChange(rows, cols)
+}
+
+// Wait waits for the shell to exit.
+func (s *SshShell) Wait() error {
+	return s.session.Wait()
+}
+
+// Close closes the session and the connection, the shell is terminated.
+func (s *SshShell) Close() error {
+	if s.session != nil {
+		s.session.Close()
+	}
+	return s.client.Close()
 }
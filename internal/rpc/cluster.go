@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+)
+
+// clusterService 集群服务，与 REST 接口的集群控制器对应
+type clusterService struct {
+	pb.UnimplementedClusterServiceServer
+	gw *gateway
+}
+
+func (s *clusterService) CreateCluster(ctx context.Context, req *pb.CreateClusterRequest) (*pb.CreateClusterResp, error) {
+	if err := requireIDs("project_id", req.GetProjectId()); err != nil {
+		return nil, err
+	}
+	resp := &pb.CreateClusterResp{}
+	path := "/projects/" + url.PathEscape(req.GetProjectId()) + "/clusters"
+	body := req.GetCluster()
+	if body == nil {
+		body = &pb.CreateClusterReq{}
+	}
+	if err := s.gw.invoke(ctx, http.MethodPost, path, nil, body, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *clusterService) GetCluster(ctx context.Context, req *pb.GetClusterRequest) (*pb.GetClusterResp, error) {
+	if err := requireIDs("project_id", req.GetProjectId(), "cluster_id", req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	resp := &pb.GetClusterResp{}
+	if err := s.gw.invoke(ctx, http.MethodGet, clusterPath(req.GetProjectId(), req.GetClusterId()), nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *clusterService) ListCluster(ctx context.Context, req *pb.ListClusterRequest) (*pb.ListClusterResp, error) {
+	if err := requireIDs("project_id", req.GetProjectId()); err != nil {
+		return nil, err
+	}
+	query, err := queryValues(req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListClusterResp{}
+	path := "/projects/" + url.PathEscape(req.GetProjectId()) + "/clusters"
+	if err := s.gw.invoke(ctx, http.MethodGet, path, query, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *clusterService) DeleteCluster(ctx context.Context, req *pb.DeleteClusterRequest) (*pb.BaseResponse, error) {
+	if err := requireIDs("project_id", req.GetProjectId(), "cluster_id", req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	resp := &pb.BaseResponse{}
+	if err := s.gw.invoke(ctx, http.MethodDelete, clusterPath(req.GetProjectId(), req.GetClusterId()), nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *clusterService) UpgradeCluster(ctx context.Context, req *pb.UpgradeClusterRequest) (*pb.BaseResponse, error) {
+	if err := requireIDs("project_id", req.GetProjectId(), "cluster_id", req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	resp := &pb.BaseResponse{}
+	body := req.GetUpgrade()
+	if body == nil {
+		body = &pb.UpgradeClusterReq{}
+	}
+	path := clusterPath(req.GetProjectId(), req.GetClusterId()) + "/upgrade"
+	if err := s.gw.invoke(ctx, http.MethodPost, path, nil, body, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func clusterPath(projectID string, clusterID string) string {
+	return "/projects/" + url.PathEscape(projectID) + "/clusters/" + url.PathEscape(clusterID)
+}
@@ -0,0 +1,193 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gitbub.com/wbuntu/gin-template/internal/api/middleware"
+	"gitbub.com/wbuntu/gin-template/internal/model"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// REST 接口的路由前缀
+const apiPrefix = "/api/v1.0"
+
+// 错误详情中的域名，Reason 为 model.Code
+const errorDomain = "gin-template"
+
+// 转发为 HTTP 头部的元数据，与 REST 接口使用相同的令牌、幂等键与资源版本
+var forwardedMetadata = map[string]string{
+	"authorization":   "Authorization",
+	"idempotency-key": "Idempotency-Key",
+	"if-match":        "If-Match",
+}
+
+// responseMessage 与 REST 响应对应的 protobuf 消息，都包含响应码与响应消息
+type responseMessage interface {
+	proto.Message
+	GetCode() string
+	GetMessage() string
+}
+
+// gateway 将 gRPC 调用转换为 protobuf 编码的 HTTP 请求，在进程内交给 gin 处理
+// 与 REST 接口共用认证、权限、审计、幂等、参数校验与控制器逻辑
+type gateway struct {
+	handler http.Handler
+}
+
+// invoke 调用 REST 接口并将响应写入 resp，响应码不为 Success 时返回对应的 gRPC 错误
+func (gw *gateway) invoke(ctx context.Context, method string, path string, query url.Values, req proto.Message, resp responseMessage) error {
+	var body []byte
+	if req != nil {
+		data, err := proto.Marshal(req)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "marshal request: %s", err)
+		}
+		body = data
+	}
+	// path 中的参数已转义，使用 url.Parse 解析以免再次转义
+	u, err := url.Parse(apiPrefix + path)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "parse path: %s", err)
+	}
+	u.RawQuery = query.Encode()
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return status.Errorf(codes.Internal, "new request: %s", err)
+	}
+	r.Header.Set("Accept", binding.MIMEPROTOBUF)
+	if len(body) > 0 {
+		r.Header.Set("Content-Type", binding.MIMEPROTOBUF)
+	}
+	r.Header.Set(middleware.GinCtxRequestID, getRequestID(ctx))
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, header := range forwardedMetadata {
+			if values := md.Get(key); len(values) > 0 {
+				r.Header.Set(header, values[0])
+			}
+		}
+	}
+	// 审计事件与任务日志使用调用方地址
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	w := &responseWriter{header: http.Header{}, status: http.StatusOK}
+	gw.handler.ServeHTTP(w, r)
+	// 认证等中间件拒绝请求时返回 JSON
+	if strings.HasPrefix(w.header.Get("Content-Type"), binding.MIMEPROTOBUF) {
+		err = proto.Unmarshal(w.body.Bytes(), resp)
+	} else {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(w.body.Bytes(), resp)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "unmarshal response with status %d: %s", w.status, err)
+	}
+	if etag := w.header.Get("ETag"); len(etag) > 0 {
+		if err := grpc.SetHeader(ctx, metadata.Pairs("etag", etag)); err != nil {
+			return err
+		}
+	}
+	code := model.Code(resp.GetCode())
+	if code == model.CodeSuccess {
+		return nil
+	}
+	st, err := status.New(grpcCode(code), resp.GetMessage()).WithDetails(&errdetails.ErrorInfo{
+		Reason: string(code),
+		Domain: errorDomain,
+	})
+	if err != nil {
+		return status.Errorf(grpcCode(code), "%s: %s", code, resp.GetMessage())
+	}
+	return st.Err()
+}
+
+// grpcCode 响应码对应的 gRPC 状态码
+func grpcCode(code model.Code) codes.Code {
+	switch code {
+	case model.CodeParamError:
+		return codes.InvalidArgument
+	case model.CodeNotExists:
+		return codes.NotFound
+	case model.CodeAlreadyExists:
+		return codes.AlreadyExists
+	case model.CodeForbidOperate, model.CodeIdempotencyMismatch:
+		return codes.FailedPrecondition
+	case model.CodeUnauthorized:
+		return codes.Unauthenticated
+	case model.CodeForbidden:
+		return codes.PermissionDenied
+	case model.CodeQuotaExceeded:
+		return codes.ResourceExhausted
+	case model.CodeConflict, model.CodeIdempotencyInProgress:
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
+
+// queryValues 将 protobuf 消息转换为查询参数，字段名与 REST 接口的查询参数相同
+func queryValues(msg proto.Message) (url.Values, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "marshal query: %s", err)
+	}
+	fields := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decode query: %s", err)
+	}
+	values := url.Values{}
+	for key, value := range fields {
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				values.Add(key, fmt.Sprint(item))
+			}
+			continue
+		}
+		values.Set(key, fmt.Sprint(value))
+	}
+	return values, nil
+}
+
+// requireIDs 检查路径中的资源ID，参数为字段名与值
+func requireIDs(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if len(pairs[i+1]) == 0 {
+			return status.Errorf(codes.InvalidArgument, "%s is required", pairs[i])
+		}
+	}
+	return nil
+}
+
+// responseWriter 在内存中记录 gin 写入的响应
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+)
+
+func TestGatewayPathEscape(t *testing.T) {
+	var path, escapedPath string
+	gw := &gateway{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		escapedPath = r.URL.EscapedPath()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"Success"}`))
+	})}
+	s := &clusterService{gw: gw}
+	if _, err := s.GetCluster(context.Background(), &pb.GetClusterRequest{ProjectId: "a/b", ClusterId: "c d"}); err != nil {
+		t.Fatalf("GetCluster: %s", err)
+	}
+	if path != "/api/v1.0/projects/a/b/clusters/c d" {
+		t.Fatalf("path: %s", path)
+	}
+	// 路径参数只转义一次
+	if escapedPath != "/api/v1.0/projects/a%2Fb/clusters/c%20d" {
+		t.Fatalf("escaped path: %s", escapedPath)
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// 请求ID的元数据，与 HTTP 头部 X-Request-Id 对应
+const metadataRequestID = "x-request-id"
+
+type requestIDKey struct{}
+
+// getRequestID 获取拦截器设置的请求ID
+func getRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// requestIDInterceptor 使用元数据中的请求ID，未携带时生成，通过响应头部元数据返回，并初始化带请求ID的 logger
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataRequestID); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if len(requestID) == 0 {
+		requestID = utils.UUID()
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, requestID)); err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	ctx = log.S(ctx, log.WithFields(log.Fields{
+		"module":    "grpc",
+		"requestID": requestID,
+	}))
+	return handler(ctx, req)
+}
+
+// loggingInterceptor 记录调用的方法、耗时与状态码，健康检查不记录
+func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+		return handler(ctx, req)
+	}
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	code := status.Code(err)
+	logger := log.G(ctx).WithFields(log.Fields{
+		"latency": time.Since(start),
+		"code":    code.String(),
+	})
+	switch code {
+	case codes.OK:
+		logger.Info(info.FullMethod)
+	case codes.Internal, codes.Unknown:
+		logger.Errorf("%s: %s", info.FullMethod, err)
+	default:
+		logger.Warnf("%s: %s", info.FullMethod, err)
+	}
+	return resp, err
+}
+
+// recoveryInterceptor 捕获 panic 并返回 Internal
+func recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.G(ctx).Errorf("panic: %v\n%s", r, debug.Stack())
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
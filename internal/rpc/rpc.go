@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server gRPC 服务，调用通过 gateway 交给 REST 接口的 handler 处理，健康检查与反射服务不需要认证
+type Server struct {
+	srv    *grpc.Server
+	health *health.Server
+	lis    net.Listener
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger log.Logger
+}
+
+func (s *Server) Setup(ctx context.Context, cfg *config.Config, handler http.Handler) error {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.logger = log.WithField("module", "grpc")
+	lis, err := net.Listen("tcp", cfg.API.GRPCAddr)
+	if err != nil {
+		return errors.Wrap(err, "listen")
+	}
+	s.lis = lis
+	s.srv = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestIDInterceptor,
+		loggingInterceptor,
+		recoveryInterceptor,
+	))
+	gw := &gateway{handler: handler}
+	pb.RegisterClusterServiceServer(s.srv, &clusterService{gw: gw})
+	pb.RegisterTaskServiceServer(s.srv, &taskService{gw: gw})
+	s.health = health.NewServer()
+	healthpb.RegisterHealthServer(s.srv, s.health)
+	reflection.Register(s.srv)
+	return nil
+}
+
+func (s *Server) Serve() error {
+	s.logger.Infof("start listening: %s", s.lis.Addr())
+	if err := s.srv.Serve(s.lis); err != nil {
+		log.Fatalf("srv.Serve: %s", err)
+	}
+	return nil
+}
+
+// Shutdown 先将健康状态设置为 NOT_SERVING，等待处理中的调用结束，超时后强制关闭
+func (s *Server) Shutdown() error {
+	s.health.Shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		s.srv.Stop()
+	}
+	s.cancel()
+	return nil
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"gitbub.com/wbuntu/gin-template/internal/model/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// taskService 任务服务，与 REST 接口的任务控制器对应
+type taskService struct {
+	pb.UnimplementedTaskServiceServer
+	gw *gateway
+}
+
+func (s *taskService) ListTask(ctx context.Context, req *pb.ListTaskRequest) (*pb.ListTaskResp, error) {
+	if err := requireIDs("project_id", req.GetProjectId(), "cluster_id", req.GetClusterId()); err != nil {
+		return nil, err
+	}
+	query, err := queryValues(req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListTaskResp{}
+	path := clusterPath(req.GetProjectId(), req.GetClusterId()) + "/tasks"
+	if err := s.gw.invoke(ctx, http.MethodGet, path, query, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *taskService) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResp, error) {
+	path, err := taskPath(req.GetProjectId(), req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.GetTaskResp{}
+	if err := s.gw.invoke(ctx, http.MethodGet, path, nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *taskService) ListTaskLog(ctx context.Context, req *pb.ListTaskLogRequest) (*pb.ListTaskLogResp, error) {
+	path, err := taskPath(req.GetProjectId(), req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+	query, err := queryValues(req.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.ListTaskLogResp{}
+	if err := s.gw.invoke(ctx, http.MethodGet, path+"/logs", query, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *taskService) CancelTask(ctx context.Context, req *pb.CancelTaskRequest) (*pb.BaseResponse, error) {
+	path, err := taskPath(req.GetProjectId(), req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.BaseResponse{}
+	if err := s.gw.invoke(ctx, http.MethodPost, path+"/cancel", nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *taskService) RetryTask(ctx context.Context, req *pb.RetryTaskRequest) (*pb.BaseResponse, error) {
+	path, err := taskPath(req.GetProjectId(), req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.BaseResponse{}
+	if err := s.gw.invoke(ctx, http.MethodPost, path+"/retry", nil, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func taskPath(projectID string, taskID uint64) (string, error) {
+	if err := requireIDs("project_id", projectID); err != nil {
+		return "", err
+	}
+	if taskID == 0 {
+		return "", status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	return "/projects/" + url.PathEscape(projectID) + "/tasks/" + strconv.FormatUint(taskID, 10), nil
+}
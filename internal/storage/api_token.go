@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// APITokenPrefix API令牌前缀，用于区分 API 令牌与 JWT
+const APITokenPrefix = "gt_"
+
+// APIToken 静态 API 令牌，数据库中只保存令牌的 sha256 摘要
+type APIToken struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID string       `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	Name       string       `gorm:"not null;comment:'名称'"`
+	Subject    string       `gorm:"not null;index;comment:'调用者身份'"`
+	TokenHash  string       `gorm:"not null;uniqueIndex;size:64;comment:'令牌摘要'"`
+	ExpiresAt  sql.NullTime `gorm:"comment:'过期时间'"`
+	CreatedAt  time.Time    `gorm:"comment:'创建时间'"`
+}
+
+// Expired 令牌是否已过期，未设置过期时间的令牌永不过期
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt.Valid && time.Now().After(t.ExpiresAt.Time)
+}
+
+// HashAPIToken 计算 API 令牌的摘要
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetAPITokenByToken 根据令牌明文获取 API 令牌
+func GetAPITokenByToken(token string) (*APIToken, error) {
+	item := &APIToken{}
+	if err := DB().
+		Where("token_hash = ?", HashAPIToken(token)).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// CreateAPIToken 生成并保存 API 令牌，返回只展示一次的令牌明文，ttl 为 0 时永不过期
+func CreateAPIToken(name string, subject string, ttl time.Duration) (*APIToken, string, error) {
+	resourceID, err := GenerateAPITokenResourceID()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "generate resourceID")
+	}
+	// 令牌使用密码学安全的随机数生成
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", errors.Wrap(err, "generate token")
+	}
+	token := APITokenPrefix + hex.EncodeToString(buf)
+	item := &APIToken{
+		ResourceID: resourceID.ResourceID,
+		Name:       name,
+		Subject:    subject,
+		TokenHash:  HashAPIToken(token),
+	}
+	if ttl > 0 {
+		item.ExpiresAt = sql.NullTime{Time: time.Now().Add(ttl), Valid: true}
+	}
+	if err := DB().Create(item).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return item, token, nil
+}
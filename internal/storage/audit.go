@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// AuditEvent 修改类接口的审计事件，记录调用者、请求与处理结果
+type AuditEvent struct {
+	ID           uint64         `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	RequestID    string         `gorm:"not null;index;size:64;comment:'请求ID'"`
+	Actor        string         `gorm:"not null;index;size:255;comment:'调用者身份'"`
+	Method       string         `gorm:"not null;size:16;comment:'请求方法'"`
+	Route        string         `gorm:"not null;size:255;comment:'路由'"`
+	Path         string         `gorm:"not null;size:1024;comment:'请求路径'"`
+	ProjectID    string         `gorm:"not null;default:'';index;size:20;comment:'项目资源ID'"`
+	ResourceID   string         `gorm:"not null;default:'';index;size:255;comment:'操作的资源ID'"`
+	RequestBody  datatypes.JSON `gorm:"comment:'脱敏后的请求体'"`
+	StatusCode   int            `gorm:"not null;comment:'HTTP状态码'"`
+	ResponseCode string         `gorm:"not null;size:64;comment:'响应码'"`
+	Latency      int64          `gorm:"not null;comment:'耗时（毫秒）'"`
+	ClientIP     string         `gorm:"not null;size:64;comment:'客户端IP'"`
+	CreatedAt    time.Time      `gorm:"index;comment:'创建时间'"`
+}
+
+// CreateAuditEvent 保存审计事件
+func CreateAuditEvent(item *AuditEvent) error {
+	if err := DB().Create(item).Error; err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// AuditEventFilters 根据调用者与时间范围生成审计事件的过滤条件，参数为空或零值时不过滤
+func AuditEventFilters(actor string, start time.Time, end time.Time) []*Filter {
+	filters := []*Filter{}
+	if len(actor) > 0 {
+		filters = append(filters, &Filter{Query: "actor = ?", Args: []interface{}{actor}})
+	}
+	if !start.IsZero() {
+		filters = append(filters, &Filter{Query: "created_at >= ?", Args: []interface{}{start}})
+	}
+	if !end.IsZero() {
+		filters = append(filters, &Filter{Query: "created_at <= ?", Args: []interface{}{end}})
+	}
+	return filters
+}
+
+// ListAuditEvent 根据查询条件分页列出审计事件，最新的事件在前，还有下一页时返回下一页的分页令牌
+func ListAuditEvent(query *ListQuery) ([]AuditEvent, string, error) {
+	items := []AuditEvent{}
+	db, err := query.applyPage(DB())
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return paginate(query, items)
+}
+
+// CountAuditEvent 计算满足查询条件的审计事件总数
+func CountAuditEvent(query *ListQuery) (int, error) {
+	var count int64
+	if err := applyFilters(DB().Model(&AuditEvent{}), query.Filters).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
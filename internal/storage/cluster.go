@@ -2,26 +2,54 @@ package storage
 
 import (
 	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Cluster struct {
-	ID          uint64        `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
-	Name        string        `gorm:"not null;comment:'名称'"`
-	Description string        `gorm:"comment:'集群描述信息'"`
-	ResourceID  string        `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
-	Type        K8sType       `gorm:"not null;comment:'k8s类型 k8s k3s'"`
-	Version     string        `gorm:"not null;comment:'版本'"`
-	Runtime     string        `gorm:"not null;comment:'运行时'"`
-	Status      ClusterStatus `gorm:"not null;index;comment:'状态'"`
-	CreatedAt   time.Time     `gorm:"comment:'创建时间'"`
-	UpdatedAt   time.Time     `gorm:"comment:'更新时间'"`
-}
-
-// GetClusterByResourceID 根据资源ID获取集群
+	ID              uint64                                `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	Name            string                                `gorm:"not null;comment:'名称'"`
+	Description     string                                `gorm:"comment:'集群描述信息'"`
+	ResourceID      string                                `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	ProjectID       string                                `gorm:"not null;default:'';index;size:20;comment:'项目资源ID'"`
+	Type            K8sType                               `gorm:"not null;comment:'k8s类型 k8s k3s'"`
+	Version         string                                `gorm:"not null;comment:'版本'"`
+	Runtime         string                                `gorm:"not null;comment:'运行时'"`
+	Status          ClusterStatus                         `gorm:"not null;index;comment:'状态'"`
+	ResourceVersion uint64                                `gorm:"not null;default:1;comment:'资源版本，每次更新加一'"`
+	Annotations     datatypes.JSONType[map[string]string] `gorm:"comment:'集群注解'"`
+	CreatedAt       time.Time                             `gorm:"comment:'创建时间'"`
+	UpdatedAt       time.Time                             `gorm:"comment:'更新时间'"`
+	DeletedAt       gorm.DeletedAt                        `gorm:"index;comment:'删除时间'"`
+}
+
+// ClusterLabel 集群标签，每个标签保存为一行，便于根据标签查询集群
+type ClusterLabel struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ClusterID string `gorm:"not null;uniqueIndex:idx_cluster_label;size:20;comment:'集群资源ID'"`
+	Key       string `gorm:"not null;uniqueIndex:idx_cluster_label;index:idx_cluster_label_key_value;size:317;comment:'标签键'"`
+	Value     string `gorm:"not null;index:idx_cluster_label_key_value;size:63;comment:'标签值'"`
+}
+
+// GetProjectClusterByResourceID 根据项目与资源ID获取集群，集群不属于该项目时返回 ErrDoesNotExist
+func GetProjectClusterByResourceID(projectID string, resourceID string) (*Cluster, error) {
+	item := &Cluster{}
+	if err := DB().
+		Where("project_id = ? and resource_id = ?", projectID, resourceID).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetClusterByResourceID 根据资源ID获取集群，不限定项目，只用于 daemon 执行任务
 func GetClusterByResourceID(resourceID string) (*Cluster, error) {
 	item := &Cluster{}
 	if err := DB().
-		Where("resource_id = ? and status != ?", resourceID, ClusterStatusDeleted).
+		Where("resource_id = ?", resourceID).
 		Take(item).
 		Error; err != nil {
 		return nil, handleStorageError(err)
@@ -29,62 +57,343 @@ func GetClusterByResourceID(resourceID string) (*Cluster, error) {
 	return item, nil
 }
 
+// UpdateClusterAndTaskStatus 在事务中更新集群、任务与受影响节点的状态并写入状态变化的事件，集群已删除时软删除集群与集群的任务，任务已不在等待执行时返回 ErrTaskNotPending
+func UpdateClusterAndTaskStatus(cluster *Cluster, task *Task, nodes ...*Node) error {
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		// 任务可能在执行期间被取消，只更新仍在等待执行的任务
+		result := tx.Model(task).
+			Where("status < ?", TaskStatusSuccess).
+			Updates(map[string]interface{}{
+				"retry_count": task.RetryCount,
+				"retry_at":    task.RetryAt,
+				"status":      task.Status,
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update task")
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskNotPending
+		}
+		if err := CreateStatusEvents(tx, cluster, task); err != nil {
+			return err
+		}
+		fields := map[string]interface{}{
+			"status":  cluster.Status,
+			"version": cluster.Version,
+		}
+		if cluster.Status == ClusterStatusDeleted {
+			cluster.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			fields["deleted_at"] = cluster.DeletedAt
+		}
+		if err := UpdateCluster(tx, cluster, fields); err != nil {
+			return errors.Wrap(err, "update cluster")
+		}
+		if cluster.DeletedAt.Valid {
+			if err := tx.Model(&Task{}).
+				Where("resource_id = ?", cluster.ResourceID).
+				UpdateColumn("deleted_at", cluster.DeletedAt).Error; err != nil {
+				return errors.Wrap(err, "delete tasks")
+			}
+		}
+		for _, node := range nodes {
+			if err := UpdateNode(tx, node, map[string]interface{}{"status": node.Status}); err != nil {
+				return errors.Wrap(err, "update node")
+			}
+		}
+		return nil
+	}); err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// UpdateClusterStatus 在事务中写入状态事件并更新集群状态，集群已被其他请求修改时返回 ErrConflict
+func UpdateClusterStatus(cluster *Cluster) error {
+	version := cluster.ResourceVersion
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		if err := CreateStatusEvents(tx, cluster, nil); err != nil {
+			return err
+		}
+		return UpdateCluster(tx, cluster, map[string]interface{}{"status": cluster.Status})
+	}); err != nil {
+		// 事务回滚时恢复资源版本
+		cluster.ResourceVersion = version
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// UpdateCluster 使用指定的数据库连接更新集群字段，只在资源版本未变化时更新并将版本加一，集群已被其他请求修改时返回 ErrConflict
+func UpdateCluster(db *gorm.DB, cluster *Cluster, fields map[string]interface{}) error {
+	version, err := updateWithResourceVersion(db, &Cluster{}, cluster.ID, cluster.ResourceVersion, fields)
+	if err != nil {
+		return err
+	}
+	cluster.ResourceVersion = version
+	return nil
+}
+
 // ListRunnableClusterResoureID 列出未删除集群ID
 func ListRunnableClusterResoureID() ([]string, error) {
 	var resourceIDs []string
 	if err := DB().
-		Raw("SELECT resource_id FROM clusters WHERE status != ? order by id desc", ClusterStatusDeleted).
-		Scan(&resourceIDs).
+		Model(&Cluster{}).
+		Order("id desc").
+		Pluck("resource_id", &resourceIDs).
 		Error; err != nil {
 		return nil, handleStorageError(err)
 	}
 	return resourceIDs, nil
 }
 
-// ListCluster 分页列出集群
-func ListCluster(offset int, limit int, filter *Filter) ([]Cluster, error) {
+// ListCluster 根据查询条件分页列出项目中的集群，还有下一页时返回下一页的分页令牌
+func ListCluster(projectID string, query *ListQuery) ([]Cluster, string, error) {
 	items := []Cluster{}
-	var err error
-	if filter != nil {
-		err = DB().
-			Limit(limit).
-			Offset(offset).
-			Where("status != ?", ClusterStatusDeleted).
-			Where(filter.Query, filter.Args...).
-			Order("id desc").
-			Find(&items).Error
-	} else {
-		err = DB().
-			Limit(limit).
-			Offset(offset).
-			Where("status != ?", ClusterStatusDeleted).
-			Order("id desc").
-			Find(&items).Error
-	}
+	db, err := query.applyPage(DB().Where("project_id = ?", projectID))
 	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return paginate(query, items)
+}
+
+// CountCluster 计算项目中满足查询条件的集群总数
+func CountCluster(projectID string, query *ListQuery) (int, error) {
+	var count int64
+	if err := applyFilters(DB().Model(&Cluster{}).Where("project_id = ?", projectID), query.Filters).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+// ListDeletedCluster 分页列出已删除的集群，最近删除的集群在前，projectID 为空时列出全部项目的集群
+func ListDeletedCluster(projectID string, offset int, limit int) ([]Cluster, error) {
+	items := []Cluster{}
+	if err := deletedClusterQuery(projectID).
+		Order("deleted_at desc").
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&items).Error; err != nil {
 		return nil, handleStorageError(err)
 	}
 	return items, nil
 }
 
-// CountCluster 计算集群总数
-func CountCluster(filter *Filter) (int, error) {
+// CountDeletedCluster 计算已删除的集群总数
+func CountDeletedCluster(projectID string) (int, error) {
 	var count int64
-	var err error
-	if filter != nil {
-		err = DB().
-			Model(&Cluster{}).
-			Where("status != ?", ClusterStatusDeleted).
-			Where(filter.Query, filter.Args...).
-			Count(&count).Error
-	} else {
-		err = DB().
+	if err := deletedClusterQuery(projectID).
+		Model(&Cluster{}).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+func deletedClusterQuery(projectID string) *gorm.DB {
+	db := DB().Unscoped().Where("deleted_at IS NOT NULL")
+	if len(projectID) > 0 {
+		db = db.Where("project_id = ?", projectID)
+	}
+	return db
+}
+
+// RestoreCluster 在事务中恢复已删除的集群与集群的任务，集群的主机已重置，恢复后进入异常状态，
+// 集群不存在或未删除时返回 ErrDoesNotExist，超出项目配额时返回 ErrQuotaExceeded
+func RestoreCluster(resourceID string) (*Cluster, error) {
+	item := &Cluster{}
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("resource_id = ? and deleted_at IS NOT NULL", resourceID).
+			Take(item).Error; err != nil {
+			return err
+		}
+		if err := CheckClusterQuota(tx, item.ProjectID); err != nil {
+			return err
+		}
+		// 恢复后的集群为异常状态，写入状态事件
+		restored := *item
+		restored.Status = ClusterStatusError
+		if err := CreateStatusEvents(tx, &restored, nil); err != nil {
+			return err
+		}
+		result := tx.Unscoped().
 			Model(&Cluster{}).
-			Where("status != ?", ClusterStatusDeleted).
-			Count(&count).Error
+			Where("id = ? and resource_version = ?", item.ID, item.ResourceVersion).
+			Updates(map[string]interface{}{
+				"status":           ClusterStatusError,
+				"deleted_at":       nil,
+				"resource_version": item.ResourceVersion + 1,
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "restore cluster")
+		}
+		if result.RowsAffected == 0 {
+			return ErrConflict
+		}
+		if err := tx.Unscoped().
+			Model(&Task{}).
+			Where("resource_id = ? and deleted_at IS NOT NULL", resourceID).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return errors.Wrap(err, "restore tasks")
+		}
+		return nil
+	}); err != nil {
+		return nil, handleStorageError(err)
 	}
-	if err != nil {
+	item.Status = ClusterStatusError
+	item.DeletedAt = gorm.DeletedAt{}
+	item.ResourceVersion++
+	return item, nil
+}
+
+// clusterPurgeBatchSize 每次清理的集群数量上限
+const clusterPurgeBatchSize = 100
+
+// PurgeDeletedClusters 彻底删除删除时间早于 before 的集群，以及集群的任务、任务日志、标签、节点、节点池与凭据，返回清理的集群数量
+func PurgeDeletedClusters(before time.Time) (int, error) {
+	items := []Cluster{}
+	if err := DB().Unscoped().
+		Where("deleted_at < ?", before).
+		Order("id asc").
+		Limit(clusterPurgeBatchSize).
+		Find(&items).Error; err != nil {
 		return 0, handleStorageError(err)
 	}
-	return int(count), nil
+	for i := range items {
+		if err := purgeCluster(&items[i]); err != nil {
+			return i, handleStorageError(err)
+		}
+	}
+	return len(items), nil
+}
+
+// purgeCluster 在事务中彻底删除集群与集群的关联数据，集群与任务需要使用 Unscoped 跳过软删除
+func purgeCluster(cluster *Cluster) error {
+	return DB().Transaction(func(tx *gorm.DB) error {
+		var taskIDs []uint64
+		if err := tx.Unscoped().Model(&Task{}).Where("resource_id = ?", cluster.ResourceID).Pluck("id", &taskIDs).Error; err != nil {
+			return errors.Wrap(err, "list tasks")
+		}
+		if len(taskIDs) > 0 {
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&TaskLog{}).Error; err != nil {
+				return errors.Wrap(err, "delete task logs")
+			}
+		}
+		if err := tx.Unscoped().Where("resource_id = ?", cluster.ResourceID).Delete(&Task{}).Error; err != nil {
+			return errors.Wrap(err, "delete tasks")
+		}
+		var credentialIDs []string
+		if err := tx.Model(&Node{}).Where("cluster_id = ?", cluster.ResourceID).Pluck("credential_id", &credentialIDs).Error; err != nil {
+			return errors.Wrap(err, "list node credentials")
+		}
+		if len(credentialIDs) > 0 {
+			if err := tx.Where("resource_id IN ?", credentialIDs).Delete(&Credential{}).Error; err != nil {
+				return errors.Wrap(err, "delete credentials")
+			}
+		}
+		if err := tx.Where("cluster_id = ?", cluster.ResourceID).Delete(&Node{}).Error; err != nil {
+			return errors.Wrap(err, "delete nodes")
+		}
+		if err := tx.Where("cluster_id = ?", cluster.ResourceID).Delete(&NodePool{}).Error; err != nil {
+			return errors.Wrap(err, "delete node pools")
+		}
+		if err := tx.Where("cluster_id = ?", cluster.ResourceID).Delete(&ClusterLabel{}).Error; err != nil {
+			return errors.Wrap(err, "delete cluster labels")
+		}
+		if err := tx.Unscoped().Delete(cluster).Error; err != nil {
+			return errors.Wrap(err, "delete cluster")
+		}
+		return nil
+	})
+}
+
+// ClusterLabelFilter 根据标签过滤集群，values 为空时只匹配标签键，exclude 为 true 时选择不匹配的集群，包括没有该标签的集群
+func ClusterLabelFilter(key string, values []string, exclude bool) *Filter {
+	// 使用 map 作为条件，由 gorm 处理 key、value 等关键字的转义
+	conds := map[string]interface{}{"key": key}
+	if len(values) > 0 {
+		conds["value"] = values
+	}
+	subQuery := DB().Model(&ClusterLabel{}).Select("cluster_id").Where(conds)
+	if exclude {
+		return &Filter{Query: "resource_id NOT IN (?)", Args: []interface{}{subQuery}}
+	}
+	return &Filter{Query: "resource_id IN (?)", Args: []interface{}{subQuery}}
+}
+
+// ClusterStatusCount 按状态分组的集群数量
+type ClusterStatusCount struct {
+	Status ClusterStatus
+	Count  int64
+}
+
+// CountClusterGroupByStatus 按状态分组计算集群数量
+func CountClusterGroupByStatus() ([]ClusterStatusCount, error) {
+	items := []ClusterStatusCount{}
+	if err := DB().
+		Model(&Cluster{}).
+		Select("status, count(*) as count").
+		Group("status").
+		Scan(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// GetClusterLabels 获取集群的标签
+func GetClusterLabels(clusterID string) (map[string]string, error) {
+	items := []ClusterLabel{}
+	if err := DB().
+		Where("cluster_id = ?", clusterID).
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	labels := make(map[string]string, len(items))
+	for _, item := range items {
+		labels[item.Key] = item.Value
+	}
+	return labels, nil
+}
+
+// ListClusterLabels 批量获取集群的标签，返回集群资源ID到标签的映射
+func ListClusterLabels(clusterIDs []string) (map[string]map[string]string, error) {
+	items := []ClusterLabel{}
+	if len(clusterIDs) > 0 {
+		if err := DB().
+			Where("cluster_id IN ?", clusterIDs).
+			Find(&items).Error; err != nil {
+			return nil, handleStorageError(err)
+		}
+	}
+	result := make(map[string]map[string]string, len(clusterIDs))
+	for _, id := range clusterIDs {
+		result[id] = map[string]string{}
+	}
+	for _, item := range items {
+		result[item.ClusterID][item.Key] = item.Value
+	}
+	return result, nil
+}
+
+// ReplaceClusterLabels 使用指定的数据库连接替换集群的全部标签
+func ReplaceClusterLabels(db *gorm.DB, clusterID string, labels map[string]string) error {
+	if err := db.Where("cluster_id = ?", clusterID).Delete(&ClusterLabel{}).Error; err != nil {
+		return errors.Wrap(err, "delete cluster labels")
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	items := make([]ClusterLabel, 0, len(labels))
+	for k, v := range labels {
+		items = append(items, ClusterLabel{ClusterID: clusterID, Key: k, Value: v})
+	}
+	if err := db.Create(&items).Error; err != nil {
+		return errors.Wrap(err, "create cluster labels")
+	}
+	return nil
 }
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gorm.io/gorm"
+)
+
+// webhookEventTypes 按写入顺序列出事件类型
+func webhookEventTypes(t *testing.T) []WebhookEventType {
+	t.Helper()
+	items := []WebhookEventType{}
+	if err := DB().Model(&WebhookEvent{}).Order("id asc").Pluck("type", &items).Error; err != nil {
+		t.Fatalf("list webhook events: %s", err)
+	}
+	return items
+}
+
+func TestUpdateClusterStatusCreatesEvents(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	cluster := &Cluster{ResourceID: "cluster-a", Name: "a", ProjectID: DefaultProjectID, Status: ClusterStatusRunning, ResourceVersion: 1}
+	if err := DB().Create(cluster).Error; err != nil {
+		t.Fatalf("create cluster: %s", err)
+	}
+	// 健康检查失败与恢复
+	for _, status := range []ClusterStatus{ClusterStatusError, ClusterStatusRunning} {
+		cluster.Status = status
+		if err := UpdateClusterStatus(cluster); err != nil {
+			t.Fatalf("update cluster status: %s", err)
+		}
+	}
+	expected := []WebhookEventType{WebhookEventClusterError, WebhookEventClusterRunning}
+	if events := webhookEventTypes(t); !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	// 版本过期时不更新状态，也不写入事件
+	stale := *cluster
+	stale.ResourceVersion--
+	stale.Status = ClusterStatusError
+	if err := UpdateClusterStatus(&stale); err != ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if events := webhookEventTypes(t); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v after conflict, got %v", expected, events)
+	}
+}
+
+func TestRestoreClusterCreatesEvent(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	cluster := &Cluster{ResourceID: "cluster-a", Name: "a", ProjectID: DefaultProjectID, Status: ClusterStatusDeleted, ResourceVersion: 1}
+	if err := DB().Create(cluster).Error; err != nil {
+		t.Fatalf("create cluster: %s", err)
+	}
+	if err := DB().Delete(cluster).Error; err != nil {
+		t.Fatalf("delete cluster: %s", err)
+	}
+	restored, err := RestoreCluster(cluster.ResourceID)
+	if err != nil {
+		t.Fatalf("restore cluster: %s", err)
+	}
+	if restored.Status != ClusterStatusError || restored.DeletedAt != (gorm.DeletedAt{}) {
+		t.Errorf("unexpected restored cluster: %+v", restored)
+	}
+	expected := []WebhookEventType{WebhookEventClusterError}
+	if events := webhookEventTypes(t); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+}
+
+func TestUpdateClusterConflict(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	cluster := &Cluster{ResourceID: "cluster-a", Name: "a", ProjectID: DefaultProjectID, Status: ClusterStatusRunning, ResourceVersion: 1}
+	if err := DB().Create(cluster).Error; err != nil {
+		t.Fatalf("create cluster: %s", err)
+	}
+	stale := *cluster
+	if err := UpdateCluster(DB(), cluster, map[string]interface{}{"name": "b"}); err != nil {
+		t.Fatalf("update cluster: %s", err)
+	}
+	if cluster.ResourceVersion != 2 {
+		t.Errorf("expected resource version 2, got %d", cluster.ResourceVersion)
+	}
+	// 使用过期版本更新时返回冲突，不修改记录与版本
+	if err := UpdateCluster(DB(), &stale, map[string]interface{}{"name": "c"}); err != ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if stale.ResourceVersion != 1 {
+		t.Errorf("expected stale resource version 1, got %d", stale.ResourceVersion)
+	}
+	item := &Cluster{}
+	if err := DB().First(item, cluster.ID).Error; err != nil {
+		t.Fatalf("get cluster: %s", err)
+	}
+	if item.Name != "b" || item.ResourceVersion != 2 {
+		t.Errorf("unexpected cluster after conflict: name %s, resource version %d", item.Name, item.ResourceVersion)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// encryptedPrefix 加密后的凭据前缀，没有前缀的值为未加密的旧数据
+const encryptedPrefix = "enc:v1:"
+
+// credentialCipher 加密凭据密码的 AES-256-GCM，未配置密钥时为空，密码以明文保存
+var credentialCipher cipher.AEAD
+
+func init() {
+	schema.RegisterSerializer("credential", credentialSerializer{})
+}
+
+// setCredentialKey 设置凭据的加密密钥，密钥为 base64 编码的 32 字节，返回是否启用加密
+func setCredentialKey(key string) (bool, error) {
+	credentialCipher = nil
+	if len(key) == 0 {
+		return false, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return false, errors.Wrap(err, "decode credential key")
+	}
+	if len(data) != 32 {
+		return false, errors.Errorf("credential key must be 32 bytes, got %d", len(data))
+	}
+	block, err := aes.NewCipher(data)
+	if err != nil {
+		return false, errors.Wrap(err, "new cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return false, errors.Wrap(err, "new gcm")
+	}
+	credentialCipher = aead
+	return true, nil
+}
+
+// encryptCredential 加密凭据，未配置密钥时返回明文
+func encryptCredential(plaintext string) (string, error) {
+	if credentialCipher == nil {
+		return plaintext, nil
+	}
+	nonce := make([]byte, credentialCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "generate nonce")
+	}
+	sealed := credentialCipher.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptCredential 解密凭据，未加密的旧数据直接返回
+func decryptCredential(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	if credentialCipher == nil {
+		return "", errors.New("credential is encrypted but db.credential_key is empty")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.Wrap(err, "decode credential")
+	}
+	size := credentialCipher.NonceSize()
+	if len(data) < size {
+		return "", errors.New("credential too short")
+	}
+	plaintext, err := credentialCipher.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypt credential")
+	}
+	return string(plaintext), nil
+}
+
+// credentialSerializer 读写数据库时加密与解密字符串字段
+type credentialSerializer struct{}
+
+func (credentialSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return errors.Errorf("unsupported credential value: %T", dbValue)
+	}
+	plaintext, err := decryptCredential(value)
+	if err != nil {
+		return err
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (credentialSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, _ := fieldValue.(string)
+	return encryptCredential(plaintext)
+}
+
+// encryptPlaintextCredentials 启用加密后加密已保存的明文凭据，返回加密的数量
+func encryptPlaintextCredentials(db *gorm.DB) (int, error) {
+	if credentialCipher == nil {
+		return 0, nil
+	}
+	items := []Credential{}
+	if err := db.Where("password NOT LIKE ?", encryptedPrefix+"%").Find(&items).Error; err != nil {
+		return 0, errors.Wrap(err, "list plaintext credentials")
+	}
+	for i := range items {
+		if err := db.Model(&items[i]).Select("password").Updates(&Credential{Password: items[i].Password}).Error; err != nil {
+			return 0, errors.Wrapf(err, "encrypt credential %s", items[i].ResourceID)
+		}
+	}
+	return len(items), nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+)
+
+func TestSetCredentialKey(t *testing.T) {
+	defer setCredentialKey("")
+	tests := []struct {
+		name      string
+		key       string
+		encrypted bool
+		err       bool
+	}{
+		{name: "empty", key: ""},
+		{name: "valid", key: base64.StdEncoding.EncodeToString(make([]byte, 32)), encrypted: true},
+		{name: "invalid base64", key: "not base64!", err: true},
+		{name: "short key", key: base64.StdEncoding.EncodeToString(make([]byte, 16)), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := setCredentialKey(tt.key)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if encrypted != tt.encrypted {
+				t.Errorf("expected encrypted %v, got %v", tt.encrypted, encrypted)
+			}
+		})
+	}
+}
+
+func TestCredentialEncryption(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	defer setCredentialKey("")
+	rawPassword := func(resourceID string) string {
+		var password string
+		if err := DB().Raw("SELECT password FROM credentials WHERE resource_id = ?", resourceID).Scan(&password).Error; err != nil {
+			t.Fatalf("read raw password: %s", err)
+		}
+		return password
+	}
+	// 未配置密钥时明文保存
+	legacy := &Credential{ResourceID: "cred-legacy", Username: "root", Password: "legacy-password"}
+	if err := DB().Create(legacy).Error; err != nil {
+		t.Fatalf("create credential: %s", err)
+	}
+	if raw := rawPassword(legacy.ResourceID); raw != legacy.Password {
+		t.Fatalf("expected plaintext password, got %s", raw)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("generate key: %s", err)
+	}
+	if _, err := setCredentialKey(base64.StdEncoding.EncodeToString(key)); err != nil {
+		t.Fatalf("set credential key: %s", err)
+	}
+	// 配置密钥后加密已保存的明文凭据
+	count, err := encryptPlaintextCredentials(DB())
+	if err != nil {
+		t.Fatalf("encrypt credentials: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 encrypted credential, got %d", count)
+	}
+	if count, _ := encryptPlaintextCredentials(DB()); count != 0 {
+		t.Errorf("expected no plaintext credentials, got %d", count)
+	}
+	created := &Credential{ResourceID: "cred-new", Username: "root", Password: "new-password"}
+	if err := DB().Create(created).Error; err != nil {
+		t.Fatalf("create credential: %s", err)
+	}
+	for _, item := range []*Credential{legacy, created} {
+		raw := rawPassword(item.ResourceID)
+		if !strings.HasPrefix(raw, encryptedPrefix) || strings.Contains(raw, item.Password) {
+			t.Errorf("password of %s not encrypted: %s", item.ResourceID, raw)
+		}
+		got, err := GetCredentialByResourceID(item.ResourceID)
+		if err != nil {
+			t.Fatalf("get credential: %s", err)
+		}
+		if got.Password != item.Password {
+			t.Errorf("expected password %s, got %s", item.Password, got.Password)
+		}
+	}
+	items, err := ListCredentialByResourceIDs([]string{legacy.ResourceID, created.ResourceID})
+	if err != nil || len(items) != 2 {
+		t.Fatalf("list credentials: %d %v", len(items), err)
+	}
+	// 密钥错误或缺失时无法读取
+	if _, err := setCredentialKey(base64.StdEncoding.EncodeToString(make([]byte, 32))); err != nil {
+		t.Fatalf("set credential key: %s", err)
+	}
+	if _, err := GetCredentialByResourceID(created.ResourceID); err == nil {
+		t.Error("expected error with wrong key")
+	}
+	setCredentialKey("")
+	if _, err := GetCredentialByResourceID(created.ResourceID); err == nil {
+		t.Error("expected error without key")
+	}
+}
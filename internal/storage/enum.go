@@ -8,6 +8,14 @@ const (
 	K8sTypeK3s K8sType = "k3s"
 )
 
+// HostRole 主机角色
+type HostRole string
+
+const (
+	HostRoleMaster HostRole = "master" // 控制面节点
+	HostRoleWorker HostRole = "worker" // 工作节点
+)
+
 // ClusterStatus 集群状态
 type ClusterStatus uint8
 
@@ -57,6 +65,7 @@ const (
 	TaskStatusRetrying                   // 重试中
 	TaskStatusSuccess                    // 已成功
 	TaskStatusFail                       // 已失败
+	TaskStatusCanceled                   // 已取消
 )
 
 func (s TaskStatus) String() string {
@@ -69,6 +78,8 @@ func (s TaskStatus) String() string {
 		return "Success"
 	case TaskStatusFail:
 		return "Fail"
+	case TaskStatusCanceled:
+		return "Canceled"
 	default:
 		return "Unknown"
 	}
@@ -80,3 +91,153 @@ const (
 	TaskRetryTypeFixed TaskRetryType = iota // 固定间隔
 	TaskRetryTypePow                        // 指数递增
 )
+
+// NodeStatus 节点状态
+type NodeStatus uint8
+
+// 中间态 0~99
+const (
+	NodeStatusJoining  NodeStatus = iota // 加入中
+	NodeStatusDraining                   // 驱逐中
+	NodeStatusRemoving                   // 移除中
+)
+
+// 稳态 >= 100
+const (
+	NodeStatusRunning NodeStatus = 100 + iota // 运行中
+	NodeStatusDrained                         // 已驱逐
+	NodeStatusRemoved                         // 已移除
+	NodeStatusError                           // 异常
+)
+
+func (s NodeStatus) String() string {
+	switch s {
+	case NodeStatusJoining:
+		return "Joining"
+	case NodeStatusDraining:
+		return "Draining"
+	case NodeStatusRemoving:
+		return "Removing"
+	case NodeStatusRunning:
+		return "Running"
+	case NodeStatusDrained:
+		return "Drained"
+	case NodeStatusRemoved:
+		return "Removed"
+	case NodeStatusError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+const (
+	NodeActionAdd    = "AddNode"
+	NodeActionDrain  = "DrainNode"
+	NodeActionRemove = "RemoveNode"
+)
+
+// Permission 权限，路由声明访问需要的权限，角色包含一组权限
+type Permission string
+
+const (
+	PermissionAll           Permission = "*"              // 全部权限
+	PermissionClusterRead   Permission = "cluster:read"   // 查看集群与节点
+	PermissionClusterCreate Permission = "cluster:create" // 创建集群
+	PermissionClusterUpdate Permission = "cluster:update" // 升级集群、管理节点与节点池
+	PermissionClusterDelete Permission = "cluster:delete" // 删除集群
+	PermissionTaskRead      Permission = "task:read"      // 查看任务与任务日志
+	PermissionTaskUpdate    Permission = "task:update"    // 取消与重试任务
+	PermissionRoleRead      Permission = "role:read"      // 查看角色与角色绑定
+	PermissionRoleUpdate    Permission = "role:update"    // 管理角色与角色绑定
+	PermissionProjectRead   Permission = "project:read"   // 查看项目
+	PermissionProjectUpdate Permission = "project:update" // 创建项目、修改项目配额
+	PermissionAdminRead     Permission = "admin:read"     // 查看已删除的集群与任务
+	PermissionAdminUpdate   Permission = "admin:update"   // 恢复已删除的集群
+	PermissionAuditRead     Permission = "audit:read"     // 查看审计事件
+	PermissionWebhookRead   Permission = "webhook:read"   // 查看 webhook 与投递记录
+	PermissionWebhookUpdate Permission = "webhook:update" // 管理 webhook
+	PermissionNodeShell     Permission = "node:shell"     // 通过终端登录节点
+)
+
+// Global 是否为全局权限，全局权限只能通过不限定项目的角色绑定授予，避免项目成员修改自己的配额或授予自己角色
+func (p Permission) Global() bool {
+	switch p {
+	case PermissionRoleRead, PermissionRoleUpdate, PermissionProjectUpdate, PermissionAdminRead, PermissionAdminUpdate, PermissionAuditRead:
+		return true
+	default:
+		return false
+	}
+}
+
+// Permissions 全部可以分配给角色的权限
+var Permissions = []Permission{
+	PermissionAll,
+	PermissionClusterRead,
+	PermissionClusterCreate,
+	PermissionClusterUpdate,
+	PermissionClusterDelete,
+	PermissionTaskRead,
+	PermissionTaskUpdate,
+	PermissionRoleRead,
+	PermissionRoleUpdate,
+	PermissionProjectRead,
+	PermissionProjectUpdate,
+	PermissionAdminRead,
+	PermissionAdminUpdate,
+	PermissionAuditRead,
+	PermissionWebhookRead,
+	PermissionWebhookUpdate,
+	PermissionNodeShell,
+}
+
+// 内置角色
+const (
+	RoleViewer   = "viewer"   // 只读
+	RoleOperator = "operator" // 可以创建、升级集群与管理节点、任务，不能删除集群
+	RoleAdmin    = "admin"    // 全部权限
+)
+
+// WebhookEventType webhook 事件类型
+type WebhookEventType string
+
+const (
+	WebhookEventClusterRunning WebhookEventType = "cluster.running" // 集群进入运行中
+	WebhookEventClusterError   WebhookEventType = "cluster.error"   // 集群进入异常
+	WebhookEventClusterDeleted WebhookEventType = "cluster.deleted" // 集群已删除
+	WebhookEventTaskSucceeded  WebhookEventType = "task.succeeded"  // 任务执行成功
+	WebhookEventTaskFailed     WebhookEventType = "task.failed"     // 任务执行失败
+	WebhookEventTaskCanceled   WebhookEventType = "task.canceled"   // 任务被取消
+)
+
+// WebhookEventTypes 全部可以订阅的事件类型
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventClusterRunning,
+	WebhookEventClusterError,
+	WebhookEventClusterDeleted,
+	WebhookEventTaskSucceeded,
+	WebhookEventTaskFailed,
+	WebhookEventTaskCanceled,
+}
+
+// WebhookDeliveryStatus webhook 投递状态
+type WebhookDeliveryStatus uint8
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = iota // 等待投递
+	WebhookDeliveryStatusSucceeded                              // 投递成功
+	WebhookDeliveryStatusFailed                                 // 超过重试次数，投递失败
+)
+
+func (s WebhookDeliveryStatus) String() string {
+	switch s {
+	case WebhookDeliveryStatusPending:
+		return "Pending"
+	case WebhookDeliveryStatusSucceeded:
+		return "Succeeded"
+	case WebhookDeliveryStatusFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
@@ -9,6 +9,12 @@ var (
 	ErrAlreadyExists = errors.New("already exists")
 	ErrDoesNotExist  = errors.New("does not exist")
 	ErrAbort         = errors.New("abort")
+	// ErrTaskNotPending 任务已结束或已被取消，不再更新任务状态
+	ErrTaskNotPending = errors.New("task not pending")
+	// ErrQuotaExceeded 项目的集群数量已达到配额
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrConflict 资源版本已变化，资源已被其他请求修改
+	ErrConflict = errors.New("conflict")
 )
 
 func handleStorageError(err error) error {
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"time"
+)
+
+// IdempotencyRecord 携带幂等键的请求与响应，有效期内相同调用者使用相同幂等键的请求直接返回保存的响应
+type IdempotencyRecord struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	Subject      string    `gorm:"not null;uniqueIndex:idx_idempotency;size:255;comment:'调用者身份'"`
+	Key          string    `gorm:"column:idempotency_key;not null;uniqueIndex:idx_idempotency;size:255;comment:'幂等键'"`
+	RequestHash  string    `gorm:"not null;size:64;comment:'请求方法、路径与请求体的哈希'"`
+	Completed    bool      `gorm:"not null;comment:'是否已保存响应'"`
+	StatusCode   int       `gorm:"not null;comment:'HTTP状态码'"`
+	ResponseCode string    `gorm:"not null;comment:'响应码'"`
+	ContentType  string    `gorm:"not null;comment:'响应类型'"`
+	Response     []byte    `gorm:"comment:'响应体'"`
+	ExpiresAt    time.Time `gorm:"not null;index;comment:'过期时间'"`
+	CreatedAt    time.Time `gorm:"comment:'创建时间'"`
+}
+
+// ReserveIdempotencyKey 占用调用者的幂等键，返回幂等键的记录与是否由本次请求占用，幂等键已被占用时返回已有的记录
+func ReserveIdempotencyKey(subject string, key string, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	existing, err := getIdempotencyRecord(subject, key)
+	if err != nil && err != ErrDoesNotExist {
+		return nil, false, err
+	}
+	if existing != nil {
+		if existing.ExpiresAt.After(time.Now()) {
+			return existing, false, nil
+		}
+		// 过期后可以重新使用幂等键
+		if err := DB().Delete(existing).Error; err != nil {
+			return nil, false, handleStorageError(err)
+		}
+	}
+	item := &IdempotencyRecord{
+		Subject:     subject,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	// 依赖唯一索引保证并发请求中只有一个可以占用幂等键
+	if err := DB().Create(item).Error; err != nil {
+		existing, takeErr := getIdempotencyRecord(subject, key)
+		if takeErr != nil {
+			return nil, false, handleStorageError(err)
+		}
+		return existing, false, nil
+	}
+	return item, true, nil
+}
+
+func getIdempotencyRecord(subject string, key string) (*IdempotencyRecord, error) {
+	item := &IdempotencyRecord{}
+	if err := DB().
+		Where("subject = ? and idempotency_key = ?", subject, key).
+		Take(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// CompleteIdempotencyKey 保存幂等键对应的响应
+func CompleteIdempotencyKey(item *IdempotencyRecord) error {
+	item.Completed = true
+	if err := DB().Model(item).Updates(map[string]interface{}{
+		"completed":     item.Completed,
+		"status_code":   item.StatusCode,
+		"response_code": item.ResponseCode,
+		"content_type":  item.ContentType,
+		"response":      item.Response,
+	}).Error; err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey 释放幂等键，请求执行出错时调用，允许客户端使用相同幂等键重试
+func ReleaseIdempotencyKey(item *IdempotencyRecord) error {
+	if err := DB().Delete(item).Error; err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords 删除过期的幂等键记录，返回删除的数量
+func DeleteExpiredIdempotencyRecords() (int64, error) {
+	result := DB().
+		Where("expires_at < ?", time.Now()).
+		Delete(&IdempotencyRecord{})
+	if result.Error != nil {
+		return 0, handleStorageError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
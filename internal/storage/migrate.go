@@ -4,8 +4,11 @@ import (
 	"context"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 func Migrate(ctx context.Context, cfg *config.Config) error {
@@ -17,23 +20,197 @@ func Migrate(ctx context.Context, cfg *config.Config) error {
 	if err := DB().AutoMigrate(
 		&ResourceID{},
 		&Cluster{},
+		&ClusterLabel{},
 		&Task{},
 		&TaskLog{},
+		&NodePool{},
+		&Node{},
+		&Credential{},
+		&APIToken{},
+		&Role{},
+		&RoleBinding{},
+		&Project{},
+		&IdempotencyRecord{},
+		&AuditEvent{},
+		&Webhook{},
+		&WebhookEvent{},
+		&WebhookDelivery{},
 	); err != nil {
 		return errors.Wrap(err, "migrate model")
 	}
 	// 使用 gormigrate 修改或更新数据
-	if len(migrations) == 0 {
-		return nil
+	if len(migrations) > 0 {
+		migrationsOptions := gormigrate.DefaultOptions
+		migrationsOptions.UseTransaction = true
+		m := gormigrate.New(DB(), migrationsOptions, migrations)
+		if err := m.Migrate(); err != nil {
+			return errors.Wrap(err, "migrate data")
+		}
+	}
+	// 配置加密密钥后加密已保存的明文凭据，每次启动时检查
+	count, err := encryptPlaintextCredentials(DB())
+	if err != nil {
+		return errors.Wrap(err, "encrypt credentials")
 	}
-	migrationsOptions := gormigrate.DefaultOptions
-	migrationsOptions.UseTransaction = true
-	m := gormigrate.New(DB(), migrationsOptions, migrations)
-	if err := m.Migrate(); err != nil {
-		return errors.Wrap(err, "migrate data")
+	if count > 0 {
+		log.G(ctx).Infof("encrypted %d plaintext credentials", count)
 	}
 	return nil
 }
 
 // migrations 用年月日时分秒作为ID，每个迁移动作都在事务中执行，执行成功一次后记录到数据库，不再执行
-var migrations = []*gormigrate.Migration{}
+var migrations = []*gormigrate.Migration{
+	{
+		ID:      "20261017000000",
+		Migrate: migrateClusterHostsToNodes,
+	},
+	{
+		ID:      "20261017020000",
+		Migrate: migrateBuiltinRoles,
+	},
+	{
+		ID:      "20261017030000",
+		Migrate: migrateDefaultProject,
+	},
+	{
+		ID:      "20261017040000",
+		Migrate: migrateDeletedClusters,
+	},
+}
+
+// migrateDeletedClusters 软删除已删除状态的集群与集群的任务，使用集群的更新时间作为删除时间
+func migrateDeletedClusters(tx *gorm.DB) error {
+	items := []Cluster{}
+	if err := tx.Where("status = ?", ClusterStatusDeleted).Find(&items).Error; err != nil {
+		return errors.Wrap(err, "list deleted clusters")
+	}
+	for _, item := range items {
+		if err := tx.Model(&Cluster{}).
+			Where("id = ?", item.ID).
+			UpdateColumn("deleted_at", item.UpdatedAt).Error; err != nil {
+			return errors.Wrapf(err, "delete cluster: %s", item.ResourceID)
+		}
+		if err := tx.Model(&Task{}).
+			Where("resource_id = ?", item.ResourceID).
+			UpdateColumn("deleted_at", item.UpdatedAt).Error; err != nil {
+			return errors.Wrapf(err, "delete tasks: %s", item.ResourceID)
+		}
+	}
+	return nil
+}
+
+// migrateDefaultProject 创建默认项目，将已有的集群与任务归属到默认项目，并重建包含项目的角色绑定唯一索引
+func migrateDefaultProject(tx *gorm.DB) error {
+	if err := tx.Create(&Project{
+		ResourceID:  DefaultProjectID,
+		Name:        "default",
+		Description: "default project",
+	}).Error; err != nil {
+		return errors.Wrap(err, "create default project")
+	}
+	if err := tx.Model(&Cluster{}).
+		Where("project_id = ?", "").
+		Update("project_id", DefaultProjectID).Error; err != nil {
+		return errors.Wrap(err, "update clusters")
+	}
+	if err := tx.Model(&Task{}).
+		Where("project_id = ?", "").
+		Update("project_id", DefaultProjectID).Error; err != nil {
+		return errors.Wrap(err, "update tasks")
+	}
+	// AutoMigrate 不会修改已存在的同名索引
+	if tx.Migrator().HasIndex(&RoleBinding{}, "idx_role_binding") {
+		if err := tx.Migrator().DropIndex(&RoleBinding{}, "idx_role_binding"); err != nil {
+			return errors.Wrap(err, "drop role binding index")
+		}
+	}
+	if err := tx.Migrator().CreateIndex(&RoleBinding{}, "idx_role_binding"); err != nil {
+		return errors.Wrap(err, "create role binding index")
+	}
+	return nil
+}
+
+// migrateBuiltinRoles 创建内置角色
+func migrateBuiltinRoles(tx *gorm.DB) error {
+	for i := range builtinRoles {
+		role := builtinRoles[i]
+		if err := tx.Create(&role).Error; err != nil {
+			return errors.Wrapf(err, "create role: %s", role.Name)
+		}
+	}
+	return nil
+}
+
+// migrateClusterHostsToNodes 将创建任务配置中的集群主机迁移到节点与凭据表，并清除任务配置中的主机密码
+func migrateClusterHostsToNodes(tx *gorm.DB) error {
+	type legacyHost struct {
+		IP       string   `json:"ip"`
+		Port     int      `json:"port"`
+		Password string   `json:"password"`
+		Role     HostRole `json:"role"`
+	}
+	type legacyConfig struct {
+		Hosts []legacyHost `json:"hosts"`
+	}
+	tasks := []Task{}
+	if err := tx.Where("action = ?", ClusterActionCreate).Find(&tasks).Error; err != nil {
+		return errors.Wrap(err, "list create tasks")
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		cfg := &legacyConfig{}
+		if err := task.GetConfig(cfg); err != nil {
+			return errors.Wrapf(err, "get task config: %d", task.ID)
+		}
+		if len(cfg.Hosts) == 0 {
+			continue
+		}
+		cluster := &Cluster{}
+		if err := tx.Where("resource_id = ?", task.ResourceID).Take(cluster).Error; err != nil {
+			return errors.Wrapf(err, "get cluster: %s", task.ResourceID)
+		}
+		// 节点状态跟随集群状态
+		status := NodeStatusRunning
+		switch cluster.Status {
+		case ClusterStatusCreating:
+			status = NodeStatusJoining
+		case ClusterStatusDeleted:
+			status = NodeStatusRemoved
+		case ClusterStatusError:
+			status = NodeStatusError
+		}
+		for _, h := range cfg.Hosts {
+			credentialID, err := createResourceIDWithDB(tx, "cred")
+			if err != nil {
+				return errors.Wrap(err, "generate credential resourceID")
+			}
+			nodeID, err := createResourceIDWithDB(tx, "node")
+			if err != nil {
+				return errors.Wrap(err, "generate node resourceID")
+			}
+			if err := tx.Create(&Credential{
+				ResourceID: credentialID.ResourceID,
+				Username:   "root",
+				Password:   h.Password,
+			}).Error; err != nil {
+				return errors.Wrap(err, "create credential")
+			}
+			if err := tx.Create(&Node{
+				ResourceID:      nodeID.ResourceID,
+				ClusterID:       cluster.ResourceID,
+				Role:            h.Role,
+				IP:              h.IP,
+				SSHPort:         h.Port,
+				CredentialID:    credentialID.ResourceID,
+				Status:          status,
+				ResourceVersion: 1,
+			}).Error; err != nil {
+				return errors.Wrap(err, "create node")
+			}
+		}
+		if err := tx.Model(task).Update("config", datatypes.JSON("{}")).Error; err != nil {
+			return errors.Wrapf(err, "clear task config: %d", task.ID)
+		}
+	}
+	return nil
+}
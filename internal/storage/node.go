@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type NodePool struct {
+	ID         uint64                                `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID string                                `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	ClusterID  string                                `gorm:"not null;index;size:20;comment:'集群资源ID'"`
+	Name       string                                `gorm:"not null;comment:'名称'"`
+	Role       HostRole                              `gorm:"not null;comment:'角色 master worker'"`
+	Labels     datatypes.JSONType[map[string]string] `gorm:"comment:'节点标签'"`
+	Taints     datatypes.JSONType[[]Taint]           `gorm:"comment:'节点污点'"`
+	CreatedAt  time.Time                             `gorm:"comment:'创建时间'"`
+	UpdatedAt  time.Time                             `gorm:"comment:'更新时间'"`
+}
+
+type Node struct {
+	ID              uint64                                `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID      string                                `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	ClusterID       string                                `gorm:"not null;index;size:20;comment:'集群资源ID'"`
+	NodePoolID      string                                `gorm:"index;size:20;comment:'节点池资源ID'"`
+	Role            HostRole                              `gorm:"not null;comment:'角色 master worker'"`
+	IP              string                                `gorm:"not null;comment:'IP地址'"`
+	SSHPort         int                                   `gorm:"not null;comment:'SSH端口'"`
+	CredentialID    string                                `gorm:"not null;size:20;comment:'凭据资源ID'"`
+	Labels          datatypes.JSONType[map[string]string] `gorm:"comment:'节点标签'"`
+	Taints          datatypes.JSONType[[]Taint]           `gorm:"comment:'节点污点'"`
+	Status          NodeStatus                            `gorm:"not null;index;comment:'状态'"`
+	ResourceVersion uint64                                `gorm:"not null;default:1;comment:'资源版本，每次更新加一'"`
+	CreatedAt       time.Time                             `gorm:"comment:'创建时间'"`
+	UpdatedAt       time.Time                             `gorm:"comment:'更新时间'"`
+}
+
+// Taint 节点污点
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// Credential 主机登录凭据，只在 daemon 执行任务与打开终端时读取，不通过接口返回
+type Credential struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID string    `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	Username   string    `gorm:"not null;comment:'用户名'"`
+	Password   string    `gorm:"not null;serializer:credential;comment:'密码，配置 db.credential_key 时加密保存'"`
+	CreatedAt  time.Time `gorm:"comment:'创建时间'"`
+}
+
+// ClusterHost 集群主机的访问信息，由节点与凭据组合而成
+type ClusterHost struct {
+	NodeID   string            `json:"nodeId"`   // 节点资源ID
+	IP       string            `json:"ip"`       // IP地址
+	Port     int               `json:"port"`     // SSH端口
+	Password string            `json:"password"` // root密码
+	Role     HostRole          `json:"role"`     // 角色
+	Labels   map[string]string `json:"labels"`   // 节点标签
+	Taints   []Taint           `json:"taints"`   // 节点污点
+}
+
+// GetNodeByResourceID 根据资源ID获取集群节点
+func GetNodeByResourceID(clusterID string, resourceID string) (*Node, error) {
+	item := &Node{}
+	if err := DB().
+		Where("cluster_id = ? and resource_id = ? and status != ?", clusterID, resourceID, NodeStatusRemoved).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListClusterNodes 按创建顺序列出集群中未移除的节点
+func ListClusterNodes(clusterID string) ([]Node, error) {
+	items := []Node{}
+	if err := DB().
+		Where("cluster_id = ? and status != ?", clusterID, NodeStatusRemoved).
+		Order("id asc").
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// ListNode 根据查询条件分页列出集群节点
+func ListNode(clusterID string, query *ListQuery) ([]Node, error) {
+	items := []Node{}
+	if err := query.apply(DB().Where("cluster_id = ? and status != ?", clusterID, NodeStatusRemoved)).
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// CountNode 计算满足查询条件的集群节点总数
+func CountNode(clusterID string, query *ListQuery) (int, error) {
+	var count int64
+	if err := applyFilters(DB().Model(&Node{}).Where("cluster_id = ? and status != ?", clusterID, NodeStatusRemoved), query.Filters).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+// GetNodePoolByResourceID 根据资源ID获取节点池
+func GetNodePoolByResourceID(clusterID string, resourceID string) (*NodePool, error) {
+	item := &NodePool{}
+	if err := DB().
+		Where("cluster_id = ? and resource_id = ?", clusterID, resourceID).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListNodePool 列出集群节点池
+func ListNodePool(clusterID string) ([]NodePool, error) {
+	items := []NodePool{}
+	if err := DB().
+		Where("cluster_id = ?", clusterID).
+		Order("id desc").
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// ListCredentialByResourceIDs 根据资源ID批量获取凭据
+func ListCredentialByResourceIDs(resourceIDs []string) ([]Credential, error) {
+	items := []Credential{}
+	if len(resourceIDs) == 0 {
+		return items, nil
+	}
+	if err := DB().
+		Where("resource_id IN ?", resourceIDs).
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// UpdateNode 使用指定的数据库连接更新节点字段，只在资源版本未变化时更新并将版本加一，节点已被其他请求修改时返回 ErrConflict
+func UpdateNode(db *gorm.DB, node *Node, fields map[string]interface{}) error {
+	version, err := updateWithResourceVersion(db, &Node{}, node.ID, node.ResourceVersion, fields)
+	if err != nil {
+		return err
+	}
+	node.ResourceVersion = version
+	return nil
+}
+
+// GetCredentialByResourceID 根据资源ID获取凭据
+func GetCredentialByResourceID(resourceID string) (*Credential, error) {
+	item := &Credential{}
+	if err := DB().Where("resource_id = ?", resourceID).Take(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// NewNodeWithCredential 生成节点与凭据的资源ID，返回待创建的节点与凭据，节点状态为加入中
+func NewNodeWithCredential(clusterID string, host ClusterHost) (*Node, *Credential, error) {
+	nodeID, err := GenerateNodeResourceID()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generate node resourceID")
+	}
+	credentialID, err := GenerateCredentialResourceID()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "generate credential resourceID")
+	}
+	credential := &Credential{
+		ResourceID: credentialID.ResourceID,
+		Username:   "root",
+		Password:   host.Password,
+	}
+	node := &Node{
+		ResourceID:      nodeID.ResourceID,
+		ClusterID:       clusterID,
+		Role:            host.Role,
+		IP:              host.IP,
+		SSHPort:         host.Port,
+		CredentialID:    credential.ResourceID,
+		Labels:          datatypes.JSONType[map[string]string]{Data: host.Labels},
+		Taints:          datatypes.JSONType[[]Taint]{Data: host.Taints},
+		Status:          NodeStatusJoining,
+		ResourceVersion: 1,
+	}
+	return node, credential, nil
+}
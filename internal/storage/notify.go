@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// clusterNotifyChannel 集群变更通知的 Redis 频道
+	clusterNotifyChannel = "gin-template:cluster-notify"
+	// clusterEventChannel 集群状态、任务状态与任务日志变化的 Redis 频道
+	clusterEventChannel = "gin-template:cluster-event"
+)
+
+var (
+	// localClusterNotify 进程内的集群变更通知，API 与 daemon 部署在同一进程时使用
+	localClusterNotify = make(chan string, 1024)
+	// localClusterWatchers 进程内接收通知的数量，当前进程不是主节点时没有接收方
+	localClusterWatchers int32
+	// clusterEventSubscribers 进程内订阅集群事件的通道，按集群资源ID分组
+	clusterEventSubscribers = map[string]map[chan struct{}]struct{}{}
+	clusterEventMutex       sync.Mutex
+	// clusterEventWatchOnce 启用键值数据库时只启动一个 Redis 订阅，再分发给进程内的订阅者
+	clusterEventWatchOnce sync.Once
+)
+
+// NotifyCluster 通知 daemon 立即处理集群任务，启用键值数据库时通过 Redis 发布，否则写入进程内通道，通知丢失时由定期同步兜底
+func NotifyCluster(ctx context.Context, resourceID string) error {
+	// 提交任务后集群与最新任务已变化，同时通知事件流
+	if err := PublishClusterEvent(ctx, resourceID); err != nil {
+		log.G(ctx).Warnf("publish cluster event: %s", err)
+	}
+	if KVDB() != nil {
+		if err := KVDB().Publish(ctx, clusterNotifyChannel, resourceID).Err(); err != nil {
+			return errors.Wrap(err, "publish cluster notify")
+		}
+		return nil
+	}
+	if atomic.LoadInt32(&localClusterWatchers) == 0 {
+		return nil
+	}
+	select {
+	case localClusterNotify <- resourceID:
+		return nil
+	default:
+		return errors.New("cluster notify channel is full")
+	}
+}
+
+// WatchCluster 接收集群变更通知并调用 handler，阻塞直到 ctx 结束
+func WatchCluster(ctx context.Context, handler func(resourceID string)) {
+	if KVDB() == nil {
+		atomic.AddInt32(&localClusterWatchers, 1)
+		defer atomic.AddInt32(&localClusterWatchers, -1)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resourceID := <-localClusterNotify:
+				handler(resourceID)
+			}
+		}
+	}
+	watchChannel(ctx, clusterNotifyChannel, handler)
+}
+
+// PublishClusterEvent 通知事件流集群有变化，启用键值数据库时通过 Redis 发布，否则分发给进程内的订阅者，通知丢失时由事件流的定期检查兜底
+func PublishClusterEvent(ctx context.Context, resourceID string) error {
+	if KVDB() != nil {
+		if err := KVDB().Publish(ctx, clusterEventChannel, resourceID).Err(); err != nil {
+			return errors.Wrap(err, "publish cluster event")
+		}
+		return nil
+	}
+	dispatchClusterEvent(resourceID)
+	return nil
+}
+
+// SubscribeClusterEvent 订阅集群事件，集群有变化时通道可读，多次变化合并为一次，调用返回的函数取消订阅
+func SubscribeClusterEvent(resourceID string) (<-chan struct{}, func()) {
+	if KVDB() != nil {
+		clusterEventWatchOnce.Do(func() {
+			go watchChannel(log.S(context.Background(), log.WithField("job", "cluster_event")), clusterEventChannel, dispatchClusterEvent)
+		})
+	}
+	ch := make(chan struct{}, 1)
+	clusterEventMutex.Lock()
+	defer clusterEventMutex.Unlock()
+	if clusterEventSubscribers[resourceID] == nil {
+		clusterEventSubscribers[resourceID] = map[chan struct{}]struct{}{}
+	}
+	clusterEventSubscribers[resourceID][ch] = struct{}{}
+	return ch, func() {
+		clusterEventMutex.Lock()
+		defer clusterEventMutex.Unlock()
+		delete(clusterEventSubscribers[resourceID], ch)
+		if len(clusterEventSubscribers[resourceID]) == 0 {
+			delete(clusterEventSubscribers, resourceID)
+		}
+	}
+}
+
+// dispatchClusterEvent 唤醒集群的全部订阅者，已有未处理的事件时跳过
+func dispatchClusterEvent(resourceID string) {
+	clusterEventMutex.Lock()
+	defer clusterEventMutex.Unlock()
+	for ch := range clusterEventSubscribers[resourceID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watchChannel 订阅 Redis 频道并调用 handler，阻塞直到 ctx 结束
+func watchChannel(ctx context.Context, channel string, handler func(resourceID string)) {
+	sub := KVDB().Subscribe(ctx, channel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.G(ctx).Warnf("%s subscription closed", channel)
+				return
+			}
+			handler(msg.Payload)
+		}
+	}
+}
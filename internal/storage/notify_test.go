@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// waitFor 等待条件成立，超时后测试失败
+func waitFor(t *testing.T, name string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// receive 从通道读取集群资源ID，超时后测试失败
+func receive(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case resourceID := <-ch:
+		return resourceID
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for cluster notify")
+		return ""
+	}
+}
+
+func TestNotifyClusterLocal(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	// 没有接收方时丢弃通知，由定期同步兜底
+	if err := NotifyCluster(context.Background(), "cluster-dropped"); err != nil {
+		t.Fatalf("notify cluster: %s", err)
+	}
+	if len(localClusterNotify) != 0 {
+		t.Fatalf("expected notify dropped without watchers, got %d pending", len(localClusterNotify))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan string, 1)
+	done := make(chan struct{})
+	go func() {
+		WatchCluster(ctx, func(resourceID string) { received <- resourceID })
+		close(done)
+	}()
+	waitFor(t, "local watcher", func() bool { return atomic.LoadInt32(&localClusterWatchers) == 1 })
+	// 通知 daemon 的同时唤醒事件流
+	events, unsubscribe := SubscribeClusterEvent("cluster-a")
+	defer unsubscribe()
+	if err := NotifyCluster(context.Background(), "cluster-a"); err != nil {
+		t.Fatalf("notify cluster: %s", err)
+	}
+	if resourceID := receive(t, received); resourceID != "cluster-a" {
+		t.Errorf("expected cluster-a, got %s", resourceID)
+	}
+	select {
+	case <-events:
+	default:
+		t.Error("cluster event subscriber not woken")
+	}
+	cancel()
+	<-done
+	if watchers := atomic.LoadInt32(&localClusterWatchers); watchers != 0 {
+		t.Errorf("expected no watchers after cancel, got %d", watchers)
+	}
+}
+
+func TestNotifyClusterRedis(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	mr := miniredis.RunT(t)
+	sharedKVDB = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		sharedKVDB.Close()
+		sharedKVDB = nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan string, 1)
+	go WatchCluster(ctx, func(resourceID string) { received <- resourceID })
+	waitFor(t, "redis subscription", func() bool { return mr.PubSubNumSub(clusterNotifyChannel)[clusterNotifyChannel] == 1 })
+	// 其他副本的事件流通过 Redis 订阅唤醒
+	events, unsubscribe := SubscribeClusterEvent("cluster-a")
+	defer unsubscribe()
+	waitFor(t, "redis event subscription", func() bool { return mr.PubSubNumSub(clusterEventChannel)[clusterEventChannel] == 1 })
+	if err := NotifyCluster(context.Background(), "cluster-a"); err != nil {
+		t.Fatalf("notify cluster: %s", err)
+	}
+	if resourceID := receive(t, received); resourceID != "cluster-a" {
+		t.Errorf("expected cluster-a, got %s", resourceID)
+	}
+	select {
+	case <-events:
+	case <-time.After(5 * time.Second):
+		t.Error("cluster event subscriber not woken")
+	}
+	// 本地通道不使用
+	if len(localClusterNotify) != 0 {
+		t.Errorf("expected no local notify, got %d pending", len(localClusterNotify))
+	}
+}
+
+func TestClusterEventSubscribe(t *testing.T) {
+	first, unsubscribeFirst := SubscribeClusterEvent("cluster-a")
+	second, unsubscribeSecond := SubscribeClusterEvent("cluster-a")
+	other, unsubscribeOther := SubscribeClusterEvent("cluster-b")
+	defer unsubscribeOther()
+	// 多次变化合并为一次唤醒
+	for i := 0; i < 3; i++ {
+		if err := PublishClusterEvent(context.Background(), "cluster-a"); err != nil {
+			t.Fatalf("publish cluster event: %s", err)
+		}
+	}
+	for name, ch := range map[string]<-chan struct{}{"first": first, "second": second} {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("%s subscriber not woken", name)
+		}
+		select {
+		case <-ch:
+			t.Errorf("%s subscriber woken twice", name)
+		default:
+		}
+	}
+	select {
+	case <-other:
+		t.Error("subscriber of another cluster woken")
+	default:
+	}
+	unsubscribeFirst()
+	unsubscribeSecond()
+	clusterEventMutex.Lock()
+	_, ok := clusterEventSubscribers["cluster-a"]
+	clusterEventMutex.Unlock()
+	if ok {
+		t.Error("subscribers not removed after unsubscribe")
+	}
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// 分页令牌签名的截断长度
+const pageTokenMACSize = 16
+
+// pageTokenSecret 分页令牌的签名密钥，未配置时在启动时随机生成，重启后之前的令牌失效
+var pageTokenSecret []byte
+
+// setPageTokenSecret 设置分页令牌的签名密钥，返回是否使用了随机生成的密钥
+func setPageTokenSecret(secret string) (bool, error) {
+	if len(secret) > 0 {
+		pageTokenSecret = []byte(secret)
+		return false, nil
+	}
+	pageTokenSecret = make([]byte, 32)
+	if _, err := rand.Read(pageTokenSecret); err != nil {
+		return false, errors.Wrap(err, "generate page token secret")
+	}
+	return true, nil
+}
+
+// pageCursor 键集分页的位置，记录上一页最后一条记录的排序字段值与ID
+type pageCursor struct {
+	Scope string `json:"s"`           // 查询范围的摘要，令牌只能用于生成它的查询
+	Value string `json:"v,omitempty"` // 排序字段的值，时间保存为 unix 纳秒
+	ID    uint64 `json:"i"`           // 记录ID
+}
+
+// SetPage 设置分页参数，使用分页令牌时从令牌记录的位置开始，否则使用页码计算分页偏移，两者不能同时使用
+func (q *ListQuery) SetPage(pageNo int, pageSize int, pageToken string) error {
+	q.Limit = pageSize
+	if len(pageToken) == 0 {
+		if pageNo > 0 {
+			q.Offset = (pageNo - 1) * pageSize
+		}
+		return nil
+	}
+	if pageNo > 0 {
+		return errors.New("pageNo and pageToken are mutually exclusive")
+	}
+	return q.setPageToken(pageToken)
+}
+
+// setPageToken 解析并校验分页令牌，令牌被篡改或不属于当前查询时返回错误
+func (q *ListQuery) setPageToken(token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return errors.New("malformed page token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return errors.Wrap(err, "decode page token")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errors.Wrap(err, "decode page token signature")
+	}
+	if !hmac.Equal(mac, signPageToken(payload)) {
+		return errors.New("page token signature mismatch")
+	}
+	cursor := &pageCursor{}
+	if err := json.Unmarshal(payload, cursor); err != nil {
+		return errors.Wrap(err, "unmarshal page token")
+	}
+	if cursor.Scope != q.Scope {
+		return errors.New("page token does not match query")
+	}
+	q.cursor = cursor
+	return nil
+}
+
+func signPageToken(payload []byte) []byte {
+	h := hmac.New(sha256.New, pageTokenSecret)
+	h.Write(payload)
+	return h.Sum(nil)[:pageTokenMACSize]
+}
+
+func encodePageToken(cursor *pageCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal page token")
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signPageToken(payload)), nil
+}
+
+// applyPage 添加过滤、排序与分页条件，使用令牌时从上一页最后一条记录之后开始，多查询一条记录用于判断是否还有下一页
+func (q *ListQuery) applyPage(db *gorm.DB) (*gorm.DB, error) {
+	if q.cursor != nil {
+		cmp := "<"
+		if q.SortOrder == SortOrderAsc {
+			cmp = ">"
+		}
+		if len(q.SortBy) > 0 {
+			value, err := parseCursorValue(q.cursor.Value, q.sortType)
+			if err != nil {
+				return nil, errors.Wrap(err, "parse page token")
+			}
+			db = db.Where("("+q.SortBy+" "+cmp+" ? OR ("+q.SortBy+" = ? AND id "+cmp+" ?))", value, value, q.cursor.ID)
+		} else {
+			db = db.Where("id "+cmp+" ?", q.cursor.ID)
+		}
+	}
+	return q.apply(db).Limit(q.Limit + 1), nil
+}
+
+// paginate 截取当前页的记录，还有下一页时返回指向下一页的令牌
+func paginate[T any](q *ListQuery, items []T) ([]T, string, error) {
+	if len(items) <= q.Limit {
+		return items, "", nil
+	}
+	items = items[:q.Limit]
+	stmt := &gorm.Statement{DB: DB()}
+	if err := stmt.Parse(&items[len(items)-1]); err != nil {
+		return nil, "", errors.Wrap(err, "parse model")
+	}
+	last := reflect.ValueOf(&items[len(items)-1]).Elem()
+	ctx := context.Background()
+	cursor := &pageCursor{Scope: q.Scope}
+	id, _ := stmt.Schema.LookUpField("id").ValueOf(ctx, last)
+	cursor.ID = reflect.ValueOf(id).Uint()
+	if len(q.SortBy) > 0 {
+		value, _ := stmt.Schema.LookUpField(q.SortBy).ValueOf(ctx, last)
+		cursor.Value = formatCursorValue(value)
+	}
+	token, err := encodePageToken(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	return items, token, nil
+}
+
+func formatCursorValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	default:
+		return v.String()
+	}
+}
+
+func parseCursorValue(value string, fieldType ListFieldType) (interface{}, error) {
+	switch fieldType {
+	case ListFieldInt:
+		return strconv.ParseInt(value, 10, 64)
+	case ListFieldTime:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(0, v), nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+)
+
+func TestPageTokenRejected(t *testing.T) {
+	if _, err := setPageTokenSecret("secret"); err != nil {
+		t.Fatalf("set page token secret: %s", err)
+	}
+	token, err := encodePageToken(&pageCursor{Scope: "scope-a", Value: "demo", ID: 10})
+	if err != nil {
+		t.Fatalf("encode page token: %s", err)
+	}
+	query := &ListQuery{Scope: "scope-a"}
+	if err := query.SetPage(0, 10, token); err != nil {
+		t.Fatalf("set page: %s", err)
+	}
+	if !reflect.DeepEqual(query.cursor, &pageCursor{Scope: "scope-a", Value: "demo", ID: 10}) {
+		t.Errorf("unexpected cursor: %+v", query.cursor)
+	}
+	parts := strings.Split(token, ".")
+	// 修改令牌中的记录ID，保留原来的签名
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"scope-a","v":"demo","i":1}`)) + "." + parts[1]
+	tests := []struct {
+		name  string
+		scope string
+		token string
+		err   string
+	}{
+		{name: "tampered payload", scope: "scope-a", token: tampered, err: "signature mismatch"},
+		{name: "tampered signature", scope: "scope-a", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString(make([]byte, pageTokenMACSize)), err: "signature mismatch"},
+		{name: "malformed", scope: "scope-a", token: parts[0], err: "malformed page token"},
+		{name: "other query", scope: "scope-b", token: token, err: "does not match query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &ListQuery{Scope: tt.scope}
+			if err := query.SetPage(0, 10, tt.token); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+			if query.cursor != nil {
+				t.Errorf("expected no cursor, got %+v", query.cursor)
+			}
+		})
+	}
+	// 更换密钥后之前的令牌失效
+	if _, err := setPageTokenSecret("other-secret"); err != nil {
+		t.Fatalf("set page token secret: %s", err)
+	}
+	if err := (&ListQuery{Scope: "scope-a"}).SetPage(0, 10, token); err == nil || !strings.Contains(err.Error(), "signature mismatch") {
+		t.Errorf("expected signature mismatch with another secret, got %v", err)
+	}
+	if err := (&ListQuery{Scope: "scope-a"}).SetPage(1, 10, token); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("expected pageNo and pageToken to be mutually exclusive, got %v", err)
+	}
+}
+
+func TestPageTokenKeysetContinuation(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	// 排序字段的值存在重复，翻页时依靠记录ID区分
+	createdAt := time.Unix(1700000000, 0)
+	names := []string{"b", "a", "c", "a", "b", "a", "c"}
+	statuses := []ClusterStatus{ClusterStatusRunning, ClusterStatusError, ClusterStatusRunning, ClusterStatusRunning, ClusterStatusError, ClusterStatusRunning, ClusterStatusError}
+	for i, name := range names {
+		cluster := &Cluster{ResourceID: fmt.Sprintf("cluster-%d", i), Name: name, ProjectID: DefaultProjectID, Status: statuses[i], CreatedAt: createdAt}
+		if err := DB().Create(cluster).Error; err != nil {
+			t.Fatalf("create cluster: %s", err)
+		}
+	}
+	for _, sortBy := range []string{"name", "status", "createTime"} {
+		for _, sortOrder := range []string{"asc", "desc"} {
+			t.Run(sortBy+" "+sortOrder, func(t *testing.T) {
+				column := ClusterListSchema[sortBy].Column
+				expected := []string{}
+				if err := DB().Model(&Cluster{}).Order(column+" "+sortOrder).Order("id "+sortOrder).Pluck("resource_id", &expected).Error; err != nil {
+					t.Fatalf("list expected clusters: %s", err)
+				}
+				resourceIDs := []string{}
+				token := ""
+				for page := 0; page < len(names); page++ {
+					query, err := ClusterListSchema.ParseListQuery(nil, sortBy, sortOrder)
+					if err != nil {
+						t.Fatalf("parse list query: %s", err)
+					}
+					query.Scope = sortBy + ":" + sortOrder
+					if err := query.SetPage(0, 2, token); err != nil {
+						t.Fatalf("set page: %s", err)
+					}
+					items, nextPageToken, err := ListCluster(DefaultProjectID, query)
+					if err != nil {
+						t.Fatalf("list cluster: %s", err)
+					}
+					for _, item := range items {
+						resourceIDs = append(resourceIDs, item.ResourceID)
+					}
+					if len(nextPageToken) == 0 {
+						break
+					}
+					token = nextPageToken
+				}
+				if !reflect.DeepEqual(resourceIDs, expected) {
+					t.Errorf("expected %v, got %v", expected, resourceIDs)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultProjectID 默认项目的资源ID，由数据迁移创建，迁移前的集群与任务都归属默认项目
+const DefaultProjectID = "project-default"
+
+// Project 项目，集群与任务归属于项目，不同项目的调用者互相不可见
+type Project struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID   string    `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	Name         string    `gorm:"not null;uniqueIndex;size:128;comment:'名称'"`
+	Description  string    `gorm:"comment:'项目描述信息'"`
+	ClusterQuota int       `gorm:"not null;comment:'集群数量配额 0-不限制'"`
+	CreatedAt    time.Time `gorm:"comment:'创建时间'"`
+	UpdatedAt    time.Time `gorm:"comment:'更新时间'"`
+}
+
+// GetProjectByResourceID 根据资源ID获取项目
+func GetProjectByResourceID(resourceID string) (*Project, error) {
+	item := &Project{}
+	if err := DB().
+		Where("resource_id = ?", resourceID).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetProjectByName 根据名称获取项目
+func GetProjectByName(name string) (*Project, error) {
+	item := &Project{}
+	if err := DB().
+		Where("name = ?", name).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListProject 分页列出项目
+func ListProject(offset int, limit int) ([]Project, error) {
+	items := []Project{}
+	if err := DB().
+		Limit(limit).
+		Offset(offset).
+		Order("id desc").
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// CountProject 计算项目总数
+func CountProject() (int, error) {
+	var count int64
+	if err := DB().
+		Model(&Project{}).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+// UpdateProject 更新项目描述与集群数量配额
+func UpdateProject(item *Project) error {
+	if err := DB().Model(item).Updates(map[string]interface{}{
+		"description":   item.Description,
+		"cluster_quota": item.ClusterQuota,
+	}).Error; err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// CheckClusterQuota 在事务中检查项目未删除的集群数量是否已达到配额，达到配额时返回 ErrQuotaExceeded
+// 先锁定项目记录，并发创建或恢复集群时串行计数，避免同时通过检查后超出配额
+func CheckClusterQuota(tx *gorm.DB, projectID string) error {
+	project := &Project{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("resource_id = ?", projectID).
+		Take(project).Error; err != nil {
+		return errors.Wrap(err, "get project")
+	}
+	if project.ClusterQuota <= 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&Cluster{}).
+		Where("project_id = ?", projectID).
+		Count(&count).Error; err != nil {
+		return errors.Wrap(err, "count cluster")
+	}
+	if int(count) >= project.ClusterQuota {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// FilterOperator 列表查询的过滤操作符
+type FilterOperator string
+
+const (
+	FilterOpEq      FilterOperator = "eq"      // 等于
+	FilterOpNe      FilterOperator = "ne"      // 不等于
+	FilterOpIn      FilterOperator = "in"      // 属于，多个值使用逗号分隔
+	FilterOpLike    FilterOperator = "like"    // 模糊匹配，只支持字符串字段
+	FilterOpGt      FilterOperator = "gt"      // 大于，只支持数值与时间字段
+	FilterOpLt      FilterOperator = "lt"      // 小于，只支持数值与时间字段
+	FilterOpBetween FilterOperator = "between" // 闭区间，两个值使用逗号分隔，只支持数值与时间字段
+)
+
+// 比较类操作符对应的 SQL 符号
+var filterOpSymbols = map[FilterOperator]string{
+	FilterOpEq: "=",
+	FilterOpNe: "<>",
+	FilterOpGt: ">",
+	FilterOpLt: "<",
+}
+
+// LIKE 的转义字符，MySQL 字符串中的反斜杠本身需要转义，使用在 MySQL 与 SQLite 中含义相同的字符
+const likeEscapeChar = "!"
+
+// likeEscaper 转义过滤值中的通配符，模糊匹配只匹配字面值
+var likeEscaper = strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar, "%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+
+// SortOrder 排序方向
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+// 单次查询允许的过滤条件数量上限
+const maxListFilters = 10
+
+// ListFieldType 列表字段的类型，决定过滤值的解析方式与支持的操作符
+type ListFieldType uint8
+
+const (
+	ListFieldString ListFieldType = iota // 字符串
+	ListFieldInt                         // 整数
+	ListFieldTime                        // 时间，过滤值为 unix 时间戳（秒）
+)
+
+// ListField 列表查询允许过滤或排序的字段
+type ListField struct {
+	Column   string                 // 数据库列名
+	Type     ListFieldType          // 字段类型
+	Sortable bool                   // 是否允许排序
+	Enum     map[string]interface{} // 枚举名称到值的映射，例如状态名称，过滤值为名称时转换为对应的值
+}
+
+// ListSchema 模型允许查询的字段，键为接口中的字段名
+type ListSchema map[string]ListField
+
+// ClusterListSchema 集群列表允许查询的字段
+var ClusterListSchema = ListSchema{
+	"name":       {Column: "name", Type: ListFieldString, Sortable: true},
+	"resourceID": {Column: "resource_id", Type: ListFieldString},
+	"type":       {Column: "type", Type: ListFieldString},
+	"version":    {Column: "version", Type: ListFieldString, Sortable: true},
+	"runtime":    {Column: "runtime", Type: ListFieldString},
+	"status":     {Column: "status", Type: ListFieldInt, Sortable: true, Enum: enumValues(ClusterStatusCreating, ClusterStatusDeleting, ClusterStatusUpgrading, ClusterStatusRunning, ClusterStatusDeleted, ClusterStatusError)},
+	"createTime": {Column: "created_at", Type: ListFieldTime, Sortable: true},
+	"updateTime": {Column: "updated_at", Type: ListFieldTime, Sortable: true},
+}
+
+// TaskListSchema 任务列表允许查询的字段
+var TaskListSchema = ListSchema{
+	"action":     {Column: "action", Type: ListFieldString},
+	"status":     {Column: "status", Type: ListFieldInt, Sortable: true, Enum: enumValues(TaskStatusEnqueued, TaskStatusRetrying, TaskStatusSuccess, TaskStatusFail, TaskStatusCanceled)},
+	"retryCount": {Column: "retry_count", Type: ListFieldInt, Sortable: true},
+	"requestID":  {Column: "request_id", Type: ListFieldString},
+	"createTime": {Column: "created_at", Type: ListFieldTime, Sortable: true},
+	"updateTime": {Column: "updated_at", Type: ListFieldTime, Sortable: true},
+}
+
+// NodeListSchema 节点列表允许查询的字段
+var NodeListSchema = ListSchema{
+	"resourceID": {Column: "resource_id", Type: ListFieldString},
+	"nodePoolID": {Column: "node_pool_id", Type: ListFieldString},
+	"role":       {Column: "role", Type: ListFieldString, Sortable: true},
+	"ip":         {Column: "ip", Type: ListFieldString, Sortable: true},
+	"status":     {Column: "status", Type: ListFieldInt, Sortable: true, Enum: enumValues(NodeStatusJoining, NodeStatusDraining, NodeStatusRemoving, NodeStatusRunning, NodeStatusDrained, NodeStatusRemoved, NodeStatusError)},
+	"createTime": {Column: "created_at", Type: ListFieldTime, Sortable: true},
+}
+
+func enumValues(items ...fmt.Stringer) map[string]interface{} {
+	values := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		values[item.String()] = item
+	}
+	return values
+}
+
+// ListQuery 列表查询条件，过滤条件使用 AND 组合，未指定排序字段时按 ID 倒序
+type ListQuery struct {
+	Filters   []*Filter // 过滤条件
+	SortBy    string    // 排序的数据库列名
+	SortOrder SortOrder // 排序方向
+	Offset    int       // 分页偏移
+	Limit     int       // 分页大小
+	Scope     string    // 查询范围的摘要，分页令牌只能用于相同范围的查询
+
+	sortType ListFieldType // 排序字段的类型，用于解析分页令牌中的值
+	cursor   *pageCursor   // 分页令牌记录的位置
+}
+
+// ParseListQuery 根据模型允许的字段解析过滤与排序参数，过滤参数格式为 field:op:value
+func (s ListSchema) ParseListQuery(filters []string, sortBy string, sortOrder string) (*ListQuery, error) {
+	if len(filters) > maxListFilters {
+		return nil, errors.Errorf("too many filters, max %d", maxListFilters)
+	}
+	query := &ListQuery{SortOrder: SortOrderDesc}
+	for _, item := range filters {
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 {
+			return nil, errors.Errorf("invalid filter %s, expect field:op:value", item)
+		}
+		filter, err := s.NewFilter(parts[0], FilterOperator(parts[1]), parts[2])
+		if err != nil {
+			return nil, err
+		}
+		query.Filters = append(query.Filters, filter)
+	}
+	if len(sortBy) > 0 {
+		field, ok := s[sortBy]
+		if !ok || !field.Sortable {
+			return nil, errors.Errorf("unsupported sortBy %s", sortBy)
+		}
+		query.SortBy = field.Column
+		query.sortType = field.Type
+	}
+	switch SortOrder(sortOrder) {
+	case "":
+	case SortOrderAsc, SortOrderDesc:
+		query.SortOrder = SortOrder(sortOrder)
+	default:
+		return nil, errors.Errorf("unsupported sortOrder %s", sortOrder)
+	}
+	return query, nil
+}
+
+// NewFilter 根据字段、操作符与过滤值生成过滤条件，多个值使用逗号分隔
+func (s ListSchema) NewFilter(name string, op FilterOperator, value string) (*Filter, error) {
+	field, ok := s[name]
+	if !ok {
+		return nil, errors.Errorf("unsupported filter field %s", name)
+	}
+	if len(value) == 0 {
+		return nil, errors.Errorf("empty filter value for %s", name)
+	}
+	switch op {
+	case FilterOpEq, FilterOpNe, FilterOpGt, FilterOpLt:
+		if field.Type == ListFieldString && (op == FilterOpGt || op == FilterOpLt) {
+			return nil, errors.Errorf("unsupported operator %s for %s", op, name)
+		}
+		v, err := field.parse(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter value for %s", name)
+		}
+		return &Filter{Query: field.Column + " " + filterOpSymbols[op] + " ?", Args: []interface{}{v}}, nil
+	case FilterOpIn:
+		items := strings.Split(value, ",")
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			v, err := field.parse(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid filter value for %s", name)
+			}
+			values = append(values, v)
+		}
+		return &Filter{Query: field.Column + " IN ?", Args: []interface{}{values}}, nil
+	case FilterOpLike:
+		if field.Type != ListFieldString {
+			return nil, errors.Errorf("unsupported operator %s for %s", op, name)
+		}
+		return &Filter{Query: field.Column + " LIKE ? ESCAPE '" + likeEscapeChar + "'", Args: []interface{}{"%" + likeEscaper.Replace(value) + "%"}}, nil
+	case FilterOpBetween:
+		if field.Type == ListFieldString {
+			return nil, errors.Errorf("unsupported operator %s for %s", op, name)
+		}
+		items := strings.Split(value, ",")
+		if len(items) != 2 {
+			return nil, errors.Errorf("invalid filter value for %s, expect start,end", name)
+		}
+		start, err := field.parse(items[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter value for %s", name)
+		}
+		end, err := field.parse(items[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter value for %s", name)
+		}
+		return &Filter{Query: field.Column + " BETWEEN ? AND ?", Args: []interface{}{start, end}}, nil
+	default:
+		return nil, errors.Errorf("unsupported operator %s", op)
+	}
+}
+
+// parse 将过滤值转换为字段类型对应的值
+func (f ListField) parse(value string) (interface{}, error) {
+	if v, ok := f.Enum[value]; ok {
+		return v, nil
+	}
+	switch f.Type {
+	case ListFieldInt:
+		return strconv.ParseInt(value, 10, 64)
+	case ListFieldTime:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(v, 0), nil
+	default:
+		return value, nil
+	}
+}
+
+// Where 添加过滤条件
+func (q *ListQuery) Where(filters ...*Filter) {
+	q.Filters = append(q.Filters, filters...)
+}
+
+// apply 添加过滤、排序与分页条件，使用 ID 作为最后的排序字段保证分页稳定
+func (q *ListQuery) apply(db *gorm.DB) *gorm.DB {
+	db = applyFilters(db, q.Filters)
+	order := q.SortOrder
+	if order != SortOrderAsc {
+		order = SortOrderDesc
+	}
+	if len(q.SortBy) > 0 {
+		db = db.Order(q.SortBy + " " + string(order))
+	}
+	return db.Order("id " + string(order)).Offset(q.Offset).Limit(q.Limit)
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		filters   []string
+		sortBy    string
+		sortOrder string
+		query     string
+		args      []interface{}
+		err       string
+	}{
+		{name: "eq", filters: []string{"name:eq:demo"}, query: "name = ?", args: []interface{}{"demo"}},
+		{name: "value with colon", filters: []string{"version:ne:1.2:3"}, query: "version <> ?", args: []interface{}{"1.2:3"}},
+		{name: "enum value", filters: []string{"status:eq:Running"}, query: "status = ?", args: []interface{}{ClusterStatusRunning}},
+		{name: "enum in", filters: []string{"status:in:Running,Error"}, query: "status IN ?", args: []interface{}{[]interface{}{ClusterStatusRunning, ClusterStatusError}}},
+		{name: "int value for enum field", filters: []string{"status:gt:3"}, query: "status > ?", args: []interface{}{int64(3)}},
+		{name: "like", filters: []string{"name:like:demo"}, query: "name LIKE ? ESCAPE '!'", args: []interface{}{"%demo%"}},
+		{name: "like escapes wildcards", filters: []string{"name:like:a%b_c!d"}, query: "name LIKE ? ESCAPE '!'", args: []interface{}{"%a!%b!_c!!d%"}},
+		{name: "between", filters: []string{"createTime:between:100,200"}, query: "created_at BETWEEN ? AND ?", args: []interface{}{time.Unix(100, 0), time.Unix(200, 0)}},
+		{name: "unknown field", filters: []string{"unknown:eq:1"}, err: "unsupported filter field"},
+		{name: "bad operator", filters: []string{"name:regexp:demo"}, err: "unsupported operator"},
+		{name: "missing value", filters: []string{"name:eq"}, err: "expect field:op:value"},
+		{name: "empty value", filters: []string{"name:eq:"}, err: "empty filter value"},
+		{name: "gt on string", filters: []string{"name:gt:demo"}, err: "unsupported operator gt for name"},
+		{name: "like on int", filters: []string{"status:like:Run"}, err: "unsupported operator like for status"},
+		{name: "unknown enum value", filters: []string{"status:eq:Unknown"}, err: "invalid filter value"},
+		{name: "between on string", filters: []string{"name:between:a,b"}, err: "unsupported operator between"},
+		{name: "between with one value", filters: []string{"createTime:between:100"}, err: "expect start,end"},
+		{name: "between with invalid value", filters: []string{"createTime:between:100,tomorrow"}, err: "invalid filter value"},
+		{name: "too many filters", filters: strings.Split(strings.Repeat("name:eq:demo,", maxListFilters+1), ",")[:maxListFilters+1], err: "too many filters"},
+		{name: "sort", sortBy: "createTime", sortOrder: "asc"},
+		{name: "unsortable field", sortBy: "runtime", err: "unsupported sortBy"},
+		{name: "unknown sort field", sortBy: "unknown", err: "unsupported sortBy"},
+		{name: "bad sort order", sortBy: "name", sortOrder: "up", err: "unsupported sortOrder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ClusterListSchema.ParseListQuery(tt.filters, tt.sortBy, tt.sortOrder)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(tt.query) > 0 {
+				if len(query.Filters) != 1 {
+					t.Fatalf("expected 1 filter, got %d", len(query.Filters))
+				}
+				if query.Filters[0].Query != tt.query {
+					t.Errorf("expected query %q, got %q", tt.query, query.Filters[0].Query)
+				}
+				if !reflect.DeepEqual(query.Filters[0].Args, tt.args) {
+					t.Errorf("expected args %v, got %v", tt.args, query.Filters[0].Args)
+				}
+			}
+			if len(tt.sortBy) > 0 && query.SortBy != ClusterListSchema[tt.sortBy].Column {
+				t.Errorf("expected sortBy %s, got %s", ClusterListSchema[tt.sortBy].Column, query.SortBy)
+			}
+			if len(tt.sortOrder) == 0 && query.SortOrder != SortOrderDesc {
+				t.Errorf("expected default sort order desc, got %s", query.SortOrder)
+			}
+		})
+	}
+}
+
+func TestLikeFilterMatchesLiterally(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	names := []string{"a%b", "a_b", "axb", `a\b`, "a!b"}
+	for i, name := range names {
+		if err := DB().Create(&Cluster{ResourceID: fmt.Sprintf("cluster-%d", i), Name: name, ProjectID: DefaultProjectID}).Error; err != nil {
+			t.Fatalf("create cluster: %s", err)
+		}
+	}
+	tests := []struct {
+		value string
+		match []string
+	}{
+		{"%", []string{"a%b"}},
+		{"_", []string{"a_b"}},
+		{`\`, []string{`a\b`}},
+		{"!", []string{"a!b"}},
+		{"a", names},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			filter, err := ClusterListSchema.NewFilter("name", FilterOpLike, tt.value)
+			if err != nil {
+				t.Fatalf("new filter: %s", err)
+			}
+			items := []string{}
+			if err := DB().Model(&Cluster{}).Where(filter.Query, filter.Args...).Order("id asc").Pluck("name", &items).Error; err != nil {
+				t.Fatalf("query: %s", err)
+			}
+			if !reflect.DeepEqual(items, tt.match) {
+				t.Errorf("expected %v, got %v", tt.match, items)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Role 角色，内置角色通过数据迁移创建，不允许删除
+type Role struct {
+	ID          uint64                           `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	Name        string                           `gorm:"not null;uniqueIndex;size:64;comment:'名称'"`
+	Description string                           `gorm:"not null;comment:'描述'"`
+	Permissions datatypes.JSONType[[]Permission] `gorm:"comment:'权限列表'"`
+	Builtin     bool                             `gorm:"not null;comment:'是否内置'"`
+	CreatedAt   time.Time                        `gorm:"comment:'创建时间'"`
+	UpdatedAt   time.Time                        `gorm:"comment:'更新时间'"`
+}
+
+// Allows 角色是否拥有指定权限
+func (r *Role) Allows(permission Permission) bool {
+	for _, p := range r.Permissions.Data {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleBinding 将角色授予调用者，ProjectID 为空时角色在全部项目中生效，否则只在该项目中生效
+type RoleBinding struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_role_binding;size:255;comment:'调用者身份'"`
+	RoleName  string    `gorm:"not null;uniqueIndex:idx_role_binding;size:64;index;comment:'角色名称'"`
+	ProjectID string    `gorm:"not null;default:'';uniqueIndex:idx_role_binding;size:20;index;comment:'项目资源ID 空-全部项目'"`
+	CreatedAt time.Time `gorm:"comment:'创建时间'"`
+}
+
+// SubjectHasPermission 调用者在项目中绑定的角色是否拥有指定权限，projectID 为空或权限为全局权限时只检查不限定项目的角色绑定
+func SubjectHasPermission(subject string, projectID string, permission Permission) (bool, error) {
+	roles := []Role{}
+	db := DB().
+		Joins("JOIN role_bindings ON role_bindings.role_name = roles.name").
+		Where("role_bindings.subject = ?", subject)
+	if len(projectID) == 0 || permission.Global() {
+		db = db.Where("role_bindings.project_id = ?", "")
+	} else {
+		db = db.Where("role_bindings.project_id IN ?", []string{"", projectID})
+	}
+	if err := db.Find(&roles).Error; err != nil {
+		return false, handleStorageError(err)
+	}
+	for i := range roles {
+		if roles[i].Allows(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetRoleByName 根据名称获取角色
+func GetRoleByName(name string) (*Role, error) {
+	item := &Role{}
+	if err := DB().
+		Where("name = ?", name).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListRole 列出全部角色
+func ListRole() ([]Role, error) {
+	items := []Role{}
+	if err := DB().
+		Order("id asc").
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// DeleteRole 在事务中删除角色与角色绑定
+func DeleteRole(role *Role) error {
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_name = ?", role.Name).Delete(&RoleBinding{}).Error; err != nil {
+			return errors.Wrap(err, "delete role bindings")
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			return errors.Wrap(err, "delete role")
+		}
+		return nil
+	}); err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// GetRoleBindingByID 根据ID获取角色绑定
+func GetRoleBindingByID(id uint64) (*RoleBinding, error) {
+	item := &RoleBinding{}
+	if err := DB().
+		Where("id = ?", id).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListRoleBinding 列出角色绑定，subject 不为空时只列出该调用者的角色绑定
+func ListRoleBinding(subject string) ([]RoleBinding, error) {
+	items := []RoleBinding{}
+	db := DB().Order("id asc")
+	if len(subject) > 0 {
+		db = db.Where("subject = ?", subject)
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// CreateRoleBinding 将角色授予调用者，projectID 不为空时只在该项目中生效，角色或项目不存在时返回 ErrDoesNotExist，已授予时返回 ErrAlreadyExists
+func CreateRoleBinding(subject string, roleName string, projectID string) (*RoleBinding, error) {
+	if _, err := GetRoleByName(roleName); err != nil {
+		return nil, err
+	}
+	if len(projectID) > 0 {
+		if _, err := GetProjectByResourceID(projectID); err != nil {
+			return nil, err
+		}
+	}
+	var count int64
+	if err := DB().
+		Model(&RoleBinding{}).
+		Where("subject = ? and role_name = ? and project_id = ?", subject, roleName, projectID).
+		Count(&count).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	if count > 0 {
+		return nil, ErrAlreadyExists
+	}
+	item := &RoleBinding{Subject: subject, RoleName: roleName, ProjectID: projectID}
+	if err := DB().Create(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// builtinRoles 内置角色
+var builtinRoles = []Role{
+	{
+		Name:        RoleViewer,
+		Description: "read only access to clusters, nodes and tasks",
+		Permissions: datatypes.JSONType[[]Permission]{Data: []Permission{
+			PermissionClusterRead,
+			PermissionTaskRead,
+		}},
+		Builtin: true,
+	},
+	{
+		Name:        RoleOperator,
+		Description: "manage clusters, nodes and tasks except deleting clusters",
+		Permissions: datatypes.JSONType[[]Permission]{Data: []Permission{
+			PermissionClusterRead,
+			PermissionClusterCreate,
+			PermissionClusterUpdate,
+			PermissionTaskRead,
+			PermissionTaskUpdate,
+		}},
+		Builtin: true,
+	},
+	{
+		Name:        RoleAdmin,
+		Description: "full access",
+		Permissions: datatypes.JSONType[[]Permission]{Data: []Permission{PermissionAll}},
+		Builtin:     true,
+	},
+}
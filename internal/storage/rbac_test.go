@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"testing"
+
+	"gitbub.com/wbuntu/gin-template/internal/pkg/testutil"
+	"gorm.io/datatypes"
+)
+
+func TestPermissionGlobal(t *testing.T) {
+	global := map[Permission]bool{
+		PermissionRoleRead:      true,
+		PermissionRoleUpdate:    true,
+		PermissionProjectUpdate: true,
+		PermissionAdminRead:     true,
+		PermissionAdminUpdate:   true,
+		PermissionAuditRead:     true,
+	}
+	for _, p := range Permissions {
+		if p.Global() != global[p] {
+			t.Errorf("%s: expected global %v, got %v", p, global[p], p.Global())
+		}
+	}
+}
+
+func TestSubjectHasPermission(t *testing.T) {
+	testutil.SetupDB(t, DB, Setup, Migrate)
+	other := &Project{ResourceID: "project-other", Name: "other"}
+	if err := DB().Create(other).Error; err != nil {
+		t.Fatalf("create project: %s", err)
+	}
+	auditor := &Role{
+		Name:        "auditor",
+		Permissions: datatypes.JSONType[[]Permission]{Data: []Permission{PermissionAuditRead}},
+	}
+	if err := DB().Create(auditor).Error; err != nil {
+		t.Fatalf("create role: %s", err)
+	}
+	bindings := []struct {
+		subject   string
+		role      string
+		projectID string
+	}{
+		{"global-viewer", RoleViewer, ""},
+		{"project-operator", RoleOperator, DefaultProjectID},
+		{"project-admin", RoleAdmin, DefaultProjectID},
+		{"project-auditor", "auditor", DefaultProjectID},
+		{"global-admin", RoleAdmin, ""},
+	}
+	for _, b := range bindings {
+		if _, err := CreateRoleBinding(b.subject, b.role, b.projectID); err != nil {
+			t.Fatalf("create role binding %s: %s", b.subject, err)
+		}
+	}
+	if _, err := CreateRoleBinding("global-viewer", RoleViewer, ""); err != ErrAlreadyExists {
+		t.Errorf("duplicate role binding: expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := CreateRoleBinding("nobody", RoleViewer, "project-missing"); err != ErrDoesNotExist {
+		t.Errorf("missing project: expected ErrDoesNotExist, got %v", err)
+	}
+	tests := []struct {
+		name       string
+		subject    string
+		projectID  string
+		permission Permission
+		allowed    bool
+	}{
+		{"global binding without project", "global-viewer", "", PermissionClusterRead, true},
+		{"global binding in any project", "global-viewer", "project-other", PermissionClusterRead, true},
+		{"global binding missing permission", "global-viewer", DefaultProjectID, PermissionClusterCreate, false},
+		{"project binding in its project", "project-operator", DefaultProjectID, PermissionClusterCreate, true},
+		{"project binding in other project", "project-operator", "project-other", PermissionClusterCreate, false},
+		{"project binding without project", "project-operator", "", PermissionClusterRead, false},
+		{"project binding missing permission", "project-operator", DefaultProjectID, PermissionClusterDelete, false},
+		{"project admin has project permissions", "project-admin", DefaultProjectID, PermissionClusterDelete, true},
+		{"project admin has no global permissions", "project-admin", DefaultProjectID, PermissionRoleUpdate, false},
+		{"project role with global permission", "project-auditor", DefaultProjectID, PermissionAuditRead, false},
+		{"global admin has global permissions", "global-admin", "", PermissionRoleUpdate, true},
+		{"global admin in project", "global-admin", "project-other", PermissionNodeShell, true},
+		{"unknown subject", "nobody", DefaultProjectID, PermissionClusterRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := SubjectHasPermission(tt.subject, tt.projectID, tt.permission)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if allowed != tt.allowed {
+				t.Errorf("expected %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/utils"
+	"gorm.io/gorm"
 )
 
 type ResourceID struct {
@@ -14,10 +15,15 @@ type ResourceID struct {
 
 // createResourceID 通用的资源ID生成方法
 func createResourceID(prefix string) (*ResourceID, error) {
+	return createResourceIDWithDB(DB(), prefix)
+}
+
+// createResourceIDWithDB 使用指定的数据库连接生成资源ID，用于在事务中生成资源ID
+func createResourceIDWithDB(db *gorm.DB, prefix string) (*ResourceID, error) {
 	item := &ResourceID{}
 	str := utils.GetRandString(10)
 	item.ResourceID = prefix + "-" + str
-	if err := DB().Create(item).Error; err != nil {
+	if err := db.Create(item).Error; err != nil {
 		return nil, handleStorageError(err)
 	}
 	return item, nil
@@ -27,3 +33,33 @@ func createResourceID(prefix string) (*ResourceID, error) {
 func GenerateClusterResourceID() (*ResourceID, error) {
 	return createResourceID("cluster")
 }
+
+// GenerateNodeResourceID 生成节点资源ID
+func GenerateNodeResourceID() (*ResourceID, error) {
+	return createResourceID("node")
+}
+
+// GenerateNodePoolResourceID 生成节点池资源ID
+func GenerateNodePoolResourceID() (*ResourceID, error) {
+	return createResourceID("nodepool")
+}
+
+// GenerateCredentialResourceID 生成凭据资源ID
+func GenerateCredentialResourceID() (*ResourceID, error) {
+	return createResourceID("cred")
+}
+
+// GenerateAPITokenResourceID 生成 API 令牌资源ID
+func GenerateAPITokenResourceID() (*ResourceID, error) {
+	return createResourceID("token")
+}
+
+// GenerateProjectResourceID 生成项目资源ID
+func GenerateProjectResourceID() (*ResourceID, error) {
+	return createResourceID("project")
+}
+
+// GenerateWebhookResourceID 生成 webhook 资源ID
+func GenerateWebhookResourceID() (*ResourceID, error) {
+	return createResourceID("webhook")
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gitbub.com/wbuntu/gin-template/internal/pkg/config"
+	glog "gitbub.com/wbuntu/gin-template/internal/pkg/log"
 	"github.com/glebarez/sqlite"
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
@@ -37,6 +38,22 @@ func Setup(ctx context.Context, cfg *config.Config) error {
 			return errors.Wrap(err, "NewDB")
 		}
 	}
+	// 初始化分页令牌的签名密钥，多副本部署时需要配置相同的密钥
+	generated, err := setPageTokenSecret(cfg.API.PageTokenSecret)
+	if err != nil {
+		return err
+	}
+	if generated {
+		glog.WithField("module", "storage").Warn("api.page_token_secret is empty, page tokens are only valid in this process")
+	}
+	// 初始化凭据的加密密钥，多副本部署时需要配置相同的密钥
+	encrypted, err := setCredentialKey(cfg.DB.CredentialKey)
+	if err != nil {
+		return err
+	}
+	if cfg.General.EnableDB && !encrypted {
+		glog.WithField("module", "storage").Warn("db.credential_key is empty, host passwords are stored in plaintext")
+	}
 	// 初始化键值数据库连接池
 	if cfg.General.EnableKVDB {
 		sharedKVDB, err = NewKVDB(ctx, cfg)
@@ -102,3 +119,32 @@ type Filter struct {
 	Query interface{}
 	Args  []interface{}
 }
+
+// applyFilters 依次添加过滤条件，忽略空的过滤条件
+func applyFilters(db *gorm.DB, filters []*Filter) *gorm.DB {
+	for _, filter := range filters {
+		if filter != nil {
+			db = db.Where(filter.Query, filter.Args...)
+		}
+	}
+	return db
+}
+
+// updateWithResourceVersion 只在资源版本未变化时更新记录，同时将版本加一，返回新的版本，记录已被修改或删除时返回 ErrConflict
+func updateWithResourceVersion(db *gorm.DB, model interface{}, id uint64, version uint64, fields map[string]interface{}) (uint64, error) {
+	values := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		values[k] = v
+	}
+	values["resource_version"] = version + 1
+	result := db.Model(model).
+		Where("id = ? and resource_version = ?", id, version).
+		Updates(values)
+	if result.Error != nil {
+		return 0, handleStorageError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrConflict
+	}
+	return version + 1, nil
+}
@@ -2,17 +2,21 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"math"
 	"time"
 
+	"github.com/pkg/errors"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Task struct {
 	ID         uint64         `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
 	ResourceID string         `gorm:"not null;index;size:20;comment:'资源ID'"`
+	ProjectID  string         `gorm:"not null;default:'';index;size:20;comment:'项目资源ID'"`
 	RequestID  string         `gorm:"not null;comment:'请求ID'"`
-	Status     TaskStatus     `gorm:"not null;comment:'任务状态 0-已入队列 1-重试中 2-已成功 3-已失败'"`
+	Status     TaskStatus     `gorm:"not null;comment:'任务状态 0-已入队列 1-重试中 2-已成功 3-已失败 4-已取消'"`
 	Action     string         `gorm:"not null;comment:'任务操作'"`
 	RetryType  TaskRetryType  `gorm:"not null;comment:'重试类型 0-固定间隔 1-指数递增'"`
 	RetryDelay int            `gorm:"not null;comment:'重试延迟'"`
@@ -22,11 +26,15 @@ type Task struct {
 	Config     datatypes.JSON `gorm:"comment:'任务配置'"`
 	CreatedAt  time.Time      `gorm:"comment:'创建时间'"`
 	UpdatedAt  time.Time      `gorm:"comment:'更新时间'"`
+	DeletedAt  gorm.DeletedAt `gorm:"index;comment:'删除时间'"`
 }
 
+// taskRetryMaxDelay 指数递增时的最大重试间隔
+const taskRetryMaxDelay = time.Hour
+
 func (t *Task) NextRetryDuration() time.Duration {
-	// 超过重试次数或任务已完成，则停止重试
-	if t.RetryCount >= t.RetryLimit || t.Status == TaskStatusSuccess {
+	// 超过重试次数、任务已完成或已取消，则停止重试
+	if t.RetryCount >= t.RetryLimit || t.Status == TaskStatusSuccess || t.Status == TaskStatusCanceled {
 		return -1
 	}
 	switch t.RetryType {
@@ -35,12 +43,52 @@ func (t *Task) NextRetryDuration() time.Duration {
 		return time.Duration(t.RetryDelay) * time.Second
 	// 指数递增
 	case TaskRetryTypePow:
-		return time.Duration(math.Pow(2, float64(t.RetryCount))) * time.Duration(t.RetryDelay) * time.Second
+		delay := math.Pow(2, float64(t.RetryCount)) * float64(t.RetryDelay) * float64(time.Second)
+		if delay > float64(taskRetryMaxDelay) {
+			return taskRetryMaxDelay
+		}
+		return time.Duration(delay)
 	}
 	// 无间隔
 	return 0
 }
 
+// RetryWait 距离重试时间的等待时长，未设置重试时间或已到达重试时间时返回 0
+func (t *Task) RetryWait() time.Duration {
+	if !t.RetryAt.Valid {
+		return 0
+	}
+	if wait := time.Until(t.RetryAt.Time); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// GetConfig 反序列化任务配置
+func (t *Task) GetConfig(v interface{}) error {
+	if len(t.Config) == 0 {
+		return nil
+	}
+	return json.Unmarshal(t.Config, v)
+}
+
+// SetConfig 序列化任务配置
+func (t *Task) SetConfig(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.Config = data
+	return nil
+}
+
+// ClusterTaskConfig 集群任务配置
+type ClusterTaskConfig struct {
+	NodeID  string `json:"nodeId,omitempty"`  // 节点任务操作的节点资源ID
+	Version string `json:"version,omitempty"` // 升级的目标版本
+	Step    int    `json:"step,omitempty"`    // 已完成的步骤数，重试时从下一个步骤继续执行
+}
+
 func GetNextTaskByResourceID(resourceID string) (*Task, error) {
 	items := []Task{}
 	if err := DB().
@@ -57,26 +105,176 @@ func GetNextTaskByResourceID(resourceID string) (*Task, error) {
 	return &items[0], nil
 }
 
-func UpdateTaskStatus(item *Task) error {
-	if err := DB().Model(item).Updates(map[string]interface{}{
-		"retry_count": item.RetryCount,
-		"retry_at":    item.RetryAt,
-		"status":      item.Status,
-	}).Error; err != nil {
+// Pending 任务是否等待执行
+func (t *Task) Pending() bool {
+	return t.Status < TaskStatusSuccess
+}
+
+// GetLatestTaskByResourceID 获取资源最近提交的任务
+func GetLatestTaskByResourceID(resourceID string) (*Task, error) {
+	item := &Task{}
+	if err := DB().
+		Where("resource_id = ?", resourceID).
+		Order("id desc").
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetProjectTaskByID 根据项目与ID获取任务，任务不属于该项目时返回 ErrDoesNotExist
+func GetProjectTaskByID(projectID string, id uint64) (*Task, error) {
+	item := &Task{}
+	if err := DB().
+		Where("project_id = ? and id = ?", projectID, id).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetTaskByID 根据ID获取任务，不限定项目，只用于 daemon 执行任务
+func GetTaskByID(id uint64) (*Task, error) {
+	item := &Task{}
+	if err := DB().
+		Where("id = ?", id).
+		Take(item).
+		Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListTask 根据查询条件分页列出项目中资源的任务，默认最新提交的任务在前，还有下一页时返回下一页的分页令牌
+func ListTask(projectID string, resourceID string, query *ListQuery) ([]Task, string, error) {
+	items := []Task{}
+	db, err := query.applyPage(DB().Where("project_id = ? and resource_id = ?", projectID, resourceID))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return paginate(query, items)
+}
+
+// CountTask 计算项目中资源满足查询条件的任务总数
+func CountTask(projectID string, resourceID string, query *ListQuery) (int, error) {
+	var count int64
+	if err := applyFilters(DB().Model(&Task{}).Where("project_id = ? and resource_id = ?", projectID, resourceID), query.Filters).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+// ListDeletedTask 分页列出已删除集群的任务，最新提交的任务在前，resourceID 为空时列出全部集群的任务
+func ListDeletedTask(resourceID string, offset int, limit int) ([]Task, error) {
+	items := []Task{}
+	if err := deletedTaskQuery(resourceID).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// CountDeletedTask 计算已删除集群的任务总数
+func CountDeletedTask(resourceID string) (int, error) {
+	var count int64
+	if err := deletedTaskQuery(resourceID).
+		Model(&Task{}).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+func deletedTaskQuery(resourceID string) *gorm.DB {
+	db := DB().Unscoped().Where("deleted_at IS NOT NULL")
+	if len(resourceID) > 0 {
+		db = db.Where("resource_id = ?", resourceID)
+	}
+	return db
+}
+
+// TaskStatusCount 按操作与状态分组的任务数量
+type TaskStatusCount struct {
+	Action string
+	Status TaskStatus
+	Count  int64
+}
+
+// CountTaskGroupByActionAndStatus 按操作与状态分组计算任务数量
+func CountTaskGroupByActionAndStatus() ([]TaskStatusCount, error) {
+	items := []TaskStatusCount{}
+	if err := DB().
+		Model(&Task{}).
+		Select("action, status, count(*) as count").
+		Group("action, status").
+		Scan(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// SaveTaskCheckpoint 在事务中保存任务配置中的检查点并记录步骤日志，任务已不在等待执行时返回 ErrTaskNotPending
+func SaveTaskCheckpoint(task *Task, taskLog *TaskLog) error {
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(task).
+			Where("status < ?", TaskStatusSuccess).
+			Update("config", task.Config)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update task config")
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskNotPending
+		}
+		if err := tx.Create(taskLog).Error; err != nil {
+			return errors.Wrap(err, "create task log")
+		}
+		return nil
+	}); err != nil {
 		return handleStorageError(err)
 	}
 	return nil
 }
 
+// UpdateTaskStatus 更新任务状态与重试信息，任务可能在执行期间被取消，只更新仍在等待执行的任务，否则返回 ErrTaskNotPending
+func UpdateTaskStatus(item *Task) error {
+	result := DB().Model(item).
+		Where("status < ?", TaskStatusSuccess).
+		Updates(map[string]interface{}{
+			"retry_count": item.RetryCount,
+			"retry_at":    item.RetryAt,
+			"status":      item.Status,
+		})
+	if result.Error != nil {
+		return handleStorageError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskNotPending
+	}
+	return nil
+}
+
 type TaskLog struct {
 	ID      uint64    `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
 	TaskID  uint64    `gorm:"not null;index;comment:'任务ID'"`
 	Reason  string    `gorm:"not null;comment:'原因'"`
+	Step    string    `gorm:"comment:'步骤'"`
 	Message string    `gorm:"not null;comment:'信息'"`
 	StartAt time.Time `gorm:"not null;comment:'起始时间'"`
 	EndAt   time.Time `gorm:"not null;comment:'结束时间'"`
 }
 
+// TaskLogReasonStepSucceeded 步骤执行成功的日志原因，任务状态变化的日志使用任务状态作为原因
+const TaskLogReasonStepSucceeded = "StepSucceeded"
+
 func CreateTaskLog(item *TaskLog) error {
 	if err := DB().Create(item).Error; err != nil {
 		return handleStorageError(err)
@@ -84,15 +282,41 @@ func CreateTaskLog(item *TaskLog) error {
 	return nil
 }
 
-func ListTaskLog(taskID uint64, offset int, limit int) ([]TaskLog, error) {
-	var items []TaskLog
+// ListTaskLog 根据查询条件分页列出任务日志，最新的日志在前，还有下一页时返回下一页的分页令牌
+func ListTaskLog(taskID uint64, query *ListQuery) ([]TaskLog, string, error) {
+	items := []TaskLog{}
+	db, err := query.applyPage(DB().Where("task_id = ?", taskID))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return paginate(query, items)
+}
+
+// ListClusterTaskLogAfter 按ID顺序列出集群全部任务中ID大于 afterID 的任务日志，包含已软删除的任务，集群删除后仍可以读取删除过程的日志
+func ListClusterTaskLogAfter(resourceID string, afterID uint64, limit int) ([]TaskLog, error) {
+	items := []TaskLog{}
 	if err := DB().
-		Offset(offset).
+		Where("task_id IN (?)", DB().Unscoped().Model(&Task{}).Select("id").Where("resource_id = ?", resourceID)).
+		Where("id > ?", afterID).
+		Order("id asc").
 		Limit(limit).
-		Where("task_id = ?", taskID).
-		Order("id desc").
 		Find(&items).Error; err != nil {
 		return nil, handleStorageError(err)
 	}
 	return items, nil
 }
+
+// CountTaskLog 计算任务日志总数
+func CountTaskLog(taskID uint64) (int, error) {
+	var count int64
+	if err := DB().
+		Model(&TaskLog{}).
+		Where("task_id = ?", taskID).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
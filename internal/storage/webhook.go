@@ -0,0 +1,298 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Webhook 项目的 webhook 订阅，集群与任务状态变化时向 URL 投递签名的事件
+type Webhook struct {
+	ID         uint64                                 `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ResourceID string                                 `gorm:"not null;uniqueIndex;size:20;comment:'资源ID'"`
+	ProjectID  string                                 `gorm:"not null;index;size:20;comment:'项目资源ID'"`
+	URL        string                                 `gorm:"not null;size:2048;comment:'投递地址'"`
+	Secret     string                                 `gorm:"not null;comment:'签名密钥'"`
+	Events     datatypes.JSONType[[]WebhookEventType] `gorm:"comment:'订阅的事件类型'"`
+	CreatedAt  time.Time                              `gorm:"comment:'创建时间'"`
+	UpdatedAt  time.Time                              `gorm:"comment:'更新时间'"`
+}
+
+// Subscribes 是否订阅了事件类型
+func (w *Webhook) Subscribes(eventType WebhookEventType) bool {
+	for _, item := range w.Events.Data {
+		if item == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent 待分发的事件，与集群、任务状态在同一事务中写入，由 daemon 为订阅的 webhook 生成投递记录
+type WebhookEvent struct {
+	ID         uint64                               `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	ProjectID  string                               `gorm:"not null;index;size:20;comment:'项目资源ID'"`
+	Type       WebhookEventType                     `gorm:"not null;size:64;comment:'事件类型'"`
+	Data       datatypes.JSONType[WebhookEventData] `gorm:"comment:'事件数据'"`
+	Dispatched bool                                 `gorm:"not null;default:false;index;comment:'是否已分发'"`
+	CreatedAt  time.Time                            `gorm:"index;comment:'创建时间'"`
+}
+
+// WebhookEventData 事件发生时集群与任务的快照
+type WebhookEventData struct {
+	ClusterID     string `json:"clusterId"`               // 集群资源ID
+	ClusterName   string `json:"clusterName,omitempty"`   // 集群名称
+	ClusterStatus string `json:"clusterStatus,omitempty"` // 集群状态
+	TaskID        uint64 `json:"taskId,omitempty"`        // 任务ID
+	TaskAction    string `json:"taskAction,omitempty"`    // 任务操作
+	TaskStatus    string `json:"taskStatus,omitempty"`    // 任务状态
+}
+
+// WebhookDelivery webhook 的投递记录，失败时按重试策略在下次投递时间重新投递
+type WebhookDelivery struct {
+	ID            uint64                `gorm:"primaryKey;autoIncrement;comment:'自增ID'"`
+	WebhookID     string                `gorm:"not null;index;size:20;comment:'webhook资源ID'"`
+	EventID       uint64                `gorm:"not null;index;comment:'事件ID'"`
+	EventType     WebhookEventType      `gorm:"not null;size:64;comment:'事件类型'"`
+	Status        WebhookDeliveryStatus `gorm:"not null;index;comment:'投递状态 0-等待投递 1-投递成功 2-投递失败'"`
+	Attempts      uint16                `gorm:"not null;comment:'已投递次数'"`
+	NextAttemptAt time.Time             `gorm:"not null;index;comment:'下次投递时间'"`
+	LastError     string                `gorm:"size:1024;comment:'最近一次投递的错误'"`
+	CreatedAt     time.Time             `gorm:"comment:'创建时间'"`
+	UpdatedAt     time.Time             `gorm:"comment:'更新时间'"`
+}
+
+// 集群状态与任务状态对应的事件类型，其他状态不产生事件
+var (
+	clusterStatusEvents = map[ClusterStatus]WebhookEventType{
+		ClusterStatusRunning: WebhookEventClusterRunning,
+		ClusterStatusError:   WebhookEventClusterError,
+		ClusterStatusDeleted: WebhookEventClusterDeleted,
+	}
+	taskStatusEvents = map[TaskStatus]WebhookEventType{
+		TaskStatusSuccess:  WebhookEventTaskSucceeded,
+		TaskStatusFail:     WebhookEventTaskFailed,
+		TaskStatusCanceled: WebhookEventTaskCanceled,
+	}
+)
+
+// CreateStatusEvents 在事务中根据集群与任务即将更新的状态写入事件，需要在更新状态之前调用，集群状态未变化时不写入集群事件
+func CreateStatusEvents(tx *gorm.DB, cluster *Cluster, task *Task) error {
+	events := []WebhookEvent{}
+	if cluster != nil {
+		current := &Cluster{}
+		// 恢复集群时集群仍处于软删除状态
+		if err := tx.Unscoped().Select("status").Where("id = ?", cluster.ID).Take(current).Error; err != nil {
+			return errors.Wrap(err, "get cluster status")
+		}
+		if eventType, ok := clusterStatusEvents[cluster.Status]; ok && current.Status != cluster.Status {
+			events = append(events, newWebhookEvent(eventType, cluster, task))
+		}
+	}
+	if task != nil {
+		if eventType, ok := taskStatusEvents[task.Status]; ok {
+			events = append(events, newWebhookEvent(eventType, cluster, task))
+		}
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err := tx.Create(&events).Error; err != nil {
+		return errors.Wrap(err, "create webhook events")
+	}
+	return nil
+}
+
+func newWebhookEvent(eventType WebhookEventType, cluster *Cluster, task *Task) WebhookEvent {
+	event := WebhookEvent{Type: eventType}
+	data := &event.Data.Data
+	if cluster != nil {
+		event.ProjectID = cluster.ProjectID
+		data.ClusterID = cluster.ResourceID
+		data.ClusterName = cluster.Name
+		data.ClusterStatus = cluster.Status.String()
+	}
+	if task != nil {
+		event.ProjectID = task.ProjectID
+		data.ClusterID = task.ResourceID
+		data.TaskID = task.ID
+		data.TaskAction = task.Action
+		data.TaskStatus = task.Status.String()
+	}
+	return event
+}
+
+// DispatchWebhookEvents 在事务中为未分发的事件生成订阅 webhook 的投递记录，并标记事件已分发，返回分发的事件数量
+func DispatchWebhookEvents(limit int) (int, error) {
+	count := 0
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		events := []WebhookEvent{}
+		if err := tx.Where("dispatched = ?", false).Order("id asc").Limit(limit).Find(&events).Error; err != nil {
+			return errors.Wrap(err, "list webhook events")
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		webhooks := map[string][]Webhook{}
+		deliveries := []WebhookDelivery{}
+		eventIDs := make([]uint64, 0, len(events))
+		now := time.Now()
+		for _, event := range events {
+			eventIDs = append(eventIDs, event.ID)
+			items, ok := webhooks[event.ProjectID]
+			if !ok {
+				if err := tx.Where("project_id = ?", event.ProjectID).Find(&items).Error; err != nil {
+					return errors.Wrap(err, "list webhooks")
+				}
+				webhooks[event.ProjectID] = items
+			}
+			for i := range items {
+				if !items[i].Subscribes(event.Type) {
+					continue
+				}
+				deliveries = append(deliveries, WebhookDelivery{
+					WebhookID:     items[i].ResourceID,
+					EventID:       event.ID,
+					EventType:     event.Type,
+					Status:        WebhookDeliveryStatusPending,
+					NextAttemptAt: now,
+				})
+			}
+		}
+		if len(deliveries) > 0 {
+			if err := tx.Create(&deliveries).Error; err != nil {
+				return errors.Wrap(err, "create webhook deliveries")
+			}
+		}
+		if err := tx.Model(&WebhookEvent{}).Where("id IN ?", eventIDs).Update("dispatched", true).Error; err != nil {
+			return errors.Wrap(err, "update webhook events")
+		}
+		count = len(events)
+		return nil
+	}); err != nil {
+		return 0, handleStorageError(err)
+	}
+	return count, nil
+}
+
+// ListDueWebhookDeliveries 列出已到投递时间的投递记录
+func ListDueWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	items := []WebhookDelivery{}
+	if err := DB().
+		Where("status = ? and next_attempt_at <= ?", WebhookDeliveryStatusPending, time.Now()).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// UpdateWebhookDelivery 更新投递状态与下次投递时间
+func UpdateWebhookDelivery(item *WebhookDelivery) error {
+	if err := DB().Model(item).Updates(map[string]interface{}{
+		"status":          item.Status,
+		"attempts":        item.Attempts,
+		"next_attempt_at": item.NextAttemptAt,
+		"last_error":      item.LastError,
+	}).Error; err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// GetWebhookEventByID 根据ID获取事件
+func GetWebhookEventByID(id uint64) (*WebhookEvent, error) {
+	item := &WebhookEvent{}
+	if err := DB().Where("id = ?", id).Take(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetWebhookByResourceID 根据资源ID获取 webhook，不限定项目，只用于 daemon 投递事件
+func GetWebhookByResourceID(resourceID string) (*Webhook, error) {
+	item := &Webhook{}
+	if err := DB().Where("resource_id = ?", resourceID).Take(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// GetProjectWebhook 根据项目与资源ID获取 webhook，webhook 不属于该项目时返回 ErrDoesNotExist
+func GetProjectWebhook(projectID string, resourceID string) (*Webhook, error) {
+	item := &Webhook{}
+	if err := DB().Where("project_id = ? and resource_id = ?", projectID, resourceID).Take(item).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return item, nil
+}
+
+// ListWebhook 列出项目的全部 webhook
+func ListWebhook(projectID string) ([]Webhook, error) {
+	items := []Webhook{}
+	if err := DB().Where("project_id = ?", projectID).Order("id asc").Find(&items).Error; err != nil {
+		return nil, handleStorageError(err)
+	}
+	return items, nil
+}
+
+// DeleteWebhook 在事务中删除 webhook 与 webhook 的投递记录
+func DeleteWebhook(item *Webhook) error {
+	if err := DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", item.ResourceID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return errors.Wrap(err, "delete webhook deliveries")
+		}
+		if err := tx.Delete(item).Error; err != nil {
+			return errors.Wrap(err, "delete webhook")
+		}
+		return nil
+	}); err != nil {
+		return handleStorageError(err)
+	}
+	return nil
+}
+
+// ListWebhookDelivery 根据查询条件分页列出 webhook 的投递记录，最新的记录在前，还有下一页时返回下一页的分页令牌
+func ListWebhookDelivery(webhookID string, query *ListQuery) ([]WebhookDelivery, string, error) {
+	items := []WebhookDelivery{}
+	db, err := query.applyPage(DB().Where("webhook_id = ?", webhookID))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, "", handleStorageError(err)
+	}
+	return paginate(query, items)
+}
+
+// CountWebhookDelivery 计算 webhook 的投递记录总数
+func CountWebhookDelivery(webhookID string) (int, error) {
+	var count int64
+	if err := DB().
+		Model(&WebhookDelivery{}).
+		Where("webhook_id = ?", webhookID).
+		Count(&count).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	return int(count), nil
+}
+
+// DeleteExpiredWebhookEvents 删除创建时间早于 before 且已完成的投递记录，以及没有等待投递记录的已分发事件，返回删除的事件数量
+func DeleteExpiredWebhookEvents(before time.Time) (int64, error) {
+	if err := DB().
+		Where("status != ? and created_at < ?", WebhookDeliveryStatusPending, before).
+		Delete(&WebhookDelivery{}).Error; err != nil {
+		return 0, handleStorageError(err)
+	}
+	pending := DB().Model(&WebhookDelivery{}).Select("event_id").Where("status = ?", WebhookDeliveryStatusPending)
+	result := DB().
+		Where("dispatched = ? and created_at < ? and id NOT IN (?)", true, before, pending).
+		Delete(&WebhookEvent{})
+	if result.Error != nil {
+		return 0, handleStorageError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
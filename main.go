@@ -20,6 +20,11 @@ func init() {
 // @BasePath    /api/v1.0
 // @Accept      json
 // @Produce     json
+
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                API token or JWT, format: Bearer {token}
 func main() {
 	cmd.Execute()
 }